@@ -0,0 +1,127 @@
+package chartjs
+
+import (
+	"fmt"
+
+	"github.com/iszk1215/go-chartjs/types"
+)
+
+type comparison int
+
+const (
+	// Above triggers an alert when a value is greater than the threshold.
+	Above comparison = iota
+	// Below triggers an alert when a value is less than the threshold.
+	Below
+)
+
+func (op comparison) matches(v, threshold float64) bool {
+	if op == Below {
+		return v < threshold
+	}
+	return v > threshold
+}
+
+// AlertStyle holds the colors applied to a chart when an alert is triggered.
+// Nil colors are left unchanged.
+type AlertStyle struct {
+	BorderColor     *types.RGBA
+	BackgroundColor *types.RGBA
+	TitleColor      *types.RGBA
+}
+
+// AlertRule triggers when any point of a dataset crosses a threshold.
+type AlertRule struct {
+	// Dataset is the Label of the dataset to check. Empty means every dataset.
+	Dataset   string
+	Op        comparison
+	Threshold float64
+	Style     AlertStyle
+}
+
+// AlertResult is the outcome of evaluating an AlertRule.
+type AlertResult struct {
+	Rule      AlertRule
+	Triggered bool
+	// Dataset, Index and Value locate the first point that triggered the rule.
+	Dataset string
+	Index   int
+	Value   float64
+}
+
+// plotted returns the values that end up on the value axis.
+func plotted(v Values) []float64 {
+	if ys := v.Ys(); len(ys) > 0 {
+		return ys
+	}
+	return v.Xs()
+}
+
+// AddAlert adds an alert rule to the chart.
+func (c *Chart) AddAlert(r AlertRule) {
+	c.Alerts = append(c.Alerts, r)
+}
+
+// EvaluateAlerts checks every alert rule against the chart data.
+func (c Chart) EvaluateAlerts() []AlertResult {
+	results := make([]AlertResult, 0, len(c.Alerts))
+	for _, r := range c.Alerts {
+		res := AlertResult{Rule: r, Index: -1}
+	datasets:
+		for _, d := range c.Data.Datasets {
+			if r.Dataset != "" && r.Dataset != d.Label {
+				continue
+			}
+			v, ok := d.Data.(Values)
+			if !ok {
+				continue
+			}
+			for i, y := range plotted(v) {
+				if r.Op.matches(y, r.Threshold) {
+					res.Triggered, res.Dataset, res.Index, res.Value = true, d.Label, i, y
+					break datasets
+				}
+			}
+		}
+		results = append(results, res)
+	}
+	return results
+}
+
+// Alerting reports whether any alert rule is triggered.
+func (c Chart) Alerting() bool {
+	for _, r := range c.EvaluateAlerts() {
+		if r.Triggered {
+			return true
+		}
+	}
+	return false
+}
+
+// applyAlerts returns a copy of the chart with the title colored by the triggered
+// rules, along with the CSS to apply to its canvas.
+func (c Chart) applyAlerts() (Chart, string) {
+	var css string
+	for _, r := range c.EvaluateAlerts() {
+		if !r.Triggered {
+			continue
+		}
+		s := r.Rule.Style
+		if s.BorderColor != nil {
+			css += fmt.Sprintf("border:2px solid %s;", cssColor(s.BorderColor))
+		}
+		if s.BackgroundColor != nil {
+			css += fmt.Sprintf("background-color:%s;", cssColor(s.BackgroundColor))
+		}
+		if s.TitleColor != nil && c.Options.Title != nil {
+			t := *c.Options.Title
+			t.FontColor = s.TitleColor
+			c.Options.Title = &t
+		}
+	}
+	return c, css
+}
+
+func cssColor(c *types.RGBA) string {
+	return fmt.Sprintf("rgba(%d, %d, %d, %.3f)", c.R, c.G, c.B, float64(c.A)/255)
+}
@@ -0,0 +1,69 @@
+package chartjs
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/iszk1215/go-chartjs/types"
+)
+
+// Animation wraps chartjs "animation". The zero Animation has no duration,
+// which suits charts rendered on the server.
+type Animation struct {
+	// Duration in milliseconds.
+	Duration int `json:"duration"`
+	// Easing is the name of the easing function, e.g. "easeOutQuart".
+	Easing string `json:"easing,omitempty"`
+	// Delay before the animation starts, in milliseconds.
+	Delay int `json:"delay,omitempty"`
+	// Loop repeats the animation endlessly.
+	Loop types.Bool `json:"loop,omitempty"`
+	// Disabled writes the animation as false, which turns off the
+	// Animations of the chart too. See DisableAnimation.
+	Disabled bool `json:"-"`
+}
+
+// MarshalJSON implements json.Marshaler interface.
+func (a Animation) MarshalJSON() ([]byte, error) {
+	if a.Disabled {
+		return []byte("false"), nil
+	}
+	type alias Animation
+	return json.Marshal(alias(a))
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface.
+func (a *Animation) UnmarshalJSON(b []byte) error {
+	if bytes.Equal(bytes.TrimSpace(b), []byte("false")) {
+		*a = Animation{Disabled: true}
+		return nil
+	}
+	type alias Animation
+	return json.Unmarshal(b, (*alias)(a))
+}
+
+// PropertyAnimation animates some properties of the elements, see
+// Options.Animations.
+type PropertyAnimation struct {
+	// Properties are the animated properties, e.g. "x" and "y". They
+	// default to the name of the animation.
+	Properties []string `json:"properties,omitempty"`
+	// Type is "number", "color" or "boolean".
+	Type string `json:"type,omitempty"`
+	// Duration, Easing, Delay and Loop override those of Animation. A
+	// pointer differentiates 0 from unset.
+	Duration *int       `json:"duration,omitempty"`
+	Easing   string     `json:"easing,omitempty"`
+	Delay    *int       `json:"delay,omitempty"`
+	Loop     types.Bool `json:"loop,omitempty"`
+	// From and To are the start and end values, e.g. 1 and 0 for tension.
+	From interface{} `json:"from,omitempty"`
+	To   interface{} `json:"to,omitempty"`
+}
+
+// DisableAnimation turns off every animation of the chart, e.g. for charts
+// captured as images on the server.
+func (c *Chart) DisableAnimation() {
+	c.Options.Animation = Animation{Disabled: true}
+	c.Options.Animations = nil
+}
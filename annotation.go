@@ -0,0 +1,72 @@
+package chartjs
+
+import "github.com/iszk1215/go-chartjs/types"
+
+type annotationType int
+
+const (
+	// BoxAnnotation shades the area between XMin, XMax, YMin and YMax.
+	// Unset bounds extend to the edge of the chart.
+	BoxAnnotation annotationType = iota
+	// LineAnnotation draws a line at Value on the ScaleID axis.
+	LineAnnotation
+)
+
+var annotationTypes = []string{
+	"box",
+	"line",
+}
+
+func (t annotationType) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + annotationTypes[t] + `"`), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface.
+func (t *annotationType) UnmarshalJSON(b []byte) error {
+	i, err := unmarshalEnum(b, annotationTypes, "annotation type")
+	*t = annotationType(i)
+	return err
+}
+
+// Annotation is an annotation of chartjs-plugin-annotation.
+type Annotation struct {
+	Type annotationType `json:"type"`
+
+	// Box options.
+	XScaleID string   `json:"xScaleID,omitempty"`
+	YScaleID string   `json:"yScaleID,omitempty"`
+	XMin     *float64 `json:"xMin,omitempty"`
+	XMax     *float64 `json:"xMax,omitempty"`
+	YMin     *float64 `json:"yMin,omitempty"`
+	YMax     *float64 `json:"yMax,omitempty"`
+
+	// Line options. Mode is "horizontal" or "vertical".
+	Mode    string   `json:"mode,omitempty"`
+	ScaleID string   `json:"scaleID,omitempty"`
+	Value   *float64 `json:"value,omitempty"`
+
+	BackgroundColor *types.RGBA      `json:"backgroundColor,omitempty"`
+	BorderColor     *types.RGBA      `json:"borderColor,omitempty"`
+	BorderWidth     float64          `json:"borderWidth,omitempty"`
+	Label           *AnnotationLabel `json:"label,omitempty"`
+}
+
+// AnnotationLabel is the label of a LineAnnotation.
+type AnnotationLabel struct {
+	Enabled bool   `json:"enabled"`
+	Content string `json:"content,omitempty"`
+}
+
+// Annotations wraps the "annotation" options.
+type Annotations struct {
+	Annotations []Annotation `json:"annotations"`
+}
+
+// AddAnnotation adds an annotation to the chart. The annotation plugin is
+// loaded by SaveCharts.
+func (c *Chart) AddAnnotation(a Annotation) {
+	if c.Options.Annotation == nil {
+		c.Options.Annotation = &Annotations{}
+	}
+	c.Options.Annotation.Annotations = append(c.Options.Annotation.Annotations, a)
+}
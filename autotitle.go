@@ -0,0 +1,81 @@
+package chartjs
+
+import (
+	"sort"
+	"strings"
+)
+
+// autoTitle returns the title derived for the axis id, or "" if there is
+// nothing to derive it from. Time axes are titled "Time", with the zone of
+// their Adapters. Value axes are titled with the Unit of their datasets,
+// after the label of a single dataset, e.g. "Latency (ms)".
+func (c Chart) autoTitle(id string, a Axis) string {
+	switch a.Type {
+	case Time:
+		if a.Adapters != nil && a.Adapters.Date.Zone != "" {
+			return "Time (" + a.Adapters.Date.Zone + ")"
+		}
+		return "Time"
+	case Category, Radial:
+		return ""
+	}
+	var units, labels []string
+	seen := map[string]bool{}
+	for _, d := range c.Data.Datasets {
+		// the value axis, which is x for horizontal bars.
+		axis, def := d.YAxisID, defaultYAxisID
+		if c.Options.IndexAxis == "y" {
+			axis, def = d.XAxisID, defaultXAxisID
+		}
+		if axis == "" {
+			axis = def
+		}
+		if axis != id {
+			continue
+		}
+		labels = append(labels, d.Label)
+		if d.Unit != "" && !seen[d.Unit] {
+			seen[d.Unit] = true
+			units = append(units, d.Unit)
+		}
+	}
+	switch {
+	case len(labels) == 1 && labels[0] != "" && len(units) == 1:
+		return labels[0] + " (" + units[0] + ")"
+	case len(labels) == 1 && len(units) == 0:
+		return labels[0]
+	}
+	return strings.Join(units, ", ")
+}
+
+// applyAutoTitles titles the axes without a title, see AutoTitleAxes.
+func (c *Chart) applyAutoTitles() {
+	ids := make([]string, 0, len(c.Options.Scales))
+	for id := range c.Options.Scales {
+		ids = append(ids, id)
+	}
+	scales := make(map[string]Axis, len(c.Options.Scales)+1)
+	// the default value axis, as chart.js creates it.
+	def := defaultYAxisID
+	if c.Options.IndexAxis == "y" {
+		def = defaultXAxisID
+	}
+	if _, ok := c.Options.Scales[def]; !ok && !radialTypes[c.Type] {
+		if text := c.autoTitle(def, Axis{Type: Linear}); text != "" {
+			scales[def] = Axis{Type: Linear, Title: AxisTitle{Display: true, Text: text}}
+		}
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		a := c.Options.Scales[id]
+		titled := a.Title.Text != "" || a.Title.TextLines != nil ||
+			a.ScaleLabel != nil && (a.ScaleLabel.LabelString != "" || a.ScaleLabel.LabelLines != nil)
+		if !titled {
+			if text := c.autoTitle(id, a); text != "" {
+				a.Title = AxisTitle{Display: true, Text: text}
+			}
+		}
+		scales[id] = a
+	}
+	c.Options.Scales = scales
+}
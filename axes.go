@@ -0,0 +1,95 @@
+package chartjs
+
+import (
+	"fmt"
+
+	"github.com/iszk1215/go-chartjs/types"
+)
+
+// Default axis IDs created by chart.js when no scale is configured.
+const (
+	defaultXAxisID = "x"
+	defaultYAxisID = "y"
+)
+
+// implicitAxisIDs are the IDs chart.js 2 gives the default axes.
+var implicitAxisIDs = map[string]string{
+	defaultXAxisID: "x-axis-0",
+	defaultYAxisID: "y-axis-0",
+}
+
+// hasAxis reports whether id names an axis in Options.Scales or one created
+// by chart.js, whose ID is def or the chart.js 2 ID of def.
+func (c Chart) hasAxis(id, def string) bool {
+	if _, ok := c.Options.Scales[id]; ok {
+		return true
+	}
+	return id == "" || id == def || id == implicitAxisIDs[def]
+}
+
+// ValidateAxes checks that every axis ID referenced by a dataset exists in
+// Options.Scales or names a default axis of chart.js, e.g. "y" or
+// "y-axis-0". Charts are validated when marshaled.
+func (c Chart) ValidateAxes() error {
+	for i, d := range c.Data.Datasets {
+		if !c.hasAxis(d.XAxisID, defaultXAxisID) {
+			return fmt.Errorf("chart: dataset %d (%q) references unknown x-axis %q", i, d.Label, d.XAxisID)
+		}
+		if !c.hasAxis(d.YAxisID, defaultYAxisID) {
+			return fmt.Errorf("chart: dataset %d (%q) references unknown y-axis %q", i, d.Label, d.YAxisID)
+		}
+	}
+	return nil
+}
+
+// CreateMissingAxes adds the axes referenced by datasets but missing from
+// Options.Scales. X-axes are category axes at the bottom. Y-axes are linear,
+// the first one on the left and the others on the right.
+func (c *Chart) CreateMissingAxes() {
+	left := false
+	for _, a := range c.Options.Scales {
+		if a.Position == Left {
+			left = true
+		}
+	}
+	for _, d := range c.Data.Datasets {
+		if !c.hasAxis(d.XAxisID, defaultXAxisID) {
+			c.AddAxis(Axis{ID: d.XAxisID, Type: Category, Position: Bottom})
+		}
+		if !c.hasAxis(d.YAxisID, defaultYAxisID) {
+			pos := Left
+			if left {
+				pos = Right
+			}
+			left = true
+			c.AddAxis(Axis{ID: d.YAxisID, Type: Linear, Position: pos})
+		}
+	}
+}
+
+// EnableStacking stacks the datasets of the same Stack by setting Stacked on
+// the axes of the datasets, both x and y, which are the default axes for
+// datasets without axis IDs. Call it after adding the datasets. Missing axes
+// are created: the index axis as a category axis and the value axis, y
+// unless Options.IndexAxis is "y", as a linear one.
+func (c *Chart) EnableStacking() {
+	stack := func(id, def string, value bool) {
+		if id == "" {
+			id = def
+		}
+		a, ok := c.Options.Scales[id]
+		if !ok {
+			a = Axis{ID: id, Type: Category}
+			if value {
+				a.Type = Linear
+			}
+		}
+		a.Stacked = types.True
+		c.AddAxis(a)
+	}
+	horizontal := c.Options.IndexAxis == "y"
+	for _, d := range c.Data.Datasets {
+		stack(d.XAxisID, defaultXAxisID, horizontal)
+		stack(d.YAxisID, defaultYAxisID, !horizontal)
+	}
+}
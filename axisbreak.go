@@ -0,0 +1,79 @@
+package chartjs
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/iszk1215/go-chartjs/types"
+)
+
+// BreakAxis keeps one huge value from dwarfing the rest by breaking the value
+// axis above threshold. Values above it are clipped to a little above the
+// threshold and crossed by a stripe in color, white if nil, marking the break.
+// Ticks above the threshold are hidden and tooltips show the real values.
+// The stripe is drawn with chartjs-plugin-annotation.
+func (c *Chart) BreakAxis(threshold float64, color *types.RGBA) error {
+	if threshold <= 0 || math.IsInf(threshold, 0) || math.IsNaN(threshold) {
+		return fmt.Errorf("chart: bad axis break threshold %v", threshold)
+	}
+	clip := threshold + threshold/10
+	raw := make([][]float64, len(c.Data.Datasets))
+	for i, d := range c.Data.Datasets {
+		v, ok := d.Data.(Values)
+		if !ok {
+			return fmt.Errorf("chart: dataset %q does not hold Values", d.Label)
+		}
+		raw[i] = plotted(v)
+		clipped := make([]float64, len(raw[i]))
+		for j, y := range raw[i] {
+			clipped[j] = math.Min(y, clip)
+		}
+		if len(v.Ys()) > 0 {
+			c.Data.Datasets[i].Data = xyValues{xs: v.Xs(), ys: clipped, rs: v.Rs()}
+		} else {
+			c.Data.Datasets[i].Data = xyValues{xs: clipped}
+		}
+	}
+
+	if color == nil {
+		white := types.RGBA{R: 255, G: 255, B: 255, A: 255}
+		color = &white
+	}
+	lo, hi := threshold*1.03, threshold*1.06
+	stripe := Annotation{Type: BoxAnnotation, BackgroundColor: color}
+	id := "y"
+	if c.Options.IndexAxis == "y" {
+		id = "x"
+		stripe.XScaleID, stripe.XMin, stripe.XMax = id, &lo, &hi
+	} else {
+		stripe.YScaleID, stripe.YMin, stripe.YMax = id, &lo, &hi
+	}
+	c.AddAnnotation(stripe)
+
+	axis, ok := c.Options.Scales[id]
+	if !ok {
+		axis = Axis{ID: id, Type: Linear}
+		if id == "x" {
+			axis.Position = Bottom
+		} else {
+			axis.Position = Left
+		}
+	}
+	if axis.Tick == nil {
+		axis.Tick = &Tick{}
+	}
+	axis.Tick.Max = clip
+	if axis.Tick.Callback == "" {
+		t := strconv.FormatFloat(threshold, 'g', -1, 64)
+		axis.Tick.Callback = JSFunc("function(value) { return value > " + t + " ? '' : value; }")
+	}
+	c.AddAxis(axis)
+
+	label, err := rawTooltipLabel(raw)
+	if err != nil {
+		return err
+	}
+	c.tooltipCallbacks().Label = label
+	return nil
+}
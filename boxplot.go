@@ -0,0 +1,60 @@
+package chartjs
+
+import "math"
+
+// BoxplotValues is the five-number summary of a sample plotted by a Boxplot
+// chart with chartjs-chart-box-and-violin-plot.
+type BoxplotValues struct {
+	Min      float64   `json:"min"`
+	Q1       float64   `json:"q1"`
+	Median   float64   `json:"median"`
+	Q3       float64   `json:"q3"`
+	Max      float64   `json:"max"`
+	Outliers []float64 `json:"outliers,omitempty"`
+}
+
+// quantile interpolates the q-quantile of sorted values.
+func quantile(sorted []float64, q float64) float64 {
+	pos := q * float64(len(sorted)-1)
+	i := int(pos)
+	if i+1 >= len(sorted) {
+		return sorted[len(sorted)-1]
+	}
+	return sorted[i] + (sorted[i+1]-sorted[i])*(pos-float64(i))
+}
+
+// Summarize computes the five-number summary of xs, ignoring NaNs. Whiskers
+// end at the most extreme values within 1.5 times the interquartile range of
+// the quartiles, and values beyond are outliers. The summary of an empty
+// sample is all NaN, which cannot be marshaled.
+func Summarize(xs []float64) BoxplotValues {
+	b, _ := SummarizeWeighted(xs, nil)
+	return b
+}
+
+// SummarizeWeighted is like Summarize with each value counted weights[i] times.
+func SummarizeWeighted(xs, weights []float64) (BoxplotValues, error) {
+	if err := checkWeights(len(xs), weights); err != nil {
+		return BoxplotValues{}, err
+	}
+	s, total := sortedSample(xs, weights)
+	if len(s) == 0 {
+		nan := math.NaN()
+		return BoxplotValues{Min: nan, Q1: nan, Median: nan, Q3: nan, Max: nan}, nil
+	}
+	b := BoxplotValues{
+		Q1:     weightedQuantile(s, total, 0.25),
+		Median: weightedQuantile(s, total, 0.5),
+		Q3:     weightedQuantile(s, total, 0.75),
+	}
+	lo, hi := b.Q1-1.5*(b.Q3-b.Q1), b.Q3+1.5*(b.Q3-b.Q1)
+	b.Min, b.Max = math.Inf(1), math.Inf(-1)
+	for _, p := range s {
+		if p.v < lo || p.v > hi {
+			b.Outliers = append(b.Outliers, p.v)
+			continue
+		}
+		b.Min, b.Max = math.Min(b.Min, p.v), math.Max(b.Max, p.v)
+	}
+	return b, nil
+}
@@ -0,0 +1,102 @@
+package chartjs
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Brush lets users drag over a chart to select an x range. The selection is
+// posted as JSON to URL, which should be served by a SelectionHandler.
+type Brush struct {
+	URL string
+}
+
+// SelectedPoint is a point inside a Selection.
+type SelectedPoint struct {
+	Dataset string  `json:"dataset"`
+	Index   int     `json:"index"`
+	X       float64 `json:"x"`
+	Y       float64 `json:"y"`
+	// Category is the label of the point on a Category axis.
+	Category string `json:"category,omitempty"`
+}
+
+// Selection is what a user selected with a Brush.
+type Selection struct {
+	// Chart is the Label of the chart.
+	Chart  string          `json:"chart"`
+	XMin   float64         `json:"xMin"`
+	XMax   float64         `json:"xMax"`
+	Points []SelectedPoint `json:"points"`
+}
+
+// SelectionHandler receives the selections posted by a Brush.
+func SelectionHandler(fn func(Selection) error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			http.Error(w, "chart: selections must be posted", http.StatusMethodNotAllowed)
+			return
+		}
+		var s Selection
+		if err := json.NewDecoder(r.Body).Decode(&s); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := fn(s); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// brushJS is the client side of Brush.
+const brushJS = `function chartjsBrush(chart, url) {
+	var canvas = chart.canvas, start = null;
+	var box = document.createElement("div");
+	box.style.cssText = "position:absolute;display:none;pointer-events:none;background:rgba(54,162,235,0.2);border:1px solid rgba(54,162,235,0.8)";
+	document.body.appendChild(box);
+	function xScale() {
+		for (var id in chart.scales) {
+			if (chart.scales[id].isHorizontal()) { return chart.scales[id]; }
+		}
+	}
+	function offset(evt) { return evt.clientX - canvas.getBoundingClientRect().left; }
+	canvas.addEventListener("mousedown", function(evt) {
+		start = offset(evt);
+		var r = canvas.getBoundingClientRect();
+		box.style.left = (r.left + window.scrollX + start) + "px";
+		box.style.top = (r.top + window.scrollY) + "px";
+		box.style.height = r.height + "px";
+		box.style.width = "0px";
+		box.style.display = "block";
+	});
+	canvas.addEventListener("mousemove", function(evt) {
+		if (start === null) { return; }
+		var x = offset(evt), r = canvas.getBoundingClientRect();
+		box.style.left = (r.left + window.scrollX + Math.min(start, x)) + "px";
+		box.style.width = Math.abs(x - start) + "px";
+	});
+	canvas.addEventListener("mouseup", function(evt) {
+		if (start === null) { return; }
+		var x0 = Math.min(start, offset(evt)), x1 = Math.max(start, offset(evt));
+		start = null;
+		box.style.display = "none";
+		if (x1 - x0 < 3) { return; }
+		var scale = xScale(), labels = chart.data.labels || [];
+		var sel = {chart: chart.config.label || "", xMin: scale.getValueForPixel(x0), xMax: scale.getValueForPixel(x1), points: []};
+		if (typeof sel.xMin !== "number") { sel.xMin = labels.indexOf(sel.xMin); sel.xMax = labels.indexOf(sel.xMax); }
+		chart.data.datasets.forEach(function(ds, di) {
+			chart.getDatasetMeta(di).data.forEach(function(el, i) {
+				var px = el._model ? el._model.x : el.x;
+				if (px < x0 || px > x1) { return; }
+				var p = ds.data[i], isObj = p !== null && typeof p === "object";
+				sel.points.push({dataset: ds.label || "", index: i,
+					x: isObj ? p.x : i, y: isObj ? p.y : p,
+					category: labels[i] !== undefined ? String(labels[i]) : ""});
+			});
+		});
+		fetch(url, {method: "POST", headers: {"Content-Type": "application/json"}, body: JSON.stringify(sel)});
+	});
+}
+`
@@ -0,0 +1,161 @@
+package chartjs
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// TimeBuckets groups times into calendar buckets in a time zone. Days,
+// weeks, months, quarters and years start at local midnight, so daily
+// buckets stay aligned across DST transitions, where days last 23 or 25
+// hours. Weeks start on Monday.
+type TimeBuckets struct {
+	// Unit is the length of a bucket, UnitDay if unset.
+	Unit timeUnit
+	// Location is the time zone of the buckets, UTC if nil.
+	Location *time.Location
+	// Reduce combines the values of a bucket, e.g. into their mean. The
+	// values are summed if it is nil.
+	Reduce func(ys []float64) float64
+	// Calendar makes Aggregate leave out the values of days off, and Next
+	// skip them for units up to a day.
+	Calendar Calendar
+	// MergeDaysOff makes Aggregate add the values of days off to the first
+	// bucket of the next business day instead, e.g. for weekend orders.
+	MergeDaysOff bool
+}
+
+func (b TimeBuckets) location() *time.Location {
+	if b.Location == nil {
+		return time.UTC
+	}
+	return b.Location
+}
+
+func (b TimeBuckets) unit() timeUnit {
+	if b.Unit == 0 {
+		return UnitDay
+	}
+	return b.Unit
+}
+
+// Truncate returns the start of the bucket holding t, in Location.
+func (b TimeBuckets) Truncate(t time.Time) time.Time {
+	loc := b.location()
+	t = t.In(loc)
+	// units up to an hour are truncated by the local clock, which keeps the
+	// repeated hour of a DST transition in two buckets.
+	ns := time.Duration(t.Nanosecond())
+	sec := time.Duration(t.Second()) * time.Second
+	min := time.Duration(t.Minute()) * time.Minute
+	switch b.unit() {
+	case UnitMillisecond:
+		return t.Add(-ns % time.Millisecond)
+	case UnitSecond:
+		return t.Add(-ns)
+	case UnitMinute:
+		return t.Add(-ns - sec)
+	case UnitHour:
+		return t.Add(-ns - sec - min)
+	}
+	y, m, d := t.Date()
+	switch b.unit() {
+	case UnitWeek:
+		d -= (int(t.Weekday()) + 6) % 7
+	case UnitMonth:
+		d = 1
+	case UnitQuarter:
+		m -= (m - 1) % 3
+		d = 1
+	case UnitYear:
+		m, d = time.January, 1
+	}
+	return time.Date(y, m, d, 0, 0, 0, 0, loc)
+}
+
+// Next returns the start of the bucket after the one holding t, skipping the
+// days off of Calendar for units up to a day.
+func (b TimeBuckets) Next(t time.Time) time.Time {
+	next := b.next(t)
+	if b.Calendar != nil && b.unit() <= UnitDay && !b.Calendar.IsBusinessDay(next) {
+		next = b.Truncate(nextBusinessDay(b.Calendar, next))
+	}
+	return next
+}
+
+func (b TimeBuckets) next(t time.Time) time.Time {
+	t = b.Truncate(t)
+	y, m, d := t.Date()
+	switch b.unit() {
+	case UnitMillisecond:
+		return t.Add(time.Millisecond)
+	case UnitSecond:
+		return t.Add(time.Second)
+	case UnitMinute:
+		return t.Add(time.Minute)
+	case UnitHour:
+		return t.Add(time.Hour)
+	case UnitDay:
+		d++
+	case UnitWeek:
+		d += 7
+	case UnitMonth:
+		m++
+	case UnitQuarter:
+		m += 3
+	default:
+		y++
+	}
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// Aggregate reduces the values of each bucket and returns them at the start
+// of their bucket, in time order. Empty buckets are left out, as are days
+// off of Calendar unless MergeDaysOff is set.
+func (b TimeBuckets) Aggregate(ts []time.Time, ys []float64) (TimeSeries, error) {
+	if len(ts) != len(ys) {
+		return TimeSeries{}, fmt.Errorf("chart: bad format of Values. Times and Y must be of the same length")
+	}
+	// times are keyed by instant, as equal times may differ in location.
+	groups := map[int64][]float64{}
+	starts := map[int64]time.Time{}
+	for i, t := range ts {
+		if b.Calendar != nil {
+			t = t.In(b.location())
+			if !b.Calendar.IsBusinessDay(t) {
+				if !b.MergeDaysOff {
+					continue
+				}
+				t = nextBusinessDay(b.Calendar, t)
+			}
+		}
+		start := b.Truncate(t)
+		k := start.UnixNano()
+		groups[k] = append(groups[k], ys[i])
+		starts[k] = start
+	}
+	keys := make([]int64, 0, len(groups))
+	for k := range groups {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	reduce := b.Reduce
+	if reduce == nil {
+		reduce = sum
+	}
+	s := TimeSeries{Time: make([]time.Time, len(keys)), Value: make([]float64, len(keys))}
+	for i, k := range keys {
+		s.Time[i] = starts[k]
+		s.Value[i] = reduce(groups[k])
+	}
+	return s, nil
+}
+
+func sum(ys []float64) float64 {
+	var s float64
+	for _, y := range ys {
+		s += y
+	}
+	return s
+}
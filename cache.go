@@ -0,0 +1,155 @@
+package chartjs
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sync"
+	"unsafe"
+)
+
+// DataCache keeps the marshaled data of a Dataset, so that charts whose
+// options change but whose data does not are marshaled cheaply. The data are
+// written again after Touch, or when the options of the dataset that change
+// them, e.g. YFloatFormat, change, or when Data is replaced by other values,
+// e.g. by a Middleware or Personalizer. Share one DataCache per dataset:
+//
+//	d := Dataset{Data: Floats(ys), Cache: &DataCache{}}
+//	...
+//	ys[0] = 42
+//	d.Touch()
+//
+// Only data of Values, slices, maps and pointers are cached, as their
+// identity can be told apart; other data are always written.
+type DataCache struct {
+	mu      sync.Mutex
+	version uint64
+	// data were written from the data of id with the options of key at
+	// version cached.
+	cached uint64
+	valid  bool
+	id     dataID
+	key    string
+	data   []byte
+}
+
+// dataID identifies the data of a dataset by their type and the memory of
+// their values. The pointers keep the memory alive, so that it cannot be
+// reused by other data.
+type dataID struct {
+	typ  reflect.Type
+	ptrs [4]unsafe.Pointer
+	lens [4]int
+}
+
+// identify returns the dataID of data, or false if it cannot be told apart
+// from other data of the same type.
+func identify(data interface{}) (dataID, bool) {
+	id := dataID{typ: reflect.TypeOf(data)}
+	var parts []interface{}
+	switch v := data.(type) {
+	case nil:
+		return id, false
+	case TimeValues:
+		// Xs of TimeValues are computed from the times.
+		parts = []interface{}{v.Times(), v.Ys()}
+	case Values:
+		parts = []interface{}{v.Xs(), v.Ys(), v.Rs()}
+		if m, ok := v.(MetaValues); ok {
+			parts = append(parts, m.Meta())
+		} else if l, ok := v.(LabeledValues); ok {
+			parts = append(parts, l.Labels())
+		}
+	default:
+		parts = []interface{}{data}
+	}
+	for i, p := range parts {
+		rv := reflect.ValueOf(p)
+		switch rv.Kind() {
+		case reflect.Slice, reflect.Map:
+			id.lens[i] = rv.Len()
+			id.ptrs[i] = rv.UnsafePointer()
+		case reflect.Ptr:
+			id.ptrs[i] = rv.UnsafePointer()
+		default:
+			return id, false
+		}
+	}
+	return id, true
+}
+
+// Touch invalidates the cached data, after the data changed.
+func (c *DataCache) Touch() {
+	c.mu.Lock()
+	c.version++
+	c.mu.Unlock()
+}
+
+// Version returns the number of calls to Touch.
+func (c *DataCache) Version() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.version
+}
+
+func (c *DataCache) get(id dataID, key string) ([]byte, uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.valid && c.cached == c.version && c.id == id && c.key == key {
+		return c.data, c.version, true
+	}
+	return nil, c.version, false
+}
+
+// put caches the data written at version, unless touched meanwhile.
+func (c *DataCache) put(version uint64, id dataID, key string, data []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if version == c.version {
+		c.cached, c.valid, c.id, c.key, c.data = version, true, id, key, data
+	}
+}
+
+// Touch invalidates the cached data of the dataset, if it has a Cache.
+func (d Dataset) Touch() {
+	if d.Cache != nil {
+		d.Cache.Touch()
+	}
+}
+
+// cacheKey returns the options the data are written with.
+func (d Dataset) cacheKey() string {
+	loc := ""
+	if d.Location != nil {
+		loc = d.Location.String()
+	}
+	var cur Currency
+	if d.Currency != nil {
+		cur = *d.Currency
+	}
+	return fmt.Sprintf("%d|%q|%q|%q|%q|%d|%q|%q|%+v", d.Type, d.XFloatFormat, d.YFloatFormat,
+		XFloatFormat, YFloatFormat, d.NaNPolicy, d.TimeFormat, loc, cur)
+}
+
+// writeCachedData writes the data of the dataset from its Cache, if any.
+func (d Dataset) writeCachedData(w jsonWriter) error {
+	if d.Cache == nil {
+		return d.writeData(w)
+	}
+	id, ok := identify(d.Data)
+	if !ok {
+		return d.writeData(w)
+	}
+	key := d.cacheKey()
+	data, version, ok := d.Cache.get(id, key)
+	if !ok {
+		var buf bytes.Buffer
+		if err := d.writeData(&buf); err != nil {
+			return err
+		}
+		data = buf.Bytes()
+		d.Cache.put(version, id, key, data)
+	}
+	_, err := w.Write(data)
+	return err
+}
@@ -0,0 +1,63 @@
+package chartjs
+
+import "time"
+
+// Calendar tells business days from weekends and holidays. It is used by
+// TimeBuckets and TimeGaps to skip or merge the days off consistently.
+type Calendar interface {
+	// IsBusinessDay reports whether the day of t, in t's location, is a
+	// business day.
+	IsBusinessDay(t time.Time) bool
+}
+
+// CalendarFunc adapts a function to a Calendar.
+type CalendarFunc func(t time.Time) bool
+
+// IsBusinessDay implements Calendar.
+func (f CalendarFunc) IsBusinessDay(t time.Time) bool { return f(t) }
+
+// BusinessCalendar is a Calendar of the days of the week except weekends
+// and holidays.
+type BusinessCalendar struct {
+	// Weekend are the days off every week, Saturday and Sunday if nil.
+	Weekend []time.Weekday
+	// Holidays are days off, compared by date regardless of time and
+	// location.
+	Holidays []time.Time
+}
+
+// IsBusinessDay implements Calendar.
+func (c BusinessCalendar) IsBusinessDay(t time.Time) bool {
+	weekend := c.Weekend
+	if weekend == nil {
+		weekend = []time.Weekday{time.Saturday, time.Sunday}
+	}
+	for _, wd := range weekend {
+		if t.Weekday() == wd {
+			return false
+		}
+	}
+	y, m, d := t.Date()
+	for _, h := range c.Holidays {
+		if hy, hm, hd := h.Date(); hy == y && hm == m && hd == d {
+			return false
+		}
+	}
+	return true
+}
+
+// maxDaysOff bounds the search for the next business day, so that a
+// Calendar without business days does not loop forever.
+const maxDaysOff = 366
+
+// nextBusinessDay returns local midnight of the first business day after the
+// day of t, in t's location.
+func nextBusinessDay(cal Calendar, t time.Time) time.Time {
+	y, m, d := t.Date()
+	for i := 1; ; i++ {
+		next := time.Date(y, m, d+i, 0, 0, 0, 0, t.Location())
+		if cal.IsBusinessDay(next) || i >= maxDaysOff {
+			return next
+		}
+	}
+}
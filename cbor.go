@@ -0,0 +1,207 @@
+package chartjs
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+)
+
+// CBOREncoder writes the chart config as CBOR (RFC 8949), which is smaller
+// than JSON for charts with many points. Integral numbers are written as
+// integers and others as float32 when that is exact. The config is encoded
+// from its JSON, so encoding takes longer than with JSONEncoder.
+type CBOREncoder struct{}
+
+// ContentType implements Encoder.
+func (CBOREncoder) ContentType() string { return "application/cbor" }
+
+// Encode implements Encoder.
+func (CBOREncoder) Encode(w io.Writer, c Chart) error {
+	v, err := configValue(c)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := writeCBOR(&buf, v); err != nil {
+		return err
+	}
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// writeCBORHead writes the initial byte of a major type with its argument.
+func writeCBORHead(buf *bytes.Buffer, major byte, n uint64) {
+	major <<= 5
+	switch {
+	case n < 24:
+		buf.WriteByte(major | byte(n))
+	case n <= math.MaxUint8:
+		buf.Write([]byte{major | 24, byte(n)})
+	case n <= math.MaxUint16:
+		buf.WriteByte(major | 25)
+		binary.Write(buf, binary.BigEndian, uint16(n))
+	case n <= math.MaxUint32:
+		buf.WriteByte(major | 26)
+		binary.Write(buf, binary.BigEndian, uint32(n))
+	default:
+		buf.WriteByte(major | 27)
+		binary.Write(buf, binary.BigEndian, n)
+	}
+}
+
+func writeCBOR(buf *bytes.Buffer, v interface{}) error {
+	switch v := v.(type) {
+	case nil:
+		buf.WriteByte(0xf6)
+	case bool:
+		if v {
+			buf.WriteByte(0xf5)
+		} else {
+			buf.WriteByte(0xf4)
+		}
+	case string:
+		writeCBORHead(buf, 3, uint64(len(v)))
+		buf.WriteString(v)
+	case json.Number:
+		n, err := number(v)
+		if err != nil {
+			return err
+		}
+		switch n := n.(type) {
+		case int64:
+			if n >= 0 {
+				writeCBORHead(buf, 0, uint64(n))
+			} else {
+				writeCBORHead(buf, 1, uint64(-1-n))
+			}
+		case float64:
+			if float64(float32(n)) == n {
+				buf.WriteByte(0xfa)
+				binary.Write(buf, binary.BigEndian, float32(n))
+			} else {
+				buf.WriteByte(0xfb)
+				binary.Write(buf, binary.BigEndian, n)
+			}
+		}
+	case []interface{}:
+		writeCBORHead(buf, 4, uint64(len(v)))
+		for _, e := range v {
+			if err := writeCBOR(buf, e); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		writeCBORHead(buf, 5, uint64(len(v)))
+		for _, k := range sortedMapKeys(v) {
+			writeCBOR(buf, k)
+			if err := writeCBOR(buf, v[k]); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("chart: cannot encode %T as CBOR", v)
+	}
+	return nil
+}
+
+// DecodeCBOR returns the JSON chart config of a CBOR one written by
+// CBOREncoder. Indefinite lengths, tags and byte strings are not supported.
+func DecodeCBOR(data []byte) ([]byte, error) {
+	r := bytes.NewReader(data)
+	v, err := readCBOR(r, 0)
+	if err != nil {
+		return nil, err
+	}
+	if r.Len() > 0 {
+		return nil, fmt.Errorf("chart: %d trailing bytes after CBOR config", r.Len())
+	}
+	return decodedJSON(v)
+}
+
+func readCBOR(r *bytes.Reader, depth int) (interface{}, error) {
+	if depth > maxDecodeDepth {
+		return nil, fmt.Errorf("chart: CBOR config nested deeper than %d", maxDecodeDepth)
+	}
+	t, err := r.ReadByte()
+	if err != nil {
+		return nil, io.ErrUnexpectedEOF
+	}
+	major, info := t>>5, t&0x1f
+	if major == 7 {
+		switch info {
+		case 20:
+			return false, nil
+		case 21:
+			return true, nil
+		case 22:
+			return nil, nil
+		case 26:
+			n, err := readUint(r, 4)
+			return float64(math.Float32frombits(uint32(n))), err
+		case 27:
+			n, err := readUint(r, 8)
+			return math.Float64frombits(n), err
+		}
+		return nil, fmt.Errorf("chart: unsupported CBOR simple value %d", info)
+	}
+	var n uint64
+	switch {
+	case info < 24:
+		n = uint64(info)
+	case info <= 27:
+		if n, err = readUint(r, 1<<(info-24)); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("chart: unsupported CBOR argument %d", info)
+	}
+	switch major {
+	case 0:
+		if n > math.MaxInt64 {
+			return n, nil
+		}
+		return int64(n), nil
+	case 1:
+		if n > math.MaxInt64 {
+			return nil, fmt.Errorf("chart: CBOR integer out of range")
+		}
+		return -1 - int64(n), nil
+	case 3:
+		b, err := readN(r, n)
+		return string(b), err
+	case 4:
+		if n > uint64(r.Len()) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		a := make([]interface{}, n)
+		for i := range a {
+			if a[i], err = readCBOR(r, depth+1); err != nil {
+				return nil, err
+			}
+		}
+		return a, nil
+	case 5:
+		if n > uint64(r.Len()) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		m := make(map[string]interface{}, n)
+		for i := uint64(0); i < n; i++ {
+			k, err := readCBOR(r, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			key, ok := k.(string)
+			if !ok {
+				return nil, fmt.Errorf("chart: CBOR map key %v is not a string", k)
+			}
+			if m[key], err = readCBOR(r, depth+1); err != nil {
+				return nil, err
+			}
+		}
+		return m, nil
+	}
+	return nil, fmt.Errorf("chart: unsupported CBOR major type %d", major)
+}
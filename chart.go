@@ -6,7 +6,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"html/template"
-	"math"
+	"strconv"
+	"time"
 
 	"github.com/iszk1215/go-chartjs/types"
 )
@@ -15,26 +16,94 @@ var True = types.True
 var False = types.False
 
 var chartTypes = [...]string{
+	"",
 	"line",
 	"bar",
 	"bubble",
+	"doughnut",
+	"radar",
+	"polarArea",
+	"scatter",
+	"candlestick",
+	"ohlc",
+	"boxplot",
+	"violin",
+	"matrix",
+	"treemap",
 }
 
 type chartType int
 
 func (c chartType) MarshalJSON() ([]byte, error) {
-	return []byte(`"` + chartTypes[c] + `"`), nil
+	return []byte(`"` + c.String() + `"`), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface. Registered chart
+// types are recognized by name.
+func (c *chartType) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	for i, n := range chartTypes {
+		if n == s && chartType(i) != unsetType {
+			*c = chartType(i)
+			return nil
+		}
+	}
+	customMu.RLock()
+	defer customMu.RUnlock()
+	for i, t := range customChartTypes {
+		if t.name == s {
+			*c = chartType(len(chartTypes) + i)
+			return nil
+		}
+	}
+	return fmt.Errorf("chart: unknown chart type %q", s)
 }
 
 const (
+	// unsetType is the zero Type. A chart of it is a Line chart and a dataset
+	// of it takes the type of its chart.
+	unsetType chartType = iota
 	// Line is a "line" plot
-	Line chartType = iota
+	Line
 	// Bar is a "bar" plot
 	Bar
 	// Bubble is a "bubble" plot
 	Bubble
+	// Doughnut is a "doughnut" plot. Each value of the dataset is a segment.
+	Doughnut
+	// Radar is a "radar" plot. Use AddRAxis to configure its scale.
+	Radar
+	// PolarArea is a "polarArea" plot. Each value of the dataset is a segment,
+	// see Dataset.BackgroundColors.
+	PolarArea
+	// Scatter is a "scatter" plot: points without lines on a linear x-axis.
+	// See NewScatter.
+	Scatter
+	// Candlestick is a "candlestick" plot of FinancialValues.
+	Candlestick
+	// OHLC is an "ohlc" plot of FinancialValues.
+	OHLC
+	// Boxplot is a "boxplot" plot of BoxplotValues, one per label.
+	Boxplot
+	// Violin is a "violin" plot of raw samples, one []float64 per label.
+	Violin
+	// Matrix is a "matrix" plot of MatrixValues, e.g. a heatmap.
+	Matrix
+	// Treemap is a "treemap" plot, see NewTreemapDataset.
+	Treemap
 )
 
+// or returns the type, def if it is not set.
+func (c chartType) or(def chartType) chartType {
+	if c == unsetType {
+		return def
+	}
+	return c
+}
+
 type interpMode int
 
 const (
@@ -53,12 +122,57 @@ func (m interpMode) MarshalJSON() ([]byte, error) {
 	return []byte(`"` + interpModes[m] + `"`), nil
 }
 
+// UnmarshalJSON implements json.Unmarshaler interface.
+func (m *interpMode) UnmarshalJSON(b []byte) error {
+	i, err := unmarshalEnum(b, interpModes[:], "interpolation mode")
+	*m = interpMode(i)
+	return err
+}
+
 // XFloatFormat determines how many decimal places are sent in the JSON for X values.
 var XFloatFormat = "%.2f"
 
 // YFloatFormat determines how many decimal places are sent in the JSON for Y values.
 var YFloatFormat = "%.2f"
 
+// Precision returns the float format of digits decimal places, e.g. "%.2f"
+// for 2.
+func Precision(digits int) string {
+	if digits < 0 {
+		digits = 0
+	}
+	return "%." + strconv.Itoa(digits) + "f"
+}
+
+// formatSamples are the values float formats are checked with.
+var formatSamples = []float64{0, -1.5, 123.456, 1e-7, 1e21}
+
+// CheckFloatFormat returns an error if format does not write floats as JSON
+// numbers, e.g. "%d", "%s" or "%5.2f", which pads them with spaces.
+func CheckFloatFormat(format string) error {
+	f := parseFloatFormat(format)
+	var b []byte
+	for _, v := range formatSamples {
+		var err error
+		if b, err = f.append(b[:0], v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetFloatFormats sets XFloatFormat and YFloatFormat, after checking them
+// with CheckFloatFormat.
+func SetFloatFormats(x, y string) error {
+	for _, format := range []string{x, y} {
+		if err := CheckFloatFormat(format); err != nil {
+			return err
+		}
+	}
+	XFloatFormat, YFloatFormat = x, y
+	return nil
+}
+
 // Values dictates the interface of data to be plotted.
 type Values interface {
 	// X-axis values. If only these are specified then it must be a Bar plot.
@@ -69,70 +183,82 @@ type Values interface {
 	Rs() []float64
 }
 
-func marshalValuesJSON(v Values, xformat, yformat string) ([]byte, error) {
+// writeFloat writes v using format. NaN and infinities are written as null,
+// and formats that do not produce a JSON number are rejected.
+func writeFloat(buf jsonWriter, format string, v float64) error {
+	f := parseFloatFormat(format)
+	return writeScratch(buf, func(b []byte) ([]byte, error) { return f.append(b, v) })
+}
+
+// validNumber reports whether s is a JSON number.
+func validNumber(s string) bool {
+	if s == "" || s[0] != '-' && (s[0] < '0' || s[0] > '9') {
+		return false
+	}
+	var f float64
+	return json.Unmarshal([]byte(s), &f) == nil
+}
+
+// unmarshalEnum returns the index of the JSON string b in names.
+func unmarshalEnum(b []byte, names []string, kind string) (int, error) {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return 0, err
+	}
+	for i, n := range names {
+		if n == s {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("chart: unknown %s %q", kind, s)
+}
+
+// writeValuesJSON writes the values as a JSON array, of numbers when only
+// one axis is set and of {x, y[, r]} points otherwise.
+func writeValuesJSON(buf jsonWriter, v Values, xformat, yformat string) error {
 	xs, ys, rs := v.Xs(), v.Ys(), v.Rs()
 	if len(xs) == 0 {
 		if len(rs) != 0 {
-			return nil, fmt.Errorf("chart: bad format of Values data")
+			return fmt.Errorf("chart: bad format of Values data")
 		}
 		xs = ys[:len(ys)]
 		ys = nil
 	}
-	buf := bytes.NewBuffer(make([]byte, 0, 8*len(xs)))
-	buf.WriteRune('[')
-	if len(rs) > 0 {
-		if len(xs) != len(ys) || len(xs) != len(rs) {
-			return nil, fmt.Errorf("chart: bad format of Values. All axes must be of the same length")
-		}
-		var err error
-		for i, x := range xs {
-			if i > 0 {
-				buf.WriteRune(',')
-			}
-			y, r := ys[i], rs[i]
-			if math.IsNaN(y) {
-				_, err = buf.WriteString(fmt.Sprintf(("{\"x\":" + xformat + ",\"y\": null,\"r\":" + yformat + "}"), x, r))
-			} else {
-				_, err = buf.WriteString(fmt.Sprintf(("{\"x\":" + xformat + ",\"y\":" + yformat + ",\"r\":" + yformat + "}"), x, y, r))
-			}
-			if err != nil {
-				return nil, err
-			}
-		}
-	} else if len(ys) > 0 {
-		if len(xs) != len(ys) {
-			return nil, fmt.Errorf("chart: bad format of Values. X and Y must be of the same length")
-		}
-		var err error
-		for i, x := range xs {
+	if len(rs) > 0 && (len(xs) != len(ys) || len(xs) != len(rs)) {
+		return fmt.Errorf("chart: bad format of Values. All axes must be of the same length")
+	}
+	if len(ys) > 0 && len(xs) != len(ys) {
+		return fmt.Errorf("chart: bad format of Values. X and Y must be of the same length")
+	}
+	xf, yf := parseFloatFormat(xformat), parseFloatFormat(yformat)
+	buf.WriteByte('[')
+	for i, x := range xs {
+		// each point is appended to a scratch buffer and written at once.
+		if err := writeScratch(buf, func(b []byte) ([]byte, error) {
 			if i > 0 {
-				buf.WriteRune(',')
+				b = append(b, ',')
 			}
-			y := ys[i]
-			if math.IsNaN(y) {
-				_, err = buf.WriteString(fmt.Sprintf(("{\"x\":" + xformat + ",\"y\": null }"), x))
-			} else {
-				_, err = buf.WriteString(fmt.Sprintf(("{\"x\":" + xformat + ",\"y\":" + yformat + "}"), x, y))
+			if len(ys) == 0 {
+				return xf.append(b, x)
 			}
+			b, err := xf.append(append(b, `{"x":`...), x)
 			if err != nil {
-				return nil, err
+				return b, err
 			}
-		}
-
-	} else {
-		for i, x := range xs {
-			if i > 0 {
-				buf.WriteRune(',')
+			if b, err = yf.append(append(b, `,"y":`...), ys[i]); err != nil {
+				return b, err
 			}
-			_, err := buf.WriteString(fmt.Sprintf(xformat, x))
-			if err != nil {
-				return nil, err
+			if len(rs) > 0 {
+				if b, err = yf.append(append(b, `,"r":`...), rs[i]); err != nil {
+					return b, err
+				}
 			}
+			return append(b, '}'), nil
+		}); err != nil {
+			return err
 		}
 	}
-
-	buf.WriteRune(']')
-	return buf.Bytes(), nil
+	return buf.WriteByte(']')
 }
 
 // shape indicates the type of marker used for plotting.
@@ -168,19 +294,48 @@ func (s shape) MarshalJSON() ([]byte, error) {
 	return []byte(`"` + shapes[s] + `"`), nil
 }
 
+// UnmarshalJSON implements json.Unmarshaler interface.
+func (s *shape) UnmarshalJSON(b []byte) error {
+	i, err := unmarshalEnum(b, shapes, "point style")
+	*s = shape(i)
+	return err
+}
+
 // Dataset wraps the "dataset" JSON
 type Dataset struct {
-	Data            interface{} `json:"-"`
+	Data interface{} `json:"-"`
+	// Type defaults to the type of the chart. Set it to mix types, e.g. to
+	// Line for a target line over bars.
 	Type            chartType   `json:"type,omitempty"`
 	BackgroundColor *types.RGBA `json:"backgroundColor,omitempty"`
 	// BorderColor is the color of the line.
 	BorderColor *types.RGBA `json:"borderColor,omitempty"`
 	// BorderWidth is the width of the line.
 	BorderWidth float64 `json:"borderWidth"`
+	// BorderDash is the dash pattern of the line, e.g. []float64{6, 4}.
+	BorderDash []float64 `json:"borderDash,omitempty"`
+	// BackgroundColors and BorderColors color each segment of Doughnut and
+	// PolarArea charts, or each bar. They replace the single colors when set.
+	BackgroundColors []types.RGBA `json:"-"`
+	BorderColors     []types.RGBA `json:"-"`
 
 	// Label indicates the name of the dataset to be shown in the legend.
 	Label string     `json:"label,omitempty"`
 	Fill  types.Bool `json:"fill,omitempty"`
+	// FillTarget fills to another dataset or a boundary instead, e.g. "-1"
+	// for the previous dataset, "origin" or "end".
+	FillTarget string `json:"-"`
+	// Tree, Key and Groups configure a Treemap, see NewTreemapDataset.
+	Tree   []map[string]interface{} `json:"tree,omitempty"`
+	Key    string                   `json:"key,omitempty"`
+	Groups []string                 `json:"groups,omitempty"`
+
+	// Width and Height size the cells of a Matrix, see NewMatrixDataset.
+	Width  JSFunc `json:"width,omitempty"`
+	Height JSFunc `json:"height,omitempty"`
+	// Order is the drawing order. Datasets with a higher order are drawn
+	// below the others.
+	Order int `json:"order,omitempty"`
 
 	// SteppedLine of true means dont interpolate and ignore line tension.
 	SteppedLine            types.Bool  `json:"steppedLine,omitempty"`
@@ -202,15 +357,101 @@ type Dataset struct {
 	// Axis ID that matches the ID on the Axis where this dataset is to be drawn.
 	XAxisID string `json:"xAxisID,omitempty"`
 	YAxisID string `json:"yAxisID,omitempty"`
+	// Stack groups the datasets stacked together on stacked axes. Datasets
+	// of different stacks are drawn side by side, see EnableStacking.
+	Stack string `json:"stack,omitempty"`
+
+	// Doughnut options for this dataset, see Options.Cutout.
+	Cutout        string   `json:"cutout,omitempty"`
+	Rotation      *float64 `json:"rotation,omitempty"`
+	Circumference *float64 `json:"circumference,omitempty"`
+
+	// URLTemplate makes points clickable, navigating to e.g. "/{x}/{label}".
+	// See URLClick for the placeholders.
+	URLTemplate string `json:"urlTemplate,omitempty"`
 
 	// set the formatter for the data, e.g. "%.2f"
 	// these are not exported in the json, just used to determine the decimals of precision to show
 	XFloatFormat string `json:"-"`
 	YFloatFormat string `json:"-"`
+	// NaNPolicy sets how NaN and infinite values are written.
+	NaNPolicy nanPolicy `json:"-"`
+	// TimeFormat is the Go time layout of the x values of TimeValues, e.g.
+	// time.RFC3339. They are written in epoch milliseconds if it is empty.
+	TimeFormat string `json:"-"`
+	// Location is the time zone the times of TimeValues are formatted in
+	// with TimeFormat. Times keep their own location if it is nil.
+	Location *time.Location `json:"-"`
+	// Currency formats the values in the tooltip as money. It is taken from
+	// the value axis of the dataset if nil.
+	Currency *Currency `json:"-"`
+	// Cache keeps the marshaled data until it is touched, see DataCache.
+	Cache *DataCache `json:"-"`
+	// Unit of the values, e.g. "ms" or "Requests/s", used to title their
+	// axis, see Chart.AutoTitleAxes.
+	Unit string `json:"-"`
 }
 
 // MarshalJSON implements json.Marshaler interface.
 func (d Dataset) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := d.writeJSON(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeJSON writes the dataset with its data as the last field.
+func (d Dataset) writeJSON(w jsonWriter) error {
+	// avoid recursion by creating an alias.
+	type alias Dataset
+	var buf []byte
+	var err error
+	if d.BackgroundColors == nil && d.BorderColors == nil && d.FillTarget == "" {
+		buf, err = json.Marshal(alias(d))
+	} else {
+		var fill interface{}
+		if d.FillTarget != "" {
+			fill = d.FillTarget
+		} else if d.Fill != nil {
+			fill = d.Fill
+		}
+		buf, err = json.Marshal(struct {
+			alias
+			BackgroundColor interface{} `json:"backgroundColor,omitempty"`
+			BorderColor     interface{} `json:"borderColor,omitempty"`
+			Fill            interface{} `json:"fill,omitempty"`
+		}{alias(d), colors(d.BackgroundColor, d.BackgroundColors), colors(d.BorderColor, d.BorderColors), fill})
+	}
+	if err != nil {
+		return err
+	}
+	// replace '}' with ',' to continue struct
+	w.Write(buf[:len(buf)-1])
+	if d.Currency != nil {
+		label, err := d.Currency.LabelCallback()
+		if err != nil {
+			return err
+		}
+		cb, err := json.Marshal(label)
+		if err != nil {
+			return err
+		}
+		// the tooltip options of a dataset override those of the chart.
+		w.WriteString(`,"tooltip":{"callbacks":{"label":`)
+		w.Write(cb)
+		w.WriteString(`}}`)
+	}
+	w.WriteString(`,"data":`)
+	if err := d.writeCachedData(w); err != nil {
+		return err
+	}
+	return w.WriteByte('}')
+}
+
+// writeData writes the data of the dataset. Values are written point by
+// point, other data is marshaled first.
+func (d Dataset) writeData(w jsonWriter) error {
 	xf, yf := d.XFloatFormat, d.YFloatFormat
 	if xf == "" {
 		xf = XFloatFormat
@@ -218,37 +459,119 @@ func (d Dataset) MarshalJSON() ([]byte, error) {
 	if yf == "" {
 		yf = YFloatFormat
 	}
+	if err := d.checkFloatFormats(); err != nil {
+		return err
+	}
 
 	var err error
 	var o []byte
-	if m, ok := d.Data.(json.Marshaler); ok {
+	if c := d.Currency; c != nil {
+		// write the minor unit digits of the currency by default.
+		f := fmt.Sprintf("%%.%df", c.digits())
+		v, ok := d.Data.(Values)
+		if d.YFloatFormat == "" {
+			yf = f
+		}
+		if d.XFloatFormat == "" && ok && len(v.Ys()) == 0 {
+			xf = f
+		}
+		if c.MinorUnits {
+			if _, m := d.Data.(json.Marshaler); m || !ok {
+				return fmt.Errorf("chart: dataset %q has minor units of %s but its data are not Values", d.Label, c.Code)
+			}
+			d.Data = c.scale(v)
+		}
+	}
+	if _, ok := d.Data.(json.Marshaler); ok {
+		// encoded by the data itself.
+	} else if v, ok := d.Data.(Values); ok && d.NaNPolicy != NaNNull {
+		if d.Data, err = applyNaNPolicy(v, d.NaNPolicy, d.Label); err != nil {
+			return err
+		}
+	}
+	// raw data, e.g. read by UnmarshalJSON, is written as is.
+	_, raw := d.Data.(json.RawMessage)
+	if t, ok := d.Type.custom(); ok && t.marshal != nil && !raw {
+		o, err = t.marshal(d)
+	} else if m, ok := d.Data.(json.Marshaler); ok {
 		o, err = m.MarshalJSON()
+	} else if v, ok := d.Data.(MetaValues); ok {
+		o, err = marshalMetaValuesJSON(v, xf, yf)
+	} else if v, ok := d.Data.(MatrixValues); ok {
+		o, err = marshalMatrixValuesJSON(v, xf, yf)
+	} else if v, ok := d.Data.(FinancialValues); ok {
+		o, err = marshalFinancialValuesJSON(v, yf)
+	} else if v, ok := d.Data.(TimeValues); ok {
+		return writeTimeValuesJSON(w, v, d.TimeFormat, d.Location, yf)
 	} else if v, ok := d.Data.(Values); ok {
-		o, err = marshalValuesJSON(v, xf, yf)
+		return writeValuesJSON(w, v, xf, yf)
+	} else if d.Data != nil {
+		o, err = json.Marshal(d.Data)
+	} else {
+		o = []byte("[]")
 	}
 	if err != nil {
-		return nil, err
+		return err
 	}
-	// avoid recursion by creating an alias.
-	type alias Dataset
-	buf, err := json.Marshal(alias(d))
-	if err != nil {
-		return nil, err
+	// compact and escape the data as json.Marshal does for Marshalers.
+	var buf, escaped bytes.Buffer
+	if err := json.Compact(&buf, o); err != nil {
+		return err
 	}
-	// replace '}' with ',' to continue struct
-	if len(buf) > 0 {
-		buf[len(buf)-1] = ','
+	json.HTMLEscape(&escaped, buf.Bytes())
+	_, err = w.Write(escaped.Bytes())
+	return err
+}
+
+// checkFloatFormats checks the float formats of the dataset, so that a bad
+// format fails before any value is written.
+func (d Dataset) checkFloatFormats() error {
+	for _, format := range []string{d.XFloatFormat, d.YFloatFormat} {
+		if format == "" {
+			continue
+		}
+		if CheckFloatFormat(format) != nil {
+			return fmt.Errorf("chart: dataset %q has float format %q, which does not write JSON numbers", d.Label, format)
+		}
+	}
+	return nil
+}
+
+// colors returns cs, or c when cs is not set, as a value omitted when empty.
+func colors(c *types.RGBA, cs []types.RGBA) interface{} {
+	if cs != nil {
+		return cs
+	}
+	if c != nil {
+		return c
 	}
-	buf = append(buf, []byte(`"data":`)...)
-	buf = append(buf, o...)
-	buf = append(buf, '}')
-	return buf, nil
+	return nil
 }
 
 // Data wraps the "data" JSON
 type Data struct {
 	Datasets []Dataset `json:"datasets"`
 	Labels   []string  `json:"labels"`
+	// LabelLines replaces Labels with multi-line labels when set.
+	LabelLines [][]string `json:"-"`
+	// FullLabels holds the untruncated labels shown in tooltips, see TruncateLabels.
+	FullLabels []string `json:"fullLabels,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler interface.
+func (d Data) MarshalJSON() ([]byte, error) {
+	labels, err := d.valueLabels()
+	if err != nil {
+		return nil, err
+	}
+	if len(d.Labels) == 0 && d.LabelLines == nil {
+		d.Labels = labels
+	}
+	var buf bytes.Buffer
+	if err := d.writeJSON(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
 }
 
 type axisType int
@@ -279,6 +602,13 @@ func (t axisType) MarshalJSON() ([]byte, error) {
 	return []byte("\"" + axisTypes[t] + "\""), nil
 }
 
+// UnmarshalJSON implements json.Unmarshaler interface.
+func (t *axisType) UnmarshalJSON(b []byte) error {
+	i, err := unmarshalEnum(b, axisTypes, "axis type")
+	*t = axisType(i)
+	return err
+}
+
 type axisPosition int
 
 const (
@@ -304,34 +634,141 @@ func (p axisPosition) MarshalJSON() ([]byte, error) {
 	return []byte(`"` + axisPositions[p] + `"`), nil
 }
 
+// UnmarshalJSON implements json.Unmarshaler interface.
+func (p *axisPosition) UnmarshalJSON(b []byte) error {
+	i, err := unmarshalEnum(b, axisPositions, "axis position")
+	*p = axisPosition(i)
+	return err
+}
+
 type AxisTitle struct {
 	Display bool   `json:"display,omitempty"`
 	Text    string `json:"text,omitempty"`
+	// TextLines replaces Text with multi-line text when set.
+	TextLines []string `json:"-"`
+}
+
+// MarshalJSON implements json.Marshaler interface.
+func (t AxisTitle) MarshalJSON() ([]byte, error) {
+	type alias AxisTitle
+	if t.TextLines == nil {
+		return json.Marshal(alias(t))
+	}
+	return json.Marshal(struct {
+		alias
+		Text []string `json:"text"`
+	}{alias(t), t.TextLines})
 }
 
 // Axis corresponds to 'scale' in chart.js lingo.
 type Axis struct {
-	Type      axisType     `json:"type"`
-	Position  axisPosition `json:"position,omitempty"`
-	Label     string       `json:"label,omitempty"`
-	ID        string       `json:"-"`
-	GridLines types.Bool   `json:"gridLine,omitempty"`
-	Stacked   types.Bool   `json:"stacked,omitempty"`
+	Type     axisType     `json:"type"`
+	Position axisPosition `json:"position,omitempty"`
+	Label    string       `json:"label,omitempty"`
+	ID       string       `json:"-"`
+	// GridLines shows or hides the grid lines.
+	//
+	// Deprecated: use Grid.Display, which takes precedence.
+	GridLines types.Bool `json:"-"`
+	Grid      *Grid      `json:"grid,omitempty"`
+	Stacked   types.Bool `json:"stacked,omitempty"`
 
 	// Bool differentiates between false and empty by use of pointer.
 	Display    types.Bool  `json:"display,omitempty"`
 	ScaleLabel *ScaleLabel `json:"scaleLabel,omitempty"`
 	Tick       *Tick       `json:"ticks,omitempty"`
+	// Labels overrides the category labels of this axis. Each label may span
+	// several lines.
+	Labels [][]string `json:"labels,omitempty"`
+
+	// Options of Radial axes.
+	AngleLines   *AngleLines  `json:"angleLines,omitempty"`
+	PointLabels  *PointLabels `json:"pointLabels,omitempty"`
+	SuggestedMin *float64     `json:"suggestedMin,omitempty"`
+	SuggestedMax *float64     `json:"suggestedMax,omitempty"`
+
+	// Time holds the options of Time axes.
+	Time *TimeOptions `json:"time,omitempty"`
+	// Adapters configure the date library of Time axes, e.g. their time
+	// zone, see ZoneAdapters.
+	Adapters *Adapters `json:"adapters,omitempty"`
+	// Currency formats the ticks as money, unless Tick has a Callback. The
+	// datasets on the axis without a Currency take it.
+	Currency *Currency `json:"-"`
 
 	Title AxisTitle `json:"title,omitempty"`
 }
 
-// Tick lets us set the range of the data.
+// MarshalJSON implements json.Marshaler interface.
+func (a Axis) MarshalJSON() ([]byte, error) {
+	type alias Axis
+	if a.GridLines != nil && (a.Grid == nil || a.Grid.Display == nil) {
+		g := Grid{}
+		if a.Grid != nil {
+			g = *a.Grid
+		}
+		g.Display = a.GridLines
+		a.Grid = &g
+	}
+	return json.Marshal(alias(a))
+}
+
+// Grid styles the grid lines of an axis.
+type Grid struct {
+	Display types.Bool  `json:"display,omitempty"`
+	Color   *types.RGBA `json:"color,omitempty"`
+	// LineWidth in pixels.
+	LineWidth float64 `json:"lineWidth,omitempty"`
+	// DrawOnChartArea draws the lines across the chart, not only next to
+	// the axis.
+	DrawOnChartArea types.Bool `json:"drawOnChartArea,omitempty"`
+	// DrawTicks draws the lines next to the ticks.
+	DrawTicks types.Bool `json:"drawTicks,omitempty"`
+	// BorderDash is the lengths of the dashes and gaps, e.g. {4, 2}.
+	BorderDash []float64 `json:"borderDash,omitempty"`
+	// TickLength is the length of the lines into the axis area in pixels.
+	TickLength int `json:"tickLength,omitempty"`
+}
+
+// AngleLines are the lines from the center of a Radial axis to its edge.
+type AngleLines struct {
+	Display   types.Bool  `json:"display,omitempty"`
+	Color     *types.RGBA `json:"color,omitempty"`
+	LineWidth float64     `json:"lineWidth,omitempty"`
+}
+
+// PointLabels are the labels around the edge of a Radial axis.
+type PointLabels struct {
+	Display   types.Bool  `json:"display,omitempty"`
+	FontColor *types.RGBA `json:"fontColor,omitempty"`
+	FontSize  int         `json:"fontSize,omitempty"`
+}
+
+// Tick lets us set the range of the data, and the density and style of the
+// tick labels.
 type Tick struct {
 	Min         float64    `json:"min,omitempty"`
 	Max         float64    `json:"max,omitempty"`
 	BeginAtZero types.Bool `json:"beginAtZero,omitempty"`
-	// TODO: add additional options from: tick options.
+	Reverse     types.Bool `json:"reverse,omitempty"`
+	StepSize    float64    `json:"stepSize,omitempty"`
+	// Callback formats tick labels: function(value, index, values).
+	Callback JSFunc `json:"callback,omitempty"`
+	// MaxRotation and MinRotation bound the rotation of labels in degrees.
+	// Pointers differentiate 0 from unset.
+	MaxRotation *int `json:"maxRotation,omitempty"`
+	MinRotation *int `json:"minRotation,omitempty"`
+	// MaxTicksLimit is the maximum number of ticks and grid lines.
+	MaxTicksLimit int `json:"maxTicksLimit,omitempty"`
+	// AutoSkip skips labels that would overlap, which is the default.
+	AutoSkip types.Bool `json:"autoSkip,omitempty"`
+	// AutoSkipPadding is the space between labels before they are skipped,
+	// in pixels.
+	AutoSkipPadding int `json:"autoSkipPadding,omitempty"`
+	// Padding between the labels and the axis in pixels.
+	Padding *int        `json:"padding,omitempty"`
+	Font    *Font       `json:"font,omitempty"`
+	Color   *types.RGBA `json:"color,omitempty"`
 }
 
 // ScaleLabel corresponds to scale title.
@@ -343,6 +780,20 @@ type ScaleLabel struct {
 	FontFamily  string      `json:"fontFamily,omitempty"`
 	FontSize    int         `json:"fontSize,omitempty"`
 	FontStyle   string      `json:"fontStyle,omitempty"`
+	// LabelLines replaces LabelString with multi-line text when set.
+	LabelLines []string `json:"-"`
+}
+
+// MarshalJSON implements json.Marshaler interface.
+func (l ScaleLabel) MarshalJSON() ([]byte, error) {
+	type alias ScaleLabel
+	if l.LabelLines == nil {
+		return json.Marshal(alias(l))
+	}
+	return json.Marshal(struct {
+		alias
+		LabelString []string `json:"labelString"`
+	}{alias(l), l.LabelLines})
 }
 
 // Option wraps the chartjs "option"
@@ -350,26 +801,76 @@ type Option struct {
 	Responsive          types.Bool `json:"responsive,omitempty"`
 	MaintainAspectRatio types.Bool `json:"maintainAspectRatio,omitempty"`
 	Title               *Title     `json:"title,omitempty"`
+	// Subtitle is shown below the title.
+	Subtitle *Title `json:"subtitle,omitempty"`
 }
 
-// Title is the Options title
+// Title is the Options title, and subtitle.
 type Title struct {
-	Display types.Bool `json:"display,omitempty"`
-	Text    string     `json:"text,omitempty"`
+	Display   types.Bool  `json:"display,omitempty"`
+	Text      string      `json:"text,omitempty"`
+	FontColor *types.RGBA `json:"fontColor,omitempty"`
+	// Position is Top by default.
+	Position axisPosition `json:"position,omitempty"`
+	Align    align        `json:"align,omitempty"`
+	Font     *Font        `json:"font,omitempty"`
+	Color    *types.RGBA  `json:"color,omitempty"`
+	// Padding above and below the title in pixels. A pointer
+	// differentiates 0 from unset.
+	Padding *int `json:"padding,omitempty"`
+	// TextLines replaces Text with multi-line text when set.
+	TextLines []string `json:"-"`
 }
 
-type Animation struct {
-	Duration int `json:"duration"`
+// MarshalJSON implements json.Marshaler interface.
+func (t Title) MarshalJSON() ([]byte, error) {
+	type alias Title
+	if t.TextLines == nil {
+		return json.Marshal(alias(t))
+	}
+	return json.Marshal(struct {
+		alias
+		Text []string `json:"text"`
+	}{alias(t), t.TextLines})
 }
 
 // Options wraps the chartjs "options"
 type Options struct {
 	Option
-	Scales    map[string]Axis              `json:"scales,omitempty"`
-	Legend    *Legend                      `json:"legend,omitempty"`
-	Tooltip   *Tooltip                     `json:"tooltips,omitempty"`
-	Animation Animation                    `json:"animation,omitempty"`
-	Plugins   map[string]map[string]string `json:"plugins,omitempty"`
+	Scales    map[string]Axis `json:"scales,omitempty"`
+	Legend    *Legend         `json:"legend,omitempty"`
+	Tooltip   *Tooltip        `json:"tooltips,omitempty"`
+	Animation Animation       `json:"animation,omitempty"`
+	// Animations animate single properties, by the name of the animation,
+	// e.g. "tension".
+	Animations map[string]PropertyAnimation `json:"animations,omitempty"`
+	Plugins    map[string]map[string]string `json:"plugins,omitempty"`
+	// OnClick is called with the event and the active elements.
+	// It defaults to URLClick when a dataset has a URLTemplate.
+	OnClick JSFunc `json:"onClick,omitempty"`
+
+	// chartjs-plugin-dragdata options, see EnableDragData.
+	DragData      types.Bool `json:"dragData,omitempty"`
+	DragX         types.Bool `json:"dragX,omitempty"`
+	DragDataRound int        `json:"dragDataRound,omitempty"`
+	OnDragEnd     JSFunc     `json:"onDragEnd,omitempty"`
+
+	// Cutout is the size of the hole of a Doughnut in pixels, or in percent of
+	// the radius, e.g. "50%".
+	Cutout string `json:"cutout,omitempty"`
+	// Rotation is the starting angle of a Doughnut in degrees, and
+	// Circumference the sweep of its arcs. Rotation: -90 with Circumference:
+	// 180 gives a half-doughnut gauge.
+	Rotation      *float64 `json:"rotation,omitempty"`
+	Circumference *float64 `json:"circumference,omitempty"`
+	// StartAngle is the starting angle of a PolarArea in degrees.
+	StartAngle *float64 `json:"startAngle,omitempty"`
+
+	// IndexAxis is "y" for horizontal bars.
+	IndexAxis string `json:"indexAxis,omitempty"`
+
+	// Annotation holds chartjs-plugin-annotation options, see AddAnnotation.
+	Annotation *Annotations `json:"annotation,omitempty"`
 }
 
 // Tooltip wraps chartjs "tooltips".
@@ -378,24 +879,202 @@ type Tooltip struct {
 	Enabled   types.Bool `json:"enabled,omitempty"`
 	Intersect types.Bool `json:"intersect,omitempty"`
 	// TODO: make mode typed by Interaction modes.
-	Mode   string         `json:"mode,omitempty"`
-	Custom template.JSStr `json:"custom,omitempty"`
+	Mode      string            `json:"mode,omitempty"`
+	Custom    template.JSStr    `json:"custom,omitempty"`
+	Callbacks *TooltipCallbacks `json:"callbacks,omitempty"`
+}
+
+// TooltipCallbacks holds JavaScript functions that customize tooltip text.
+// They are written as code, not strings, when the chart is embedded in HTML,
+// e.g.
+//
+//	c.Options.Tooltip.Callbacks = &TooltipCallbacks{
+//		Label: "function(item) { return item.formattedValue + ' ms'; }",
+//	}
+type TooltipCallbacks struct {
+	BeforeTitle     template.JSStr
+	Title           template.JSStr
+	AfterTitle      template.JSStr
+	BeforeBody      template.JSStr
+	BeforeLabel     template.JSStr
+	Label           template.JSStr
+	LabelColor      template.JSStr
+	LabelTextColor  template.JSStr
+	LabelPointStyle template.JSStr
+	AfterLabel      template.JSStr
+	AfterBody       template.JSStr
+	BeforeFooter    template.JSStr
+	Footer          template.JSStr
+	AfterFooter     template.JSStr
+}
+
+// callbacks returns the fields of t by their chart.js name.
+func (t *TooltipCallbacks) callbacks() map[string]*template.JSStr {
+	return map[string]*template.JSStr{
+		"beforeTitle":     &t.BeforeTitle,
+		"title":           &t.Title,
+		"afterTitle":      &t.AfterTitle,
+		"beforeBody":      &t.BeforeBody,
+		"beforeLabel":     &t.BeforeLabel,
+		"label":           &t.Label,
+		"labelColor":      &t.LabelColor,
+		"labelTextColor":  &t.LabelTextColor,
+		"labelPointStyle": &t.LabelPointStyle,
+		"afterLabel":      &t.AfterLabel,
+		"afterBody":       &t.AfterBody,
+		"beforeFooter":    &t.BeforeFooter,
+		"footer":          &t.Footer,
+		"afterFooter":     &t.AfterFooter,
+	}
 }
 
-type Legend struct {
-	Display types.Bool `json:"display,omitempty"`
+// MarshalJSON implements json.Marshaler interface.
+func (t TooltipCallbacks) MarshalJSON() ([]byte, error) {
+	m := map[string]JSFunc{}
+	for name, f := range t.callbacks() {
+		if *f != "" {
+			m[name] = JSFunc(*f)
+		}
+	}
+	return json.Marshal(m)
 }
 
 // Chart is the top-level type from chartjs.
 type Chart struct {
+	// Type defaults to Line.
 	Type    chartType `json:"type"`
 	Label   string    `json:"label,omitempty"`
 	Data    Data      `json:"data,omitempty"`
 	Options Options   `json:"options,omitempty"`
+
+	// Alerts restyle the chart in the generated HTML when triggered.
+	Alerts []AlertRule `json:"-"`
+	// EmptyText replaces the canvas in the generated HTML when the chart IsEmpty.
+	EmptyText string `json:"-"`
+	// DrillDown makes categories clickable to show child charts.
+	DrillDown *DrillDown `json:"-"`
+	// Brush posts drag selections back to the server.
+	Brush *Brush `json:"-"`
+	// DataURL makes the generated HTML fetch the chart data from a DataHandler
+	// and parse it in a web worker instead of embedding it in the page.
+	DataURL string `json:"-"`
+	// Requires names extra Plugins to load with the chart.
+	Requires []string `json:"-"`
+	// InlinePlugins are plugin objects registered with this chart only.
+	InlinePlugins []JSFunc `json:"plugins,omitempty"`
+	// Watermark stamps a text or logo on the chart area.
+	Watermark *Watermark `json:"-"`
+	// AutoCreateAxes adds the axes referenced by datasets but missing from
+	// Options.Scales when the chart is marshaled. See CreateMissingAxes.
+	AutoCreateAxes bool `json:"-"`
+	// AutoTitleAxes titles the axes without a title from the Unit and label
+	// of their datasets, e.g. "Requests/s", and Time axes from their zone,
+	// e.g. "Time (UTC)". The default value axis is created if needed.
+	AutoTitleAxes bool `json:"-"`
+	// TargetVersion selects the chart.js major version whose configuration
+	// schema is emitted.
+	TargetVersion chartJSVersion `json:"-"`
+	// Redactor is applied to the labels and values of the datasets when the
+	// chart is marshaled. Values embedded in callbacks, e.g. by BreakAxis, are
+	// not redacted.
+	Redactor Redactor `json:"-"`
+	// Middlewares are applied to a copy of the chart before it is marshaled
+	// or rendered, see Use.
+	Middlewares []Middleware `json:"-"`
+}
+
+// MarshalJSON implements json.Marshaler interface. JSFunc values are
+// strings of their code, so that the output doesn't depend on the process.
+func (c Chart) MarshalJSON() ([]byte, error) {
+	b, err := c.MarshalTagged()
+	if err != nil {
+		return nil, err
+	}
+	return StripJS(b), nil
+}
+
+// MarshalTagged returns the JSON of the chart with JSFunc values as tagged
+// strings, which InlineJS replaces with their code. The tags change with
+// every process, so the output is only meant to be processed before InlineJS.
+func (c Chart) MarshalTagged() ([]byte, error) {
+	c, err := c.prepare()
+	if err != nil {
+		return nil, err
+	}
+	return c.marshal()
+}
+
+// prepare returns the chart as it is marshaled, with its middlewares and
+// the options that change the config applied.
+func (c Chart) prepare() (Chart, error) {
+	c, err := c.applyMiddlewares()
+	if err != nil {
+		return c, err
+	}
+	if _, ok := c.Type.custom(); ok {
+		// datasets of the zero Type take the registered type of the chart.
+		datasets := make([]Dataset, len(c.Data.Datasets))
+		for i, d := range c.Data.Datasets {
+			d.Type = d.Type.or(c.Type)
+			datasets[i] = d
+		}
+		c.Data.Datasets = datasets
+	}
+	if err := c.applyCurrencies(); err != nil {
+		return c, err
+	}
+	if c.Options.OnClick == "" && c.hasURLs() {
+		c.Options.OnClick = URLClick
+	}
+	if c.Redactor != nil {
+		data, err := c.Data.redact(c.Redactor)
+		if err != nil {
+			return c, err
+		}
+		c.Data = data
+	}
+	if c.Watermark != nil {
+		p, err := c.Watermark.plugin()
+		if err != nil {
+			return c, err
+		}
+		c.InlinePlugins = append(c.InlinePlugins[:len(c.InlinePlugins):len(c.InlinePlugins)], p)
+	}
+	if c.AutoCreateAxes {
+		scales := make(map[string]Axis, len(c.Options.Scales))
+		for id, a := range c.Options.Scales {
+			scales[id] = a
+		}
+		c.Options.Scales = scales
+		c.CreateMissingAxes()
+	}
+	if c.AutoTitleAxes {
+		c.applyAutoTitles()
+	}
+	c.applyLegendOverflow()
+	return c, c.ValidateAxes()
 }
 
-// AddDataset adds a dataset to the chart.
+// NewScatter returns a Scatter chart of the points (xs[i], ys[i]) with a
+// Linear x-axis.
+func NewScatter(xs, ys []float64) (*Chart, error) {
+	if len(xs) != len(ys) {
+		return nil, fmt.Errorf("chart: bad format of Values. X and Y must be of the same length")
+	}
+	c := &Chart{Type: Scatter}
+	if _, err := c.AddXAxis(Axis{Type: Linear, Position: Bottom}); err != nil {
+		return nil, err
+	}
+	c.AddDataset(Dataset{Data: xyValues{xs: xs, ys: ys}})
+	return c, nil
+}
+
+// AddDataset adds a dataset to the chart. Data.Labels is set from
+// LabeledValues if it is empty.
 func (c *Chart) AddDataset(d Dataset) {
+	if v, ok := d.Data.(LabeledValues); ok && len(c.Data.Labels) == 0 {
+		c.Data.Labels = v.Labels()
+	}
 	c.Data.Datasets = append(c.Data.Datasets, d)
 }
 
@@ -406,6 +1085,16 @@ func (c *Chart) AddAxis(axis Axis) {
 	c.Options.Scales[axis.ID] = axis
 }
 
+// AddRAxis adds the radial axis of a Radar chart and returns its ID.
+func (c *Chart) AddRAxis(r Axis) string {
+	if r.ID == "" {
+		r.ID = "r"
+	}
+	r.Type = Radial
+	c.AddAxis(r)
+	return r.ID
+}
+
 // AddXAxis adds an x-axis to the chart and returns the ID of the added axis.
 func (c *Chart) AddXAxis(x Axis) (string, error) {
 	if x.ID == "" {
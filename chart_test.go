@@ -1,12 +1,27 @@
 package chartjs
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"encoding/xml"
+	"errors"
 	"fmt"
+	"html/template"
+	"image/png"
+	"io"
+	"io/ioutil"
 	"math"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"reflect"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/iszk1215/go-chartjs/types"
 )
@@ -116,7 +131,7 @@ func TestHTML(t *testing.T) {
 	if err != nil {
 		t.Fatalf("error opening file: %+v", err)
 	}
-	if err := chart.SaveHTML(wtr, nil); err != nil {
+	if err := chart.SaveHTML(wtr, RenderOptions{}); err != nil {
 		t.Fatalf("error saving chart: %+v", err)
 	}
 	wtr.Close()
@@ -170,7 +185,7 @@ func TestMultipleCharts(t *testing.T) {
 	if err != nil {
 		t.Fatalf("error opening file: %+v", err)
 	}
-	if err := chart.SaveHTML(wtr, nil); err != nil {
+	if err := chart.SaveHTML(wtr, RenderOptions{}); err != nil {
 		t.Fatalf("error saving chart: %+v", err)
 	}
 	wtr.Close()
@@ -202,8 +217,3649 @@ func TestAnno(t *testing.T) {
 	if err != nil {
 		t.Fatalf("error opening file: %+v", err)
 	}
-	if err := chart.SaveHTML(wtr, nil); err != nil {
+	if err := chart.SaveHTML(wtr, RenderOptions{}); err != nil {
 		t.Fatalf("error saving chart: %+v", err)
 	}
 	wtr.Close()
 }
+
+func TestAlerts(t *testing.T) {
+	chart := Chart{Type: Line, Options: Options{Option: Option{Title: &Title{Text: "latency"}}}}
+	chart.AddDataset(Dataset{Label: "p99", Data: xy{x: []float64{0, 1, 2}, y: []float64{10, 80, 20}}})
+	chart.AddAlert(AlertRule{Dataset: "p99", Op: Above, Threshold: 50, Style: AlertStyle{TitleColor: &types.RGBA{R: 255, A: 255}}})
+	chart.AddAlert(AlertRule{Op: Below, Threshold: 0})
+
+	res := chart.EvaluateAlerts()
+	if !res[0].Triggered || res[0].Index != 1 || res[0].Value != 80 {
+		t.Errorf("expected first rule to trigger at index 1, got %+v", res[0])
+	}
+	if res[1].Triggered {
+		t.Errorf("expected second rule not to trigger")
+	}
+	if !chart.Alerting() {
+		t.Errorf("expected chart to be alerting")
+	}
+
+	c, _ := chart.applyAlerts()
+	if c.Options.Title.FontColor == nil || chart.Options.Title.FontColor != nil {
+		t.Errorf("expected title color on the copy only")
+	}
+}
+
+func TestEmpty(t *testing.T) {
+	chart := Chart{Type: Line, EmptyText: "nothing yet"}
+	if !chart.IsEmpty() {
+		t.Errorf("expected chart without datasets to be empty")
+	}
+	chart.AddDataset(Dataset{Data: xy{}})
+	if !chart.IsEmpty() {
+		t.Errorf("expected chart with empty dataset to be empty")
+	}
+
+	var buf bytes.Buffer
+	if err := chart.SaveHTML(&buf, RenderOptions{}); err != nil {
+		t.Fatalf("error saving chart: %+v", err)
+	}
+	if !strings.Contains(buf.String(), "nothing yet") {
+		t.Errorf("expected placeholder text in output")
+	}
+
+	chart.AddDataset(Dataset{Data: xy{x: []float64{1}, y: []float64{2}}})
+	if chart.IsEmpty() {
+		t.Errorf("expected chart with points not to be empty")
+	}
+}
+
+func TestTimeGaps(t *testing.T) {
+	start := time.Date(2024, 3, 8, 8, 0, 0, 0, time.UTC) // a Friday
+	var ts []time.Time
+	var ys []float64
+	for i := 0; i < 4*24; i++ {
+		ts = append(ts, start.Add(time.Duration(i)*time.Hour))
+		ys = append(ys, float64(i))
+	}
+	g := TimeGaps{Skip: func(t time.Time) bool { return Weekends(t) || OutsideHours(9, 17)(t) }}
+	v, labels, err := g.Compress(ts, ys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Friday 9-17 and Monday 9-17.
+	if len(v.Ys()) != 16 || len(labels) != 16 {
+		t.Fatalf("expected 16 points, got %d", len(v.Ys()))
+	}
+	if labels[0] != "2024-03-08 09:00" || labels[8] != "2024-03-11 09:00" {
+		t.Errorf("unexpected labels: %v", labels)
+	}
+	if _, _, err := g.Compress(ts, ys[1:]); err == nil {
+		t.Error("expected an error for times and values of different lengths")
+	}
+}
+
+type metaXY struct {
+	xy
+	meta []map[string]string
+}
+
+func (v metaXY) Meta() []map[string]string {
+	return v.meta
+}
+
+func TestMeta(t *testing.T) {
+	v := metaXY{xy{x: []float64{1, 2}, y: []float64{3, math.NaN()}},
+		[]map[string]string{{"sha": "abc"}, {"sha": "d\"ef"}}}
+	chart := Chart{Type: Line}
+	chart.AddDataset(Dataset{Data: v})
+	chart.ShowMetaInTooltips()
+
+	b, err := chart.MarshalTagged()
+	if err != nil {
+		t.Fatalf("error marshaling chart: %+v", err)
+	}
+	if !strings.Contains(string(b), `{"x":1.00,"y":3.00,"meta":{"sha":"abc"}}`) ||
+		!strings.Contains(string(b), `{"x":2.00,"y":null,"meta":{"sha":"d\"ef"}}`) {
+		t.Errorf("unexpected data: %s", b)
+	}
+
+	js := string(inlineJS(b))
+	if !strings.Contains(js, `"footer":function(items, data) {`) {
+		t.Errorf("expected footer function to be inlined: %s", js)
+	}
+}
+
+func TestURLTemplate(t *testing.T) {
+	chart := Chart{Type: Bar, Data: Data{Labels: []string{"a", "b"}}}
+	chart.AddDataset(Dataset{Label: "hosts", Data: xy{x: []float64{1, 2}}, URLTemplate: "/hosts/{x}"})
+	b, err := chart.MarshalTagged()
+	if err != nil {
+		t.Fatalf("error marshaling chart: %+v", err)
+	}
+	js := string(inlineJS(b))
+	if !strings.Contains(js, `"urlTemplate":"/hosts/{x}"`) || !strings.Contains(js, `"onClick":function(evt, elements)`) {
+		t.Errorf("expected click handler and url template: %s", js)
+	}
+}
+
+func TestDrillDown(t *testing.T) {
+	h := DrillDownHandler(func(path []string) (*Chart, error) {
+		if len(path) > 1 {
+			return nil, nil
+		}
+		c := &Chart{Type: Bar, Data: Data{Labels: []string{path[0] + "-a"}}}
+		c.AddDataset(Dataset{Data: xy{x: []float64{1}}})
+		c.AddAxis(Axis{Type: Linear, Position: Left, Tick: &Tick{Callback: `function(v) { return v + "%"; }`}})
+		return c, nil
+	})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/drill?path=eu", nil))
+	if rec.Code != 200 || !strings.Contains(rec.Body.String(), `"labels":["eu-a"]`) {
+		t.Errorf("unexpected response %d: %s", rec.Code, rec.Body)
+	}
+	if rec.Header().Get("Content-Type") != "text/javascript" || !strings.Contains(rec.Body.String(), `"callback":function(v) { return v + "%"; }`) {
+		t.Errorf("expected the callback as code: %s", rec.Body)
+	}
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/drill?path=eu&path=x", nil))
+	if rec.Code != 404 {
+		t.Errorf("expected 404 for leaf, got %d", rec.Code)
+	}
+
+	chart := Chart{Type: Bar, DrillDown: &DrillDown{URL: "/drill"}}
+	chart.AddDataset(Dataset{Data: xy{x: []float64{1}}})
+	var buf bytes.Buffer
+	if err := chart.SaveHTML(&buf, RenderOptions{}); err != nil {
+		t.Fatalf("error saving chart: %+v", err)
+	}
+	if !strings.Contains(buf.String(), `chartjsDrillDown( 0 ,`) || !strings.Contains(buf.String(), `id="crumbs0"`) {
+		t.Errorf("expected drill down wiring in output")
+	}
+}
+
+func TestCrossFilter(t *testing.T) {
+	a := Chart{Type: Bar, Data: Data{Labels: []string{"us", "eu"}}}
+	a.AddDataset(Dataset{Data: xy{x: []float64{1, 2}}})
+	b := Chart{Type: Line}
+	b.AddDataset(Dataset{Label: "us", Data: xy{x: []float64{1, 2}, y: []float64{1, 2}}})
+
+	var buf bytes.Buffer
+	if err := SaveCharts(&buf, RenderOptions{CrossFilter: true}.tmap(), a, b); err != nil {
+		t.Fatalf("error saving charts: %+v", err)
+	}
+	if strings.Count(buf.String(), `"onClick":chartjsCrossFilter`) != 2 {
+		t.Errorf("expected both charts to be wired for cross filtering")
+	}
+}
+
+func TestSelectionHandler(t *testing.T) {
+	var got Selection
+	h := SelectionHandler(func(s Selection) error {
+		got = s
+		return nil
+	})
+	body := `{"chart":"cpu","xMin":1,"xMax":3,"points":[{"dataset":"a","index":2,"x":2,"y":5}]}`
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("POST", "/select", strings.NewReader(body)))
+	if rec.Code != 204 || got.Chart != "cpu" || len(got.Points) != 1 || got.Points[0].Y != 5 {
+		t.Errorf("unexpected selection %d: %+v", rec.Code, got)
+	}
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("POST", "/select", strings.NewReader("{")))
+	if rec.Code != 400 {
+		t.Errorf("expected 400 for bad body, got %d", rec.Code)
+	}
+}
+
+func TestDragData(t *testing.T) {
+	chart := Chart{Type: Line, Label: "forecast"}
+	chart.AddDataset(Dataset{Label: "plan", Data: xy{x: []float64{1, 2}, y: []float64{3, 4}}})
+	chart.EnableDragData(DragData{Round: 1, URL: "/edit"})
+
+	var buf bytes.Buffer
+	if err := chart.SaveHTML(&buf, RenderOptions{}); err != nil {
+		t.Fatalf("error saving chart: %+v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, Plugins["dragdata"].Src) || !strings.Contains(out, `"dragData":true`) ||
+		!strings.Contains(out, `"onDragEnd":function(e, datasetIndex, index, value)`) {
+		t.Errorf("expected dragdata plugin and options in output")
+	}
+
+	var got DragEvent
+	h := DragHandler(func(e DragEvent) error {
+		got = e
+		return nil
+	})
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("POST", "/edit", strings.NewReader(`{"chart":"forecast","index":1,"y":4.5}`)))
+	if rec.Code != 204 || got.Index != 1 || got.Y != 4.5 {
+		t.Errorf("unexpected edit %d: %+v", rec.Code, got)
+	}
+}
+
+func TestReport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "chartjs-report")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c := Chart{Type: Line, Label: "cpu"}
+	c.AddDataset(Dataset{Data: xy{x: []float64{1}, y: []float64{1}}})
+	r := Report{Title: "weekly", Pages: []ReportPage{{Title: "hosts", Charts: []Chart{c}}, {Title: "disks", Charts: []Chart{c, c}}}}
+	if err := r.Save(dir, nil); err != nil {
+		t.Fatalf("error saving report: %+v", err)
+	}
+	index, err := ioutil.ReadFile(filepath.Join(dir, "index.html"))
+	if err != nil || !strings.Contains(string(index), `<a href="page-2.html">disks</a>`) {
+		t.Errorf("expected table of contents to link pages: %s", index)
+	}
+	page, err := ioutil.ReadFile(filepath.Join(dir, "page-2.html"))
+	if err != nil || !strings.Contains(string(page), `<a href="page-1.html">`) || strings.Count(string(page), "<canvas") != 2 {
+		t.Errorf("expected page with navigation and two charts: %s", page)
+	}
+}
+
+func TestLazy(t *testing.T) {
+	chart := Chart{Type: Line}
+	chart.AddDataset(Dataset{Data: xy{x: []float64{1}, y: []float64{1}}})
+	var buf bytes.Buffer
+	if err := SaveCharts(&buf, map[string]interface{}{"lazy": true}, chart, chart); err != nil {
+		t.Fatalf("error saving charts: %+v", err)
+	}
+	if strings.Count(buf.String(), "chartjsLazy(") != 3 {
+		t.Errorf("expected both charts to be built lazily")
+	}
+	buf.Reset()
+	if err := SaveCharts(&buf, nil, chart); err != nil {
+		t.Fatalf("error saving charts: %+v", err)
+	}
+	if strings.Contains(buf.String(), "chartjsLazy") {
+		t.Errorf("expected charts to be built eagerly by default")
+	}
+}
+
+func TestDataURL(t *testing.T) {
+	chart := Chart{Type: Line, DataURL: "/data.json"}
+	chart.AddDataset(Dataset{Label: "big", Data: xy{x: []float64{1, 2}, y: []float64{3, 4}}})
+
+	var buf bytes.Buffer
+	if err := chart.SaveHTML(&buf, RenderOptions{}); err != nil {
+		t.Fatalf("error saving chart: %+v", err)
+	}
+	if strings.Contains(buf.String(), `"big"`) || !strings.Contains(buf.String(), `chartjsWorkerData("/data.json"`) {
+		t.Errorf("expected data to be fetched instead of embedded")
+	}
+
+	rec := httptest.NewRecorder()
+	DataHandler(&chart).ServeHTTP(rec, httptest.NewRequest("GET", "/data.json", nil))
+	if !strings.Contains(rec.Body.String(), `"label":"big"`) {
+		t.Errorf("expected data in handler response: %s", rec.Body)
+	}
+
+	// the page and the data are prepared from the full chart.
+	chart.AutoCreateAxes = true
+	chart.Data.Datasets[0].YAxisID = "right"
+	chart.Use(func(c *Chart) error {
+		c.Data.Datasets[0].Label = "huge"
+		return nil
+	})
+	if err := chart.SaveHTML(&buf, RenderOptions{}); err != nil {
+		t.Fatalf("error saving chart: %+v", err)
+	}
+	if !strings.Contains(buf.String(), `"right":{`) {
+		t.Errorf("expected the axes of the data in the page: %s", buf.String())
+	}
+	rec = httptest.NewRecorder()
+	DataHandler(&chart).ServeHTTP(rec, httptest.NewRequest("GET", "/data.json", nil))
+	if !strings.Contains(rec.Body.String(), `"label":"huge"`) {
+		t.Errorf("expected middlewares applied to the data: %s", rec.Body)
+	}
+}
+
+func TestRequiredPlugins(t *testing.T) {
+	chart := Chart{Type: Line, Requires: []string{"zoom"}}
+	chart.Options.Plugins = map[string]map[string]string{"datalabels": {"color": "red"}}
+	chart.AddXAxis(Axis{Type: Time})
+
+	got := strings.Join(chart.RequiredPlugins(), ",")
+	if got != "hammerjs,zoom,datalabels,date-adapter" {
+		t.Errorf("unexpected plugins: %s", got)
+	}
+
+	var buf bytes.Buffer
+	if err := chart.SaveHTML(&buf, RenderOptions{}); err != nil {
+		t.Fatalf("error saving chart: %+v", err)
+	}
+	if !strings.Contains(buf.String(), Plugins["zoom"].Src) || !strings.Contains(buf.String(), Plugins["hammerjs"].Src) {
+		t.Errorf("expected plugin scripts in output")
+	}
+
+	// options of built-in plugins don't load scripts.
+	builtin := Chart{Type: Line}
+	builtin.Options.Plugins = map[string]map[string]string{"legend": {"display": "false"}}
+	if got := builtin.RequiredPlugins(); len(got) != 0 {
+		t.Errorf("unexpected plugins for built-in options: %v", got)
+	}
+	if err := builtin.SaveHTML(&buf, RenderOptions{}); err != nil {
+		t.Errorf("error saving chart with built-in plugin options: %+v", err)
+	}
+
+	RegisterPlugin("test-plugin", Plugin{Src: "https://example.com/test.js"}, "hammerjs")
+	defer func() {
+		customMu.Lock()
+		delete(Plugins, "test-plugin")
+		delete(pluginDeps, "test-plugin")
+		customMu.Unlock()
+	}()
+	builtin.Requires = []string{"test-plugin"}
+	if got := strings.Join(builtin.RequiredPlugins(), ","); got != "hammerjs,test-plugin" {
+		t.Errorf("unexpected plugins: %s", got)
+	}
+
+	chart.Requires = append(chart.Requires, "nope")
+	if err := chart.SaveHTML(&buf, RenderOptions{}); err == nil || !strings.Contains(err.Error(), `"nope"`) {
+		t.Errorf("expected error for unknown plugin, got %v", err)
+	}
+}
+
+func TestErrorReport(t *testing.T) {
+	chart := Chart{Type: Line}
+	chart.AddDataset(Dataset{Data: xy{x: []float64{1}, y: []float64{1}}})
+	var buf bytes.Buffer
+	if err := SaveCharts(&buf, map[string]interface{}{"errorURL": "/errors"}, chart); err != nil {
+		t.Fatalf("error saving chart: %+v", err)
+	}
+	if !strings.Contains(buf.String(), `chartjsSelfCheck("/errors",`) {
+		t.Errorf("expected chart construction to be checked")
+	}
+
+	var got ConfigError
+	h := ErrorReportHandler(func(e ConfigError) { got = e })
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("POST", "/errors", strings.NewReader(`{"chart":"c","path":"options.scales.x.type","message":"unknown"}`)))
+	if rec.Code != 204 || got.Path != "options.scales.x.type" {
+		t.Errorf("unexpected report %d: %+v", rec.Code, got)
+	}
+}
+
+func TestThumbnail(t *testing.T) {
+	var xys xy
+	for i := 0; i < 10000; i++ {
+		xys.x = append(xys.x, float64(i))
+		xys.y = append(xys.y, math.Sin(float64(i)/100))
+	}
+	chart := Chart{Type: Line}
+	chart.AddDataset(Dataset{Data: xys, BorderColor: &types.RGBA{R: 255, A: 255}})
+
+	var buf bytes.Buffer
+	if err := chart.Thumbnail(&buf, 120, 60); err != nil {
+		t.Fatalf("error rendering thumbnail: %+v", err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("error decoding thumbnail: %+v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 120 || b.Dy() != 60 {
+		t.Errorf("unexpected size %v", b)
+	}
+
+	buf.Reset()
+	if err := chart.ThumbnailSVG(&buf, 120, 60); err != nil {
+		t.Fatalf("error rendering thumbnail: %+v", err)
+	}
+	if n := strings.Count(buf.String(), ","); n > 2*120+2 {
+		t.Errorf("expected decimated polyline, got %d points", n)
+	}
+	if err := chart.Thumbnail(&buf, 0, 60); err == nil {
+		t.Errorf("expected error for empty size")
+	}
+
+	inf := Chart{Type: Line}
+	inf.AddDataset(Dataset{Data: Floats([]float64{1, math.Inf(1), math.Inf(-1), 2})})
+	if err := inf.Thumbnail(&buf, 120, 60); err != nil {
+		t.Errorf("error rendering infinite points: %+v", err)
+	}
+
+	// points are drawn as dots, none dropped by decimation.
+	var sx, sy []float64
+	for i := 0; i < 1000; i++ {
+		sx = append(sx, float64((i*7919)%1000))
+		sy = append(sy, float64(i))
+	}
+	scatter := Chart{Type: Scatter}
+	scatter.AddDataset(Dataset{Data: xy{x: sx, y: sy}})
+	buf.Reset()
+	if err := scatter.ThumbnailSVG(&buf, 120, 60); err != nil {
+		t.Fatal(err)
+	}
+	if s := buf.String(); strings.Contains(s, "<polyline") || strings.Count(s, "<circle") != 1000 {
+		t.Errorf("expected a dot per point, got %d", strings.Count(s, "<circle"))
+	}
+
+	var raw, redacted bytes.Buffer
+	if err := chart.ThumbnailSVG(&raw, 120, 60); err != nil {
+		t.Fatal(err)
+	}
+	chart.Redactor = func(label string, v float64) (string, float64) { return label, 0 }
+	if err := chart.ThumbnailSVG(&redacted, 120, 60); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(raw.Bytes(), redacted.Bytes()) {
+		t.Error("expected the thumbnail of a redacted chart to be redacted")
+	}
+}
+
+func TestOGImage(t *testing.T) {
+	chart := &Chart{Type: Line}
+	chart.AddDataset(Dataset{Data: xy{x: []float64{1, 2}, y: []float64{1, 2}}})
+	h := OGImageHandler(func(id string) (*Chart, error) {
+		if id != "cpu" {
+			return nil, nil
+		}
+		return chart, nil
+	}, time.Hour)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/charts/cpu/og.png", nil))
+	if rec.Code != 200 || rec.Header().Get("Content-Type") != "image/png" || rec.Header().Get("Cache-Control") != "public, max-age=3600" {
+		t.Fatalf("unexpected response %d: %v", rec.Code, rec.Header())
+	}
+
+	req := httptest.NewRequest("GET", "/charts/cpu/og.png", nil)
+	req.Header.Set("If-None-Match", rec.Header().Get("ETag"))
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != 304 {
+		t.Errorf("expected 304 for matching etag, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/charts/mem/og.png", nil))
+	if rec.Code != 404 {
+		t.Errorf("expected 404 for unknown chart, got %d", rec.Code)
+	}
+
+	// the etag is the same after a restart, which changes the tag of JSFunc.
+	chart.Options.OnClick = "function() {}"
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/charts/cpu/og.png", nil))
+	etag := rec.Header().Get("ETag")
+	defer func(tag string) { jsTag = tag }(jsTag)
+	jsTag = newJSTag()
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/charts/cpu/og.png", nil))
+	if got := rec.Header().Get("ETag"); got != etag {
+		t.Errorf("expected a stable etag, got %s and %s", etag, got)
+	}
+}
+
+func TestStream(t *testing.T) {
+	type event struct {
+		host string
+		at   time.Time
+		load float64
+	}
+	start := time.Unix(0, 0)
+	events := make(chan interface{})
+	go func() {
+		for i := 0; i < 10; i++ {
+			events <- event{"a", start.Add(time.Duration(i) * time.Second), float64(i)}
+			events <- event{"b", start.Add(time.Duration(i) * time.Second), float64(-i)}
+		}
+		close(events)
+	}()
+
+	s := NewStream(5*time.Second, 3)
+	err := s.Consume(context.Background(), events, func(msg interface{}) (time.Time, string, float64) {
+		e := msg.(event)
+		return e.at, e.host, e.load
+	})
+	if err != nil {
+		t.Fatalf("error consuming: %+v", err)
+	}
+	ds := s.Datasets()
+	if len(ds) != 2 || ds[0].Label != "a" || ds[1].Label != "b" {
+		t.Fatalf("unexpected datasets: %+v", ds)
+	}
+	v := ds[0].Data.(Values)
+	if len(v.Ys()) != 3 || v.Ys()[0] != 7 || v.Xs()[2] != 9000 {
+		t.Errorf("unexpected rolling values: %v %v", v.Xs(), v.Ys())
+	}
+}
+
+func TestCron(t *testing.T) {
+	tests := []struct {
+		expr, from, next string
+	}{
+		{"30 2 * * *", "2024-03-08 10:00", "2024-03-09 02:30"},
+		{"*/15 * * * *", "2024-03-08 10:07", "2024-03-08 10:15"},
+		{"0 9 * * 1-5", "2024-03-08 10:00", "2024-03-11 09:00"},
+		{"0 0 1 * *", "2024-12-15 00:00", "2025-01-01 00:00"},
+		{"0 0 * * 7", "2024-03-08 00:00", "2024-03-10 00:00"},
+	}
+	for _, tt := range tests {
+		c, err := ParseCron(tt.expr)
+		if err != nil {
+			t.Fatalf("error parsing %q: %v", tt.expr, err)
+		}
+		from, _ := time.Parse("2006-01-02 15:04", tt.from)
+		if got := c.Next(from).Format("2006-01-02 15:04"); got != tt.next {
+			t.Errorf("%q after %s: got %s, want %s", tt.expr, tt.from, got, tt.next)
+		}
+	}
+	for _, bad := range []string{"* * * *", "60 * * * *", "*/0 * * * *", "a * * * *"} {
+		if _, err := ParseCron(bad); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestScheduler(t *testing.T) {
+	dir, err := os.MkdirTemp("", "chartjs-snapshots")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s := Scheduler{Publisher: DirPublisher(dir), Width: 100, Height: 50, Snapshots: []Snapshot{{
+		Name: "cpu", PNG: true, HTML: true,
+		Chart: func() (*Chart, error) {
+			c := &Chart{Type: Line}
+			c.AddDataset(Dataset{Data: xy{x: []float64{1, 2}, y: []float64{1, 2}}})
+			return c, nil
+		},
+	}}}
+	if err := s.RunOnce(context.Background(), time.Date(2024, 3, 8, 2, 30, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("error running snapshots: %+v", err)
+	}
+	for _, name := range []string{"cpu-20240308T0230.png", "cpu-20240308T0230.html"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("expected %s to be written: %v", name, err)
+		}
+	}
+
+	reg := NewRegistry()
+	s.Snapshots = []Snapshot{{Name: "gone", PNG: true, Chart: func() (*Chart, error) { return reg.Lookup("gone") }}}
+	if err := s.RunOnce(context.Background(), time.Now()); err == nil || !strings.Contains(err.Error(), `"gone"`) {
+		t.Errorf("expected an error naming the snapshot without a chart, got %v", err)
+	}
+
+	for _, key := range []string{"../escape", "a/../../escape", "/etc/escape", ".."} {
+		if err := DirPublisher(dir).Put(context.Background(), key, "text/plain", strings.NewReader("x")); err == nil {
+			t.Errorf("expected error for key %q", key)
+		}
+	}
+}
+
+type memPublisher map[string]string
+
+func (m memPublisher) Put(ctx context.Context, key, contentType string, r io.Reader) error {
+	b, err := io.ReadAll(r)
+	m[key] = contentType + " " + string(b)
+	return err
+}
+
+func TestPublish(t *testing.T) {
+	c := Chart{Type: Line, Label: "cpu"}
+	c.AddDataset(Dataset{Data: xy{x: []float64{1, 2}, y: []float64{1, 2}}})
+	m := memPublisher{}
+	ctx := context.Background()
+	if err := PublishJSON(ctx, m, "cpu.json", c); err != nil {
+		t.Fatal(err)
+	}
+	if err := PublishReport(ctx, m, "weekly/", Report{Pages: []ReportPage{{Charts: []Chart{c}}}}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(m["cpu.json"], `application/json {"type":"line"`) || m["weekly/index.html"] == "" || m["weekly/page-1.html"] == "" {
+		t.Errorf("unexpected artifacts: %v", m)
+	}
+	if err := PublishReport(ctx, m, "", Report{Pages: []ReportPage{{Charts: []Chart{c}}}}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if m["index.html"] == "" || m["page-1.html"] == "" {
+		t.Errorf("expected the report at the top level: %v", m)
+	}
+
+	var got *http.Request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+	}))
+	defer srv.Close()
+	p := HTTPPublisher{BaseURL: srv.URL + "/bucket/", Header: http.Header{"Authorization": {"Bearer x"}}}
+	if err := PublishPNG(ctx, p, "cpu.png", c, 40, 20); err != nil {
+		t.Fatal(err)
+	}
+	if got.Method != "PUT" || got.URL.Path != "/bucket/cpu.png" || got.Header.Get("Content-Type") != "image/png" || got.Header.Get("Authorization") != "Bearer x" {
+		t.Errorf("unexpected request: %+v", got)
+	}
+	if err := PublishPNG(ctx, p, "weekly/cpu 50%?#1.png", c, 40, 20); err != nil {
+		t.Fatal(err)
+	}
+	if got.URL.Path != "/bucket/weekly/cpu 50%?#1.png" || got.URL.RawQuery != "" {
+		t.Errorf("expected the key to be escaped, got %s", got.URL)
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	reg := NewRegistry()
+	c := &Chart{Type: Line, Label: "cpu"}
+	c.AddDataset(Dataset{Data: xy{x: []float64{1}, y: []float64{1}}})
+	if err := reg.Register("cpu", c); err != nil {
+		t.Fatal(err)
+	}
+	if err := reg.RegisterFunc("mem", func() (*Chart, error) { return c, nil }); err != nil {
+		t.Fatal(err)
+	}
+	if err := reg.Register("cpu", c); err == nil {
+		t.Errorf("expected error for duplicate id")
+	}
+	if got := strings.Join(reg.IDs(), ","); got != "cpu,mem" {
+		t.Errorf("unexpected ids: %s", got)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/charts/", http.StripPrefix("/charts", reg))
+	for path, want := range map[string]int{"/charts/": 200, "/charts/cpu": 200, "/charts/cpu/og.png": 200, "/charts/disk": 404} {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		if rec.Code != want {
+			t.Errorf("%s: got %d, want %d", path, rec.Code, want)
+		}
+		if path == "/charts/" && !strings.Contains(rec.Body.String(), `<a href="mem">mem</a>`) {
+			t.Errorf("expected list of charts: %s", rec.Body)
+		}
+	}
+
+	mux.Handle("/charts", http.StripPrefix("/charts", reg))
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/charts", nil))
+	if rec.Code != http.StatusMovedPermanently || rec.Header().Get("Location") != "/charts/" {
+		t.Errorf("expected redirect to the list, got %d %v", rec.Code, rec.Header())
+	}
+
+	bad := &Chart{Type: Line, Requires: []string{"nope"}}
+	if err := reg.Register("bad", bad); err != nil {
+		t.Fatal(err)
+	}
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/charts/bad", nil))
+	if rec.Code != 500 || strings.Contains(rec.Body.String(), "<html") {
+		t.Errorf("expected only the error, got %d: %s", rec.Code, rec.Body)
+	}
+}
+
+func TestColorMapper(t *testing.T) {
+	m := NewColorMapper(nil)
+	if m.Color("us-east-1") != NewColorMapper(nil).Color("us-east-1") {
+		t.Errorf("expected stable colors")
+	}
+	pinned := types.RGBA{R: 1, A: 255}
+	m.Set("eu-west-1", pinned)
+
+	c := Chart{Type: Line}
+	c.AddDataset(Dataset{Label: "us-east-1"})
+	c.AddDataset(Dataset{Label: "eu-west-1"})
+	c.AddDataset(Dataset{Label: "keep", BorderColor: &types.RGBA{B: 9}})
+	m.Apply(&c)
+	ds := c.Data.Datasets
+	if *ds[0].BorderColor != m.Color("us-east-1") || ds[0].BackgroundColor.A != 128 {
+		t.Errorf("unexpected colors: %v %v", ds[0].BorderColor, ds[0].BackgroundColor)
+	}
+	if *ds[1].BorderColor != pinned || ds[2].BorderColor.B != 9 {
+		t.Errorf("expected pinned and existing colors to be used")
+	}
+}
+
+func TestDefaults(t *testing.T) {
+	chart := Chart{Type: Line}
+	var buf bytes.Buffer
+	if err := chart.SaveHTML(&buf, RenderOptions{}); err != nil {
+		t.Fatalf("error saving chart: %+v", err)
+	}
+	if !strings.Contains(buf.String(), "Chart.defaults.global.animation.duration = 0;") {
+		t.Errorf("expected animation to be disabled by default")
+	}
+
+	buf.Reset()
+	d := GlobalDefaults{FontFamily: "Inter", FontSize: 14, FontColor: &types.RGBA{A: 255}, AnimationDuration: 300}
+	if err := chart.SaveHTML(&buf, RenderOptions{Defaults: &d}); err != nil {
+		t.Fatalf("error saving chart: %+v", err)
+	}
+	for _, want := range []string{
+		`Chart.defaults.global.defaultFontFamily = "Inter";`,
+		`Chart.defaults.global.defaultFontSize = 14;`,
+		`Chart.defaults.global.defaultFontColor = "rgba(0, 0, 0, 1.000)";`,
+		`Chart.defaults.global.animation.duration = 300;`,
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("expected %s in output", want)
+		}
+	}
+
+	buf.Reset()
+	chart.TargetVersion = V4
+	if err := chart.SaveHTML(&buf, RenderOptions{Defaults: &d}); err != nil {
+		t.Fatalf("error saving chart: %+v", err)
+	}
+	for _, want := range []string{
+		`Chart.defaults.datasets.line.cubicInterpolationMode = "monotone";`,
+		`Chart.defaults.font.family = "Inter";`,
+		`Chart.defaults.font.size = 14;`,
+		`Chart.defaults.color = "rgba(0, 0, 0, 1.000)";`,
+		`Chart.defaults.animation.duration = 300;`,
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("expected %s in output", want)
+		}
+	}
+	if strings.Contains(buf.String(), "Chart.defaults.global") {
+		t.Errorf("expected no chart.js 2 defaults for chart.js 4")
+	}
+	if err := SaveCharts(io.Discard, nil, Chart{Type: Line}, chart); err == nil {
+		t.Errorf("expected an error for charts targeting chart.js 2 and 4")
+	}
+}
+
+func TestWebFonts(t *testing.T) {
+	chart := Chart{Type: Line}
+	fonts := []WebFont{
+		{Family: "Brand", URL: "https://example.com/brand.woff", Weight: "700"},
+		{Family: "Embedded", Data: []byte("font"), Format: "truetype"},
+	}
+	var buf bytes.Buffer
+	if err := chart.SaveHTML(&buf, RenderOptions{Fonts: fonts}); err != nil {
+		t.Fatalf("error saving chart: %+v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		`@font-face { font-family: "Brand"; src: url("https://example.com/brand.woff") format("woff"); font-weight: 700; }`,
+		`src: url("data:font/ttf;base64,Zm9udA==") format("truetype");`,
+		`Chart.defaults.global.defaultFontFamily = "Brand";`,
+		`document.fonts.ready`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %s in output", want)
+		}
+	}
+
+	fonts = []WebFont{{Family: `x"}`, URL: "a.woff"}}
+	if err := chart.SaveHTML(&buf, RenderOptions{Fonts: fonts}); err == nil {
+		t.Errorf("expected error for bad family")
+	}
+	for _, f := range []WebFont{
+		{Family: "x", URL: "a.woff", Weight: "bold; } body { display: none"},
+		{Family: "x", URL: "a.woff", Style: "italic;"},
+	} {
+		if err := chart.SaveHTML(&buf, RenderOptions{Fonts: []WebFont{f}}); err == nil {
+			t.Errorf("expected error for %+v", f)
+		}
+	}
+	if _, err := (WebFont{Family: "x", URL: "a.woff", Weight: "700", Style: "oblique"}).css(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestWatermark(t *testing.T) {
+	chart := Chart{Type: Line, Watermark: &Watermark{Text: "ACME", Position: WatermarkBottomRight}}
+	b, err := chart.MarshalTagged()
+	if err != nil {
+		t.Fatalf("error marshaling chart: %+v", err)
+	}
+	js := string(inlineJS(b))
+	if !strings.Contains(js, `"plugins":[{afterDraw: function(chart) {`) ||
+		!strings.Contains(js, `"text":"ACME","position":"bottom-right","opacity":0.2,"font":"bold 24px sans-serif"`) {
+		t.Errorf("expected watermark plugin: %s", js)
+	}
+
+	chart.Watermark = &Watermark{}
+	if _, err := json.Marshal(chart); err == nil {
+		t.Errorf("expected error for empty watermark")
+	}
+}
+
+func TestLabelWrapping(t *testing.T) {
+	if got := strings.Join(WrapLabel("a very long category name", 10), "|"); got != "a very|long|category|name" {
+		t.Errorf("unexpected wrapping: %s", got)
+	}
+	if got := strings.Join(WrapLabel("supercalifragilistic", 8), "|"); got != "supercal|ifragili|stic" {
+		t.Errorf("unexpected wrapping of long word: %s", got)
+	}
+	if got := TruncateLabel("kubernetes-node-1", 8); got != "kuberne…" {
+		t.Errorf("unexpected truncation: %s", got)
+	}
+
+	chart := Chart{Type: Bar, Data: Data{Labels: []string{"short", "a much longer label"}}}
+	rot := 0
+	chart.AddXAxis(Axis{Tick: &Tick{MaxRotation: &rot}})
+	chart.WrapLabels(8)
+	b, err := json.Marshal(chart)
+	if err != nil {
+		t.Fatalf("error marshaling chart: %+v", err)
+	}
+	if !strings.Contains(string(b), `"labels":[["short"],["a much","longer","label"]]`) || !strings.Contains(string(b), `"maxRotation":0`) {
+		t.Errorf("unexpected labels: %s", b)
+	}
+
+	chart.Data.LabelLines = nil
+	chart.TruncateLabels(6)
+	b, _ = chart.MarshalTagged()
+	if !strings.Contains(string(b), `"labels":["short","a muc…"],"fullLabels":["short","a much longer label"]`) ||
+		!strings.Contains(string(inlineJS(b)), `"title":function(items, data)`) {
+		t.Errorf("unexpected truncated labels: %s", b)
+	}
+}
+
+func TestAbbreviateLabels(t *testing.T) {
+	for _, tt := range []struct {
+		label string
+		mode  abbreviation
+		want  string
+	}{
+		{"kubernetes-node-1", MiddleEllipsis, "kube…de-1"},
+		{"Customer Relationship Management", Initialism, "CRM"},
+		{"short", Initialism, "short"},
+		{"supercalifragilistic", Initialism, "supe…stic"},
+	} {
+		if got := AbbreviateLabel(tt.label, 9, tt.mode); got != tt.want {
+			t.Errorf("AbbreviateLabel(%q): got %q, want %q", tt.label, got, tt.want)
+		}
+	}
+
+	chart := Chart{Type: Bar, Data: Data{Labels: []string{"Site Reliability Engineering", "Software Release Engineering"}}}
+	chart.AbbreviateLabels(5, Initialism)
+	if got := strings.Join(chart.Data.Labels, ","); got != "SRE,SRE2" {
+		t.Errorf("unexpected labels: %s", got)
+	}
+	if chart.Options.Tooltip.Callbacks.Title != FullLabelTitle || chart.Data.FullLabels[1] != "Software Release Engineering" {
+		t.Errorf("expected full labels in tooltips")
+	}
+
+	chart = Chart{Type: Bar, Data: Data{Labels: []string{"alpha-x-omega", "alpha-y-omega", "alp…ga2"}}}
+	chart.AbbreviateLabels(7, MiddleEllipsis)
+	seen := map[string]bool{}
+	for _, l := range chart.Data.Labels {
+		if len([]rune(l)) > 7 || seen[l] {
+			t.Errorf("expected distinct labels of at most 7 characters: %q", chart.Data.Labels)
+		}
+		seen[l] = true
+	}
+
+	chart = Chart{Type: Bar}
+	chart.AddDataset(Dataset{Data: FromMap(map[string]float64{"Site Reliability Engineering": 1, "Software Release Engineering": 2})})
+	chart.AbbreviateLabels(5, Initialism)
+	b, err := json.Marshal(chart)
+	if err != nil {
+		t.Fatalf("error marshaling shortened labels of LabeledValues: %+v", err)
+	}
+	if !strings.Contains(string(b), `"labels":["SRE","SRE2"]`) {
+		t.Errorf("expected the short labels, got %s", b)
+	}
+}
+
+func TestMultiLineText(t *testing.T) {
+	chart := Chart{Type: Line, Options: Options{Option: Option{Title: &Title{TextLines: []string{"latency", "p99"}}}}}
+	chart.Options.Scales = map[string]Axis{"y": {
+		Type:       Linear,
+		ScaleLabel: &ScaleLabel{LabelLines: []string{"ms", "(log)"}},
+		Title:      AxisTitle{Display: true, TextLines: []string{"a", "b"}},
+		Labels:     [][]string{{"Jan", "2024"}},
+	}}
+	buf, err := json.Marshal(chart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := string(buf)
+	for _, want := range []string{
+		`"text":["latency","p99"]`,
+		`"labelString":["ms","(log)"]`,
+		`"text":["a","b"]`,
+		`"labels":[["Jan","2024"]]`,
+	} {
+		if !strings.Contains(s, want) {
+			t.Errorf("expected %s in %s", want, s)
+		}
+	}
+}
+
+func TestValidateAxes(t *testing.T) {
+	chart := Chart{Type: Line}
+	chart.AddDataset(Dataset{Label: "a", Data: xy{x: []float64{1}, y: []float64{2}}, YAxisID: "y1"})
+	chart.AddDataset(Dataset{Label: "b", Data: xy{x: []float64{1}, y: []float64{2}}, YAxisID: "y2"})
+	if err := chart.ValidateAxes(); err == nil {
+		t.Fatal("expected error for unknown axis")
+	}
+	if _, err := json.Marshal(chart); err == nil {
+		t.Fatal("expected marshal to fail for unknown axis")
+	}
+	implicit := Chart{Type: Line}
+	implicit.AddDataset(Dataset{Data: xy{x: []float64{1}, y: []float64{2}}, XAxisID: "x-axis-0", YAxisID: "y-axis-0"})
+	if _, err := json.Marshal(implicit); err != nil {
+		t.Errorf("expected the chart.js 2 default axes to be accepted: %v", err)
+	}
+
+	chart.AutoCreateAxes = true
+	buf, err := json.Marshal(chart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chart.Options.Scales != nil {
+		t.Error("marshal should not modify the chart")
+	}
+	s := string(buf)
+	for _, want := range []string{`"y1":{"type":"linear","position":"left"`, `"y2":{"type":"linear","position":"right"`} {
+		if !strings.Contains(s, want) {
+			t.Errorf("expected %s in %s", want, s)
+		}
+	}
+}
+
+func TestEqual(t *testing.T) {
+	a := Chart{Type: Line, Data: Data{Labels: []string{}}}
+	a.AddDataset(Dataset{Label: "a", Data: xy{x: []float64{1, 2}, y: []float64{3, 4}}, YFloatFormat: "%.2f"})
+	b := Chart{Type: Line}
+	b.AddDataset(Dataset{Label: "a", Data: xy{x: []float64{1, 2}, y: []float64{3, 4}}})
+	if ok, d := Equal(&a, &b); !ok {
+		t.Errorf("expected charts to be equal: %s", d)
+	}
+
+	b.Data.Datasets[0].Label = "b"
+	if ok, d := Equal(&a, &b); ok || d != `data.datasets[0].label: "a" != "b"` {
+		t.Errorf("unexpected diff: %s", d)
+	}
+}
+
+func TestDoughnut(t *testing.T) {
+	rotation, circumference := -90.0, 180.0
+	chart := Chart{Type: Doughnut, Data: Data{Labels: []string{"used", "free"}}}
+	chart.Options.Cutout = "75%"
+	chart.Options.Rotation = &rotation
+	chart.Options.Circumference = &circumference
+	chart.AddDataset(Dataset{Data: xy{x: []float64{30, 70}}})
+	buf, err := json.Marshal(chart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := string(buf)
+	for _, want := range []string{`"type":"doughnut"`, `"cutout":"75%"`, `"rotation":-90`, `"circumference":180`, `"data":[30.00,70.00]`} {
+		if !strings.Contains(s, want) {
+			t.Errorf("expected %s in %s", want, s)
+		}
+	}
+}
+
+func FuzzMarshalJSON(f *testing.F) {
+	f.Add("label", `"quoted" </script>`, "%.2f", 1.5, 2.5)
+	f.Add("", "", "%v", math.NaN(), math.Inf(1))
+	f.Add(" ", "\x00", `%.2f"`, -0.0, 1e300)
+	f.Fuzz(func(t *testing.T, label, title, format string, x, y float64) {
+		chart := Chart{Type: Line, Data: Data{Labels: []string{label}}}
+		chart.Options.Title = &Title{Text: title}
+		chart.AddDataset(Dataset{Label: label, Data: xy{x: []float64{x}, y: []float64{y}}, XFloatFormat: format})
+		chart.AddDataset(Dataset{Label: title, Data: xy{x: []float64{y}}, YFloatFormat: format})
+		buf, err := json.Marshal(chart)
+		if err != nil {
+			return
+		}
+		if !json.Valid(buf) {
+			t.Fatalf("invalid JSON: %s", buf)
+		}
+	})
+}
+
+func TestEscapeLabels(t *testing.T) {
+	evil := `"</script><script>alert(1)</script>`
+	chart := Chart{Type: Bar, Label: evil, Data: Data{Labels: []string{evil, "__chartjs_js__:alert(2)"}}}
+	chart.Options.Title = &Title{Text: evil}
+	chart.AddDataset(Dataset{Label: evil, Data: xy{x: []float64{1, 2}}})
+
+	var buf bytes.Buffer
+	if err := SaveCharts(&buf, nil, chart); err != nil {
+		t.Fatal(err)
+	}
+	s := buf.String()
+	if strings.Contains(s, "<script>alert(1)") {
+		t.Errorf("unescaped label in output: %s", s)
+	}
+	if !strings.Contains(s, `"__chartjs_js__:alert(2)"`) {
+		t.Errorf("labels should stay strings: %s", s)
+	}
+
+	chart.SanitizeLabels()
+	if want := `"alert(1)`; chart.Data.Labels[0] != want || chart.Options.Title.Text != want {
+		t.Errorf("unexpected sanitized label %q", chart.Data.Labels[0])
+	}
+	if got := SanitizeLabel("a\x00\n b<b>c</b>"); got != "a bc" {
+		t.Errorf("unexpected sanitized label %q", got)
+	}
+}
+
+func TestRadar(t *testing.T) {
+	chart := Chart{Type: Radar, Data: Data{Labels: []string{"speed", "power", "range"}}}
+	chart.AddDataset(Dataset{Label: "a", Data: xy{x: []float64{1, 2, 3}}})
+	min := 0.0
+	chart.AddRAxis(Axis{
+		AngleLines:   &AngleLines{Display: True, LineWidth: 2},
+		PointLabels:  &PointLabels{FontSize: 14},
+		SuggestedMin: &min,
+	})
+	buf, err := json.Marshal(chart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := string(buf)
+	for _, want := range []string{
+		`"type":"radar"`,
+		`"r":{"type":"radialLinear"`,
+		`"angleLines":{"display":true,"lineWidth":2}`,
+		`"pointLabels":{"fontSize":14}`,
+		`"suggestedMin":0`,
+	} {
+		if !strings.Contains(s, want) {
+			t.Errorf("expected %s in %s", want, s)
+		}
+	}
+}
+
+func TestMappedValues(t *testing.T) {
+	xs, ys := make([]float64, 1000), make([]float64, 1000)
+	for i := range xs {
+		xs[i], ys[i] = float64(i), math.Sin(float64(i)/10)
+	}
+	ys[500] = 10
+
+	path := filepath.Join(t.TempDir(), "series.bin")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteMappedValues(f, xy{x: xs, y: ys}); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	m, err := OpenMappedValues(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	if m.Len() != 1000 {
+		t.Fatalf("unexpected length %d", m.Len())
+	}
+	if x, y := m.At(500); x != 500 || y != 10 {
+		t.Errorf("unexpected point %v, %v", x, y)
+	}
+
+	r := m.Range(100, 199)
+	if got := r.Xs(); len(got) != 100 || got[0] != 100 || got[99] != 199 {
+		t.Errorf("unexpected range %v", got)
+	}
+
+	m.MaxPoints = 100
+	if got := m.Xs(); len(got) > 100 || len(got) != len(m.Ys()) {
+		t.Errorf("expected at most 100 points, got %d", len(got))
+	}
+	found := false
+	for _, y := range m.Ys() {
+		found = found || y == 10
+	}
+	if !found {
+		t.Error("decimation should keep the peak")
+	}
+}
+
+func TestPolarArea(t *testing.T) {
+	start := 45.0
+	chart := Chart{Type: PolarArea, Data: Data{Labels: []string{"a", "b"}}}
+	chart.Options.StartAngle = &start
+	chart.AddDataset(Dataset{
+		Data:             xy{x: []float64{1, 2}},
+		BackgroundColors: []types.RGBA{{R: 255, A: 255}, {B: 255, A: 255}},
+		BorderColor:      &types.RGBA{A: 255},
+	})
+	buf, err := json.Marshal(chart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := string(buf)
+	for _, want := range []string{
+		`"type":"polarArea"`,
+		`"startAngle":45`,
+		`"backgroundColor":["rgba(255, 0, 0, 1.000)","rgba(0, 0, 255, 1.000)"]`,
+		`"borderColor":"rgba(0, 0, 0, 1.000)"`,
+	} {
+		if !strings.Contains(s, want) {
+			t.Errorf("expected %s in %s", want, s)
+		}
+	}
+}
+
+func TestZoomCache(t *testing.T) {
+	xs, ys := make([]float64, 10000), make([]float64, 10000)
+	for i := range xs {
+		xs[i], ys[i] = float64(i), float64(i%100)
+	}
+	z, err := NewZoomCache(xy{x: xs, y: ys}, 100, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if z.Levels() != 4 {
+		t.Fatalf("unexpected number of levels %d", z.Levels())
+	}
+	for _, r := range [][2]float64{{0, 9999}, {0, 2000}, {5000, 5500}, {100, 150}} {
+		v := z.Range(r[0], r[1])
+		got := v.Xs()
+		if len(got) == 0 || len(got) > 100 || len(got) != len(v.Ys()) {
+			t.Errorf("range %v: unexpected number of points %d", r, len(got))
+			continue
+		}
+		if got[0] < r[0] || got[len(got)-1] > r[1] {
+			t.Errorf("range %v: points out of range %v..%v", r, got[0], got[len(got)-1])
+		}
+	}
+	if got := z.Range(100, 150).Xs(); len(got) != 51 {
+		t.Errorf("narrow ranges should return every point, got %d", len(got))
+	}
+
+	if _, err := NewZoomCache(Floats(ys), 100, 4); err == nil {
+		t.Error("expected error for a single series")
+	}
+	if _, err := NewZoomCache(XY(xs, ys[:10]), 100, 4); err == nil {
+		t.Error("expected error for mismatched lengths")
+	}
+	z, err = NewZoomCache(XY(xs, ys), 100, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := z.Range(0, 9999).Xs(); len(got) == 0 || len(got) > 100 {
+		t.Errorf("unexpected number of points %d", len(got))
+	}
+}
+
+func TestNewScatter(t *testing.T) {
+	if _, err := NewScatter([]float64{1}, nil); err == nil {
+		t.Error("expected error for mismatched lengths")
+	}
+	chart, err := NewScatter([]float64{1, 2}, []float64{3, 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf, err := json.Marshal(chart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := string(buf)
+	for _, want := range []string{`"type":"scatter"`, `"x":{"type":"linear","position":"bottom"`, `"data":[{"x":1.00,"y":3.00},{"x":2.00,"y":4.00}]`} {
+		if !strings.Contains(s, want) {
+			t.Errorf("expected %s in %s", want, s)
+		}
+	}
+}
+
+func TestLatencyHistograms(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	bounds := []float64{10, 20, 50}
+	snaps := []HistogramSnapshot{
+		{Time: t0, Bounds: bounds, Counts: []uint64{0, 0, 0, 0}},
+		{Time: t0.Add(time.Minute), Bounds: bounds, Counts: []uint64{50, 40, 10, 0}},
+		{Time: t0.Add(2 * time.Minute), Bounds: bounds, Counts: []uint64{50, 40, 10, 100}},
+	}
+	deltas, err := HistogramDeltas(snaps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deltas) != 2 || deltas[1].Counts[3] != 100 || deltas[1].Counts[0] != 0 {
+		t.Fatalf("unexpected deltas %v", deltas)
+	}
+	if q := deltas[0].Quantile(0.5); q != 10 {
+		t.Errorf("unexpected p50 %v", q)
+	}
+	if q := deltas[0].Quantile(0.7); q != 15 {
+		t.Errorf("unexpected p70 %v", q)
+	}
+	if q := deltas[1].Quantile(0.99); q != 50 {
+		t.Errorf("overflow should report the last bound, got %v", q)
+	}
+
+	chart, err := PercentileChart(deltas, 0.5, 0.99)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chart.Data.Datasets) != 2 || chart.Data.Datasets[1].Label != "p99" {
+		t.Errorf("unexpected datasets %v", chart.Data.Datasets)
+	}
+
+	heat, err := HeatmapChart(deltas, "15:04", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(heat.Data.Datasets) != 4 || heat.Data.Labels[1] != "00:02" {
+		t.Fatalf("unexpected heatmap %v", heat.Data)
+	}
+	if got := heat.Data.Datasets[3].BackgroundColors[1]; got != types.Viridis(1) {
+		t.Errorf("largest bucket should get the top color, got %v", got)
+	}
+	if _, err := json.Marshal(heat); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSLOBurnRate(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var ts []time.Time
+	var good, total []float64
+	for i := 0; i <= 12; i++ {
+		ts = append(ts, t0.Add(time.Duration(i)*10*time.Minute))
+		// 1000 requests every 10 minutes, 1% failing from the first hour on.
+		bad := 0.0
+		if i > 6 {
+			bad = float64(i-6) * 10
+		}
+		total = append(total, float64(i)*1000)
+		good = append(good, float64(i)*1000-bad)
+	}
+	slo := SLO{Objective: 0.999}
+	rates, err := slo.BurnRate(ts, good, total, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !math.IsNaN(rates[5]) || rates[6] != 0 || math.Abs(rates[12]-10) > 1e-9 {
+		t.Errorf("unexpected burn rates %v", rates)
+	}
+
+	chart, err := slo.Chart(ts, good, total)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chart.Data.Datasets) != 2 || len(chart.Options.Annotation.Annotations) != 4 {
+		t.Fatalf("unexpected chart %+v", chart)
+	}
+	if got := chart.RequiredPlugins(); !reflect.DeepEqual(got, []string{"annotation", "date-adapter"}) {
+		t.Errorf("unexpected plugins %v", got)
+	}
+	buf, err := json.Marshal(chart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(buf), `{"type":"box","yScaleID":"y","yMin":14.4,`) {
+		t.Errorf("expected threshold band in %s", buf)
+	}
+}
+
+type ohlc struct {
+	t          []time.Time
+	o, h, l, c []float64
+}
+
+func (v ohlc) Time() []time.Time { return v.t }
+func (v ohlc) Open() []float64   { return v.o }
+func (v ohlc) High() []float64   { return v.h }
+func (v ohlc) Low() []float64    { return v.l }
+func (v ohlc) Close() []float64  { return v.c }
+
+func TestCandlestick(t *testing.T) {
+	t0 := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	chart := Chart{Type: Candlestick}
+	chart.AddXAxis(Axis{Type: Time, Position: Bottom})
+	chart.AddDataset(Dataset{Label: "ACME", Data: ohlc{
+		t: []time.Time{t0}, o: []float64{10}, h: []float64{12.5}, l: []float64{9}, c: []float64{11},
+	}})
+	buf, err := json.Marshal(chart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := string(buf)
+	for _, want := range []string{`"type":"candlestick"`, `"data":[{"x":1704153600000,"o":10.00,"h":12.50,"l":9.00,"c":11.00}]`} {
+		if !strings.Contains(s, want) {
+			t.Errorf("expected %s in %s", want, s)
+		}
+	}
+
+	var page bytes.Buffer
+	if err := SaveCharts(&page, nil, chart); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(page.String(), Plugins["financial"].Src) {
+		t.Error("expected financial plugin script")
+	}
+}
+
+func TestStatusStrip(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	changes := []StateChange{
+		{Service: "api", Time: t0, State: "up"},
+		{Service: "db", Time: t0.Add(90 * time.Minute), State: "up"},
+		{Service: "api", Time: t0.Add(70 * time.Minute), State: "down"},
+		{Service: "api", Time: t0.Add(80 * time.Minute), State: "up"},
+	}
+	chart, err := StatusStrip{Bucket: time.Hour, Layout: "15:04"}.Chart(changes, t0, t0.Add(3*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(chart.Data.Labels, ","); got != "api,db" || len(chart.Data.Datasets) != 3 {
+		t.Fatalf("unexpected rows %s and %d buckets", got, len(chart.Data.Datasets))
+	}
+	up, down := DefaultStatusStates[0].Color, DefaultStatusStates[2].Color
+	for i, want := range [][]types.RGBA{{up, unknownStatus}, {down, up}, {up, up}} {
+		d := chart.Data.Datasets[i]
+		if !reflect.DeepEqual(d.BackgroundColors, want) {
+			t.Errorf("bucket %s: got %v, want %v", d.Label, d.BackgroundColors, want)
+		}
+	}
+	if _, err := json.Marshal(chart); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBoxplot(t *testing.T) {
+	b := Summarize([]float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 100, math.NaN()})
+	want := BoxplotValues{Min: 1, Q1: 3.25, Median: 5.5, Q3: 7.75, Max: 9, Outliers: []float64{100}}
+	if !reflect.DeepEqual(b, want) {
+		t.Errorf("got %+v, want %+v", b, want)
+	}
+
+	chart := Chart{Type: Boxplot, Data: Data{Labels: []string{"a"}}}
+	chart.AddDataset(Dataset{Label: "latency", Data: []BoxplotValues{b}})
+	chart.AddDataset(Dataset{Label: "raw", Type: Violin, Data: [][]float64{{1, 2, 3}}})
+	buf, err := json.Marshal(chart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := string(buf)
+	for _, want := range []string{
+		`"type":"boxplot"`,
+		`"data":[{"min":1,"q1":3.25,"median":5.5,"q3":7.75,"max":9,"outliers":[100]}]`,
+		`"data":[[1,2,3]]`,
+	} {
+		if !strings.Contains(s, want) {
+			t.Errorf("expected %s in %s", want, s)
+		}
+	}
+	if got := chart.RequiredPlugins(); !reflect.DeepEqual(got, []string{"boxplot"}) {
+		t.Errorf("unexpected plugins %v", got)
+	}
+}
+
+func TestHistogram2D(t *testing.T) {
+	xs := []float64{0, 0.1, 0.2, 0.9, 1, math.NaN()}
+	ys := []float64{0, 0.1, 0.2, 0.9, 1, 0}
+	h := Histogram2D{XBins: 2, YBins: 2}
+	counts, xedges, _, err := h.Counts(xs, ys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(counts, [][]float64{{3, 0}, {0, 2}}) || !reflect.DeepEqual(xedges, []float64{0, 0.5, 1}) {
+		t.Errorf("unexpected counts %v, edges %v", counts, xedges)
+	}
+	chart, err := h.Chart(xs, ys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(chart.Data.Labels, ","); got != "0.00,0.50" || len(chart.Data.Datasets) != 2 {
+		t.Errorf("unexpected heatmap %v", chart.Data)
+	}
+	if got := chart.Data.Datasets[0].BackgroundColors[0]; got != types.Viridis(1) {
+		t.Errorf("fullest cell should get the top color, got %v", got)
+	}
+
+	scatter, err := DensityScatter(make([]float64, 1000), make([]float64, 1000), types.RGBA{B: 255, A: 255})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a := scatter.Data.Datasets[0].PointBackgroundColor.A; a == 0 || a > 30 {
+		t.Errorf("expected translucent points, got alpha %d", a)
+	}
+}
+
+func TestKDE2D(t *testing.T) {
+	xs := []float64{0, 0.1, -0.1, 0, 0, 5}
+	ys := []float64{0, 0, 0, 0.1, -0.1, 5}
+	k := KDE2D{GridSize: 21, Levels: []float64{0.5}, Color: types.RGBA{B: 255, A: 255}}
+	gx, gy, d, err := k.Density(xs, ys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gx) != 21 || len(gy) != 21 || len(d) != 21 {
+		t.Fatalf("unexpected grid %d x %d", len(gx), len(gy))
+	}
+	if d[0][0] >= d[5][5] {
+		t.Error("density should be higher near the cluster than at the edge")
+	}
+
+	chart, err := NewScatter(xs, ys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := chart.AddDensityBands(k, xs, ys); err != nil {
+		t.Fatal(err)
+	}
+	if len(chart.Data.Datasets) != 3 {
+		t.Fatalf("expected scatter plus 2 band edges, got %d", len(chart.Data.Datasets))
+	}
+	buf, err := json.Marshal(chart.Data.Datasets[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"fill":"-1"`, `"order":1`, `"showLine":true`, `null`} {
+		if !strings.Contains(string(buf), want) {
+			t.Errorf("expected %s in %s", want, buf)
+		}
+	}
+}
+
+func TestMatrix(t *testing.T) {
+	chart := NewMatrix([][]float64{{0, 1}, {2, math.NaN()}}, ColorScale{})
+	d := chart.Data.Datasets[0]
+	if d.BackgroundColors[0] != types.Viridis(0) || d.BackgroundColors[2] != types.Viridis(1) || d.BackgroundColors[3].A != 0 {
+		t.Errorf("unexpected colors %v", d.BackgroundColors)
+	}
+	buf, err := chart.MarshalTagged()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := string(buf)
+	for _, want := range []string{
+		`"type":"matrix"`,
+		`"data":[{"x":0.00,"y":0.00,"v":0},{"x":1.00,"y":0.00,"v":1},{"x":0.00,"y":1.00,"v":2},{"x":1.00,"y":1.00,"v":null}]`,
+		`"width":"` + jsTag + `function(ctx) {`,
+	} {
+		if !strings.Contains(s, want) {
+			t.Errorf("expected %s in %s", want, s)
+		}
+	}
+	if got := chart.RequiredPlugins(); !reflect.DeepEqual(got, []string{"matrix"}) {
+		t.Errorf("unexpected plugins %v", got)
+	}
+}
+
+func TestLogHistogram(t *testing.T) {
+	chart, err := LogHistogram([]float64{1, 2, 5, 10, 99, 1000, 0, -1}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(chart.Data.Labels, ","); got != "1,3.16,10,31.6,100,316,1e+03" {
+		t.Errorf("unexpected bins %s", got)
+	}
+	if got := chart.Data.Datasets[0].Data.(Values).Xs(); !reflect.DeepEqual(got, []float64{2, 1, 1, 1, 0, 0, 1}) {
+		t.Errorf("unexpected counts %v", got)
+	}
+}
+
+func TestECDF(t *testing.T) {
+	chart, err := ECDF([]float64{3, 1, math.NaN(), 2, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := chart.Data.Datasets[0].Data.(Values)
+	if !reflect.DeepEqual(v.Xs(), []float64{1, 1, 2, 2, 2, 2, 3, 3}) || !reflect.DeepEqual(v.Ys(), []float64{0, 0.25, 0.25, 0.5, 0.5, 0.75, 0.75, 1}) {
+		t.Errorf("unexpected ECDF %v %v", v.Xs(), v.Ys())
+	}
+}
+
+func TestQQPlot(t *testing.T) {
+	chart, err := QQPlot([]float64{-1, 0, 1, math.NaN()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := chart.Data.Datasets[0].Data.(Values)
+	if xs := v.Xs(); math.Abs(xs[0]+xs[2]) > 1e-12 || xs[1] != 0 {
+		t.Errorf("theoretical quantiles should be symmetric: %v", xs)
+	}
+	if len(chart.Data.Datasets) != 2 || chart.Options.Scales["y"].Title.Text != "sample quantiles" {
+		t.Errorf("unexpected chart %+v", chart)
+	}
+	if _, err := QQPlot([]float64{1}); err == nil {
+		t.Error("expected error for a single value")
+	}
+}
+
+func TestResiduals(t *testing.T) {
+	chart, err := Residuals([]float64{1, 2}, []float64{1.5, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := chart.Data.Datasets[0].Data.(Values).Ys(); !reflect.DeepEqual(got, []float64{0.5, -1}) {
+		t.Errorf("unexpected residuals %v", got)
+	}
+	if a := chart.Options.Annotation.Annotations[0]; a.Type != LineAnnotation || *a.Value != 0 {
+		t.Errorf("expected zero line, got %+v", a)
+	}
+}
+
+func TestTreemap(t *testing.T) {
+	chart := Chart{Type: Treemap}
+	chart.AddDataset(NewTreemapDataset("disk", []TreeNode{
+		{Label: "usr", Children: []TreeNode{{Label: "bin", Value: 3}, {Label: "lib", Value: 5}}},
+		{Label: "tmp", Value: 1},
+	}))
+	buf, err := json.Marshal(chart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := string(buf)
+	for _, want := range []string{
+		`"type":"treemap"`,
+		`"tree":[{"l0":"usr","l1":"bin","value":3},{"l0":"usr","l1":"lib","value":5},{"l0":"tmp","l1":"tmp","value":1}]`,
+		`"key":"value","groups":["l0","l1"]`,
+	} {
+		if !strings.Contains(s, want) {
+			t.Errorf("expected %s in %s", want, s)
+		}
+	}
+}
+
+func TestTargetVersion(t *testing.T) {
+	chart := Chart{Type: Line}
+	chart.Options.Title = &Title{Text: "latency", FontColor: &types.RGBA{A: 255}}
+	chart.Options.Tooltip = &Tooltip{Mode: "index"}
+	chart.AddXAxis(Axis{Type: Time, Position: Bottom, Title: AxisTitle{Display: true, Text: "time"}})
+	chart.AddYAxis(Axis{Type: Linear, Position: Left, GridLines: False, ScaleLabel: &ScaleLabel{LabelString: "ms", FontSize: 12}, Tick: &Tick{Min: 1, BeginAtZero: True}})
+	chart.AddDataset(Dataset{Data: xy{x: []float64{1}, y: []float64{2}}, SteppedLine: True})
+
+	chart.TargetVersion = V2
+	buf, err := json.Marshal(chart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := string(buf)
+	for _, want := range []string{
+		`"xAxes":[{"id":"x","position":"bottom","scaleLabel":{"display":true,"labelString":"time"},"type":"time"}]`,
+		`"yAxes":[{"gridLines":{"display":false},"id":"y","position":"left","scaleLabel":{"fontSize":12,"labelString":"ms"}`,
+		`"tooltips":{"mode":"index"}`,
+		`"data":[{"x":1.00,"y":2.00}]`,
+	} {
+		if !strings.Contains(s, want) {
+			t.Errorf("v2: expected %s in %s", want, s)
+		}
+	}
+
+	chart.TargetVersion = V3
+	buf, err = json.Marshal(chart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s = string(buf)
+	for _, want := range []string{
+		`"plugins":{"title":{"color":"rgba(0, 0, 0, 1.000)","text":"latency"},"tooltip":{"mode":"index"}}`,
+		`"y":{"beginAtZero":true,"grid":{"display":false},"min":1,"position":"left","title":{"font":{"size":12},"text":"ms"},"type":"linear"}`,
+		`"stepped":true`,
+	} {
+		if !strings.Contains(s, want) {
+			t.Errorf("v3: expected %s in %s", want, s)
+		}
+	}
+}
+
+func TestUnmarshalChart(t *testing.T) {
+	red := types.RGBA{R: 255, A: 128}
+	chart := Chart{Type: Bar, Label: "round trip"}
+	chart.Options.Title = &Title{Display: True, TextLines: []string{"a", "b"}}
+	chart.Options.OnClick = "function(e) { return '<b>'; }"
+	chart.tooltipCallbacks().Label = "function(item) { return item.label; }"
+	chart.Data.LabelLines = [][]string{{"x", "1"}, {"y"}}
+	chart.AddXAxis(Axis{Type: Category, Position: Bottom, Title: AxisTitle{Display: true, Text: "x"}})
+	chart.AddYAxis(Axis{Type: Log, Position: Right, ScaleLabel: &ScaleLabel{LabelLines: []string{"ms", "p99"}}, Time: &TimeOptions{Unit: UnitDay}})
+	chart.AddAnnotation(Annotation{Type: LineAnnotation, ScaleID: "y", Value: new(float64)})
+	chart.AddDataset(Dataset{Label: "a", Data: Floats([]float64{1.25, math.NaN()}), BackgroundColors: []types.RGBA{red, red}, FillTarget: "origin"})
+	chart.AddDataset(Dataset{Type: Line, Data: XYR([]float64{1}, []float64{2}, []float64{3}), BorderColor: &red, Fill: False,
+		PointStyle: RectRot, CubicInterpolationMode: InterpMonotone, XFloatFormat: "%.3f"})
+	chart.AddDataset(Dataset{Type: sankey, Data: []flow{{"a", "b", 1}}})
+
+	want, err := json.Marshal(chart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Chart
+	if err := json.Unmarshal(want, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Options.Scales["y"].ID != "y" || got.Options.OnClick != chart.Options.OnClick || got.Data.Datasets[1].PointStyle != RectRot {
+		t.Errorf("unexpected chart %+v", got)
+	}
+	b, err := json.Marshal(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !jsonEqual(t, b, want) {
+		t.Errorf("expected %s, got %s", want, b)
+	}
+
+	for _, bad := range []string{
+		`{"type":"pie"}`,
+		`{"type":"bar","options":{"scales":{"x":{"type":"radial"}}}}`,
+		`{"type":"bar","data":{"datasets":[{"pointStyle":"hexagon"}]}}`,
+		`{"type":"bar","data":{"datasets":[{"borderColor":"red"}]}}`,
+	} {
+		if err := json.Unmarshal([]byte(bad), &got); err == nil {
+			t.Errorf("expected an error for %s", bad)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	chart := Chart{Type: Bar, Data: Data{Labels: []string{"a", "b"}}}
+	chart.AddXAxis(Axis{Type: Category, Position: Bottom})
+	chart.AddYAxis(Axis{Type: Linear, Position: Left})
+	chart.AddDataset(Dataset{Label: "ok", Data: Floats([]float64{1, 2})})
+	if err := chart.Validate(); err != nil {
+		t.Fatalf("expected a valid chart, got %v", err)
+	}
+
+	chart.AddAxis(Axis{ID: "r", Type: Radial})
+	chart.AddAxis(Axis{ID: "side", Type: Linear, Position: Top})
+	chart.AddDataset(Dataset{Label: "long", Data: Floats([]float64{1, 2, 3})})
+	chart.AddDataset(Dataset{Label: "xy", Data: XY([]float64{1}, []float64{1, 2}), YAxisID: "side"})
+	chart.AddDataset(Dataset{Label: "bubble", Type: Bubble, Data: XY([]float64{1}, []float64{1}), XAxisID: "x2"})
+	chart.AddDataset(Dataset{Label: "dots", Type: Scatter, Data: XYR([]float64{1}, []float64{1}, []float64{1, 2})})
+	err := chart.Validate()
+	verr, ok := err.(ValidationError)
+	if !ok {
+		t.Fatalf("expected a ValidationError, got %v", err)
+	}
+	want := []string{
+		`chart: radial axis "r" on a bar chart, use Linear`,
+		`chart: dataset 1 ("long") has 3 values but the chart has 2 labels`,
+		`chart: dataset 2 ("xy") uses y-axis "side" positioned top`,
+		`chart: dataset 2 ("xy") has 1 x values for 2 y values`,
+		`chart: dataset 3 ("bubble") references unknown x-axis "x2"`,
+		`chart: dataset 3 ("bubble") is a bubble dataset without radii, use XYR`,
+		`chart: dataset 4 ("dots") is a scatter on category x-axis, use Linear`,
+		`chart: dataset 4 ("dots") has 2 radii for 1 points`,
+	}
+	var got []string
+	for _, e := range verr {
+		got = append(got, e.Error())
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected\n%s\ngot\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+	if !strings.HasPrefix(err.Error(), "chart: 8 problems:\n\tchart: radial axis") {
+		t.Errorf("unexpected message %s", err)
+	}
+	_, numErr := strconv.ParseFloat("x", 64)
+	wrapped := ValidationError{errors.New("chart: other"), fmt.Errorf("chart: bad value: %w", numErr)}
+	var ne *strconv.NumError
+	if !errors.Is(wrapped, strconv.ErrSyntax) || !errors.As(wrapped, &ne) || errors.Is(wrapped, io.EOF) {
+		t.Errorf("expected errors.Is and errors.As to look into the problems")
+	}
+
+	radar := Chart{Type: Radar, Data: Data{Labels: []string{"a"}}}
+	radar.AddRAxis(Axis{})
+	radar.AddXAxis(Axis{Type: Linear})
+	radar.AddDataset(Dataset{Data: Floats([]float64{1})})
+	if err := radar.Validate(); err == nil || err.Error() != `chart: radar charts have no cartesian axes, axis "x" is ignored` {
+		t.Errorf("unexpected radar error %v", err)
+	}
+}
+
+func TestTooltipCallbacks(t *testing.T) {
+	chart := Chart{Type: Line}
+	chart.AddDataset(Dataset{Data: Floats([]float64{1})})
+	chart.Options.Tooltip = &Tooltip{Callbacks: &TooltipCallbacks{
+		BeforeBody:  "function(items) { return 'n=' + items.length; }",
+		LabelColor:  "function(item) { return {borderColor: 'red', backgroundColor: 'red'}; }",
+		AfterFooter: "function() { return '</script>'; }",
+	}}
+	var buf bytes.Buffer
+	if err := chart.SaveHTML(&buf, RenderOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`"afterFooter":function() { return '<\/script>'; }`,
+		`"beforeBody":function(items) { return 'n=' + items.length; }`,
+		`"labelColor":function(item) { return {borderColor: 'red', backgroundColor: 'red'}; }`,
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("expected %s in the page", want)
+		}
+	}
+
+	b, err := json.Marshal(chart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Chart
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got.Options.Tooltip.Callbacks, chart.Options.Tooltip.Callbacks) {
+		t.Errorf("unexpected callbacks %+v", got.Options.Tooltip.Callbacks)
+	}
+	if err := json.Unmarshal([]byte(`{"labels":"function() {}"}`), got.Options.Tooltip.Callbacks); err == nil {
+		t.Error("expected an error for an unknown callback")
+	}
+}
+
+func TestStoredConfig(t *testing.T) {
+	chart := Chart{Type: Bar}
+	chart.Options.Tooltip = &Tooltip{Mode: "index"}
+	chart.Options.IndexAxis = "y"
+	chart.AddXAxis(Axis{Type: Linear, Position: Bottom, Title: AxisTitle{Display: true, Text: "ms"}})
+	chart.AddYAxis(Axis{Type: Category, Position: Left, GridLines: False, Tick: &Tick{Min: 1}, Title: AxisTitle{Display: true, Text: "host"}})
+	chart.AddDataset(Dataset{Data: xy{x: []float64{1}, y: []float64{2}}, SteppedLine: True})
+	v3 := chart
+	v3.TargetVersion = V3
+	want, err := json.Marshal(v3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v2 := chart
+	v2.TargetVersion = V2
+	for _, c := range []Chart{chart, v2, v3} {
+		s, err := Persist(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := json.Marshal(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s, err = ParseStoredConfig(b); err != nil {
+			t.Fatal(err)
+		}
+		if s.Schema != schemaVersion() || s.ChartJS != c.TargetVersion {
+			t.Errorf("unexpected versions %d %d", s.Schema, s.ChartJS)
+		}
+		for _, target := range []chartJSVersion{V3, V4} {
+			got, err := s.Migrate(target)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !jsonEqual(t, got, want) {
+				t.Errorf("%d to %d: expected %s, got %s", c.TargetVersion, target, want, got)
+			}
+		}
+	}
+
+	// bare configs stored before Persist.
+	b, err := json.Marshal(v2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := ParseStoredConfig(b)
+	if err != nil || s.ChartJS != V2 || s.Schema != 1 {
+		t.Fatalf("expected a v2 config, got %+v %v", s, err)
+	}
+	if s, err = s.Upgrade(V4); err != nil || s.ChartJS != V4 || !jsonEqual(t, s.Config, want) {
+		t.Errorf("unexpected upgrade %s %v", s.Config, err)
+	}
+	if _, err := s.Migrate(V2); err == nil {
+		t.Error("expected an error converting to an older chart.js")
+	}
+
+	for config, want := range map[string]chartJSVersion{
+		`{"options":{"plugins":{"crosshair":{"color":"red"}}}}`: 0,
+		`{"options":{"indexAxis":"y","plugins":{}}}`:            0,
+		`{"options":{"plugins":{"tooltip":{"mode":"index"}}}}`:  V3,
+		`{"options":{"plugins":{"legend":{"display":false}}}}`:  V3,
+	} {
+		if s, err := ParseStoredConfig([]byte(config)); err != nil || s.ChartJS != want {
+			t.Errorf("%s: expected chart.js %d, got %d %v", config, want, s.ChartJS, err)
+		}
+	}
+
+	// configs of schema version 1 wrote Axis.GridLines as "gridLine".
+	s = StoredConfig{Schema: 1, Config: []byte(`{"options":{"scales":{"y":{"gridLine":false}}}}`)}
+	if got, err := s.Migrate(0); err != nil || string(got) != `{"options":{"scales":{"y":{"grid":{"display":false}}}}}` {
+		t.Errorf("expected the grid migration, got %s %v", got, err)
+	}
+
+	version := schemaVersion()
+	defer func(m []SchemaMigration) { schemaMigrations = m }(schemaMigrations)
+	schemaMigrations = append(schemaMigrations, func(c map[string]interface{}) error {
+		c["type"] = "line"
+		return nil
+	})
+	s = StoredConfig{Schema: 1, Config: []byte(`{"type":"bar"}`)}
+	if got, err := s.Migrate(0); err != nil || string(got) != `{"type":"line"}` {
+		t.Errorf("expected the schema migration, got %s %v", got, err)
+	}
+	if s, _ := Persist(Chart{}); s.Schema != version+1 {
+		t.Errorf("expected schema %d, got %d", version+1, s.Schema)
+	}
+	s.Schema = version + 2
+	if _, err := s.Migrate(0); err == nil {
+		t.Error("expected an error for a newer schema")
+	}
+}
+
+// jsonEqual reports whether a and b hold the same JSON value.
+func jsonEqual(t *testing.T, a, b []byte) bool {
+	var x, y interface{}
+	if err := json.Unmarshal(a, &x); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(b, &y); err != nil {
+		t.Fatal(err)
+	}
+	return reflect.DeepEqual(x, y)
+}
+
+func TestCorr(t *testing.T) {
+	a := []float64{1, 2, 3, 4}
+	b := []float64{2, 4, 6, 100}
+	c := []float64{4, 3, 2, 1}
+	m, err := Corr([][]float64{a, b, c}, Spearman)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m[0][1] != 1 || m[0][2] != -1 || m[1][1] != 1 {
+		t.Errorf("unexpected spearman correlations %v", m)
+	}
+	m, _ = Corr([][]float64{a, b}, Pearson)
+	if m[0][1] >= 1 || m[0][1] < 0.7 {
+		t.Errorf("unexpected pearson correlation %v", m[0][1])
+	}
+	if got := ranks([]float64{3, 1, 3, 2}); !reflect.DeepEqual(got, []float64{3.5, 1, 3.5, 2}) {
+		t.Errorf("unexpected ranks %v", got)
+	}
+
+	chart, err := CorrChart([]string{"a", "b", "c"}, [][]float64{a, b, c}, Spearman)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := chart.Data.Datasets[0]
+	if d.BackgroundColors[1] != types.RdBu(0) || d.BackgroundColors[2] != types.RdBu(1) {
+		t.Errorf("expected red for 1 and blue for -1, got %v", d.BackgroundColors)
+	}
+	if cb := chart.Options.Scales["x"].Tick.Callback; !strings.Contains(string(cb), `["a","b","c"][value]`) {
+		t.Errorf("unexpected tick callback %s", cb)
+	}
+}
+
+func TestWeightedSamples(t *testing.T) {
+	xs := []float64{1, 2, 3, 10}
+	ws := []float64{2, 0, 1, 1}
+	expanded := []float64{1, 1, 3, 10}
+
+	b, err := SummarizeWeighted(xs, ws)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := Summarize(expanded); !reflect.DeepEqual(b, want) {
+		t.Errorf("got %+v, want %+v", b, want)
+	}
+	if _, err := SummarizeWeighted(xs, []float64{1}); err == nil {
+		t.Error("expected error for missing weights")
+	}
+
+	chart, err := LogHistogramWeighted(xs, []float64{0.5, 1, 1, 2.5}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := chart.Data.Datasets[0].Data.(Values).Xs(); !reflect.DeepEqual(got, []float64{2.5, 2.5}) {
+		t.Errorf("unexpected weighted counts %v", got)
+	}
+
+	chart, err = ECDFWeighted(xs, ws)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := chart.Data.Datasets[0].Data.(Values).Ys(); !reflect.DeepEqual(got, []float64{0, 0.5, 0.5, 0.75, 0.75, 1}) {
+		t.Errorf("unexpected weighted ECDF %v", got)
+	}
+
+	counts, _, _, err := Histogram2D{XBins: 1, YBins: 1}.WeightedCounts(xs, xs, ws)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counts[0][0] != 4 {
+		t.Errorf("unexpected weighted 2D count %v", counts[0][0])
+	}
+}
+
+func TestSaveHTMLRenderOptions(t *testing.T) {
+	chart := Chart{Type: Bar, Data: Data{Labels: []string{"a"}}}
+	chart.AddDataset(Dataset{Data: xy{x: []float64{1}}})
+	var buf bytes.Buffer
+	err := chart.SaveHTML(&buf, RenderOptions{
+		Title:   "Report <1>",
+		Width:   640,
+		ChartJS: "/static/chart.js",
+		Custom:  "console.log(charts.length);",
+		TMap:    map[string]interface{}{"height": 300},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := buf.String()
+	for _, want := range []string{
+		"<!DOCTYPE html>",
+		"<title>Report &lt;1&gt;</title>",
+		`<script src="/static/chart.js"></script>`,
+		"height:300px;width:640px;",
+		"console.log(charts.length);",
+	} {
+		if !strings.Contains(s, want) {
+			t.Errorf("expected %s in output", want)
+		}
+	}
+}
+
+func TestWindRose(t *testing.T) {
+	w := WindRose{Sectors: 4, SpeedBins: []float64{5}}
+	dirs := []float64{0, 350, 44, 90, 180, -90}
+	speeds := []float64{1, 8, 2, 3, 9, 4}
+	freq, err := w.Frequencies(dirs, speeds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := 100.0 / 6
+	want := [][]float64{{2 * p, p, 0, p}, {p, 0, p, 0}}
+	if !reflect.DeepEqual(freq, want) {
+		t.Errorf("expected %v, got %v", want, freq)
+	}
+	if _, err := w.Frequencies(dirs, speeds[:1]); err == nil {
+		t.Error("expected an error for mismatched lengths")
+	}
+	inf := math.Inf(1)
+	freq, err = (WindRose{Sectors: 5}).Frequencies([]float64{inf, 0, math.NaN()}, []float64{1, inf, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(freq, [][]float64{{0, 0, 0, 0, 0}}) {
+		t.Errorf("expected non-finite observations to be dropped, got %v", freq)
+	}
+
+	chart, err := w.Chart(dirs, speeds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(chart.Data.Labels, []string{"N", "E", "S", "W"}) {
+		t.Errorf("unexpected sector labels %v", chart.Data.Labels)
+	}
+	outer := chart.Data.Datasets[1]
+	if outer.Label != "> 5" || outer.Data.(xyValues).xs[0] != 3*p {
+		t.Errorf("expected the outer ring to stack on the inner one, got %q %v", outer.Label, outer.Data)
+	}
+	b, err := json.Marshal(chart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), `"type":"radar"`) || !strings.Contains(string(b), `"type":"radialLinear"`) {
+		t.Errorf("expected a radar chart with a radial axis, got %s", b)
+	}
+	if got := (WindRose{Sectors: 5}).sectorLabels()[1]; got != "72°" {
+		t.Errorf("expected degree labels, got %s", got)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	chart := &Chart{Type: Bar, Data: Data{Labels: []string{"a"}}}
+	chart.AddDataset(Dataset{Label: "cpu", Data: xy{x: []float64{1}}})
+	h := Handler(chart)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/cpu/", nil))
+	if rec.Code != 200 || !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/html") {
+		t.Fatalf("unexpected response %d %s", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(rec.Body.String(), "<canvas") {
+		t.Errorf("expected a page with a canvas, got %s", rec.Body)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/cpu/data.json", nil))
+	var cfg map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg["type"] != "bar" || rec.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("unexpected config %s", rec.Body)
+	}
+}
+
+func TestHTMLTemplateBlocks(t *testing.T) {
+	chart := Chart{Type: Bar, Data: Data{Labels: []string{"a"}}}
+	chart.AddDataset(Dataset{Data: xy{x: []float64{1}}})
+	tpl := HTMLTemplate()
+	template.Must(tpl.Parse(`
+		{{ define "head" }}<link rel="stylesheet" href="/site.css">{{ end }}
+		{{ define "scripts" }}<script src="/extra.js"></script>{{ end }}
+		{{ define "canvas" }}<div class="card">{{ template "chartjs-canvas" . }}</div>{{ end }}
+		{{ define "init" }}console.log("ready");{{ end }}`))
+	var buf bytes.Buffer
+	if err := chart.SaveHTML(&buf, RenderOptions{HTMLTemplate: tpl}); err != nil {
+		t.Fatal(err)
+	}
+	s := buf.String()
+	for _, want := range []string{
+		`<link rel="stylesheet" href="/site.css">`,
+		`<script src="/extra.js"></script>`,
+		`<div class="card">`,
+		`<canvas id="canvas0" style="height:400px;width:400px;"></canvas>`,
+		`console.log("ready");`,
+	} {
+		if !strings.Contains(s, want) {
+			t.Errorf("expected %s in output", want)
+		}
+	}
+
+	buf.Reset()
+	if err := chart.SaveHTML(&buf, RenderOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "card") {
+		t.Error("expected the package template to be unchanged")
+	}
+}
+
+func TestNormalizeRadar(t *testing.T) {
+	newChart := func() *Chart {
+		c := &Chart{Type: Radar, Data: Data{Labels: []string{"speed", "price", "weight"}}}
+		c.AddDataset(Dataset{Label: "a", Data: xy{x: []float64{100, 20000, 3}}})
+		c.AddDataset(Dataset{Label: "b", Data: xy{x: []float64{300, 10000, 3}}})
+		return c
+	}
+	c := newChart()
+	if err := c.NormalizeRadar(MinMaxScaling); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Data.Datasets[0].Data.(xyValues).xs; !reflect.DeepEqual(got, []float64{0, 1, 0.5}) {
+		t.Errorf("unexpected min-max values %v", got)
+	}
+	if r := c.Options.Scales["r"]; r.Type != Radial || *r.SuggestedMax != 1 {
+		t.Errorf("expected a radial axis up to 1, got %+v", r)
+	}
+	b, err := json.Marshal(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(inlineJS(b)), "[[100,20000,3],[300,10000,3]]") {
+		t.Errorf("expected the original values in the tooltip callback, got %s", inlineJS(b))
+	}
+
+	c = newChart()
+	if err := c.NormalizeRadar(ZScoreScaling); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Data.Datasets[1].Data.(xyValues).xs; !reflect.DeepEqual(got, []float64{1, -1, 0}) {
+		t.Errorf("unexpected z-scores %v", got)
+	}
+
+	c.AddDataset(Dataset{Label: "c", Data: xy{x: []float64{1}}})
+	if err := c.NormalizeRadar(MinMaxScaling); err == nil {
+		t.Error("expected an error for a short dataset")
+	}
+}
+
+func TestBreakAxis(t *testing.T) {
+	chart := &Chart{Type: Bar, Data: Data{Labels: []string{"a", "b", "c"}}}
+	chart.AddDataset(Dataset{Label: "n", Data: xy{x: []float64{10, 5000, 20}}})
+	if err := chart.BreakAxis(50, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := chart.Data.Datasets[0].Data.(xyValues).xs; !reflect.DeepEqual(got, []float64{10, 55, 20}) {
+		t.Errorf("expected the huge bar to be clipped, got %v", got)
+	}
+	a := chart.Options.Annotation.Annotations[0]
+	if a.YScaleID != "y" || *a.YMin <= 50 || *a.YMax >= 55 {
+		t.Errorf("expected a stripe between the threshold and the clipped bars, got %+v", a)
+	}
+	b, err := chart.MarshalTagged()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := string(inlineJS(b))
+	for _, want := range []string{
+		`"max":55`,
+		"return value > 50 ? '' : value;",
+		"[[10,5000,20]]",
+	} {
+		if !strings.Contains(s, want) {
+			t.Errorf("expected %s in %s", want, s)
+		}
+	}
+	if err := chart.BreakAxis(0, nil); err == nil {
+		t.Error("expected an error for a zero threshold")
+	}
+}
+
+func TestPage(t *testing.T) {
+	newChart := func(label string) *Chart {
+		c := &Chart{Type: Bar, Data: Data{Labels: []string{"a"}}}
+		c.AddDataset(Dataset{Label: label, Data: xy{x: []float64{1}}})
+		return c
+	}
+	page := NewPage("Dashboard", newChart("cpu"), newChart("memory"))
+	page.Add(newChart("disk"))
+	page.Columns = 3
+	var buf bytes.Buffer
+	if err := page.Render(&buf); err != nil {
+		t.Fatal(err)
+	}
+	s := buf.String()
+	for _, want := range []string{
+		"<title>Dashboard</title>",
+		`style="display:grid;grid-template-columns:repeat(3,minmax(0,1fr));gap:16px"`,
+		`id="canvas0"`, `id="canvas1"`, `id="canvas2"`,
+		`"label":"disk"`,
+	} {
+		if !strings.Contains(s, want) {
+			t.Errorf("expected %s in output", want)
+		}
+	}
+	if strings.Contains(s, "<hr>") {
+		t.Error("expected no separators between grid cells")
+	}
+
+	page.Gap = "1em;background:url(x)"
+	if err := page.Render(&buf); err == nil {
+		t.Error("expected an error for a gap that is not a length")
+	}
+	page.Gap = "1.5em"
+	if grid, err := page.layout(); err != nil || !strings.HasSuffix(string(grid), "gap:1.5em") {
+		t.Errorf("unexpected grid %q %v", grid, err)
+	}
+
+	page.Add(nil)
+	if err := page.Render(&buf); err == nil {
+		t.Error("expected an error for a nil chart")
+	}
+}
+
+func TestJitter(t *testing.T) {
+	v := xy{x: []float64{1, 1, 1, 2}, y: []float64{5, 5, 5, 5}}
+	j := Jitter{Width: 0.5, Seed: 42}
+	a, b := j.Apply(v), j.Apply(v)
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("expected the same seed to give the same points, got %v and %v", a, b)
+	}
+	for i, x := range a.Xs() {
+		if math.Abs(x-v.x[i]) > 0.25 || x == v.x[i] {
+			t.Errorf("point %d moved from %v to %v", i, v.x[i], x)
+		}
+	}
+	if !reflect.DeepEqual(a.Ys(), v.y) {
+		t.Errorf("expected y to stay, got %v", a.Ys())
+	}
+	if c := (Jitter{Width: 0.5, Seed: 7}).Apply(v); reflect.DeepEqual(a, c) {
+		t.Error("expected another seed to give other points")
+	}
+
+	newChart := func() *Chart {
+		c := &Chart{Type: Scatter}
+		c.AddDataset(Dataset{Data: v})
+		return c
+	}
+	c1, c2 := newChart(), newChart()
+	c1.Jitter(j)
+	c2.Jitter(j)
+	b1, _ := json.Marshal(c1)
+	b2, _ := json.Marshal(c2)
+	if !bytes.Equal(b1, b2) {
+		t.Errorf("expected reproducible output, got %s and %s", b1, b2)
+	}
+}
+
+func TestRedactor(t *testing.T) {
+	chart := &Chart{Type: Line}
+	chart.AddDataset(Dataset{Label: "db-prod-eu-1", Data: xy{x: []float64{1, 2}, y: []float64{1234, 5678}}})
+	chart.AddDataset(Dataset{Label: "cache", Data: metaXY{xy{x: []float64{1}, y: []float64{42}}, []map[string]string{{"host": "a"}}}})
+	names := map[string]string{"db-prod-eu-1": "database"}
+	chart.Redactor = func(label string, v float64) (string, float64) {
+		if n, ok := names[label]; ok {
+			label = n
+		}
+		return label, math.Round(v / 1000)
+	}
+	b, err := json.Marshal(chart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := string(b)
+	for _, want := range []string{
+		`"label":"database"`,
+		`{"x":1.00,"y":1.00},{"x":2.00,"y":6.00}`,
+		`"label":"cache"`,
+		`"meta":{"host":"a"}`,
+	} {
+		if !strings.Contains(s, want) {
+			t.Errorf("expected %s in %s", want, s)
+		}
+	}
+	if strings.Contains(s, "db-prod") || chart.Data.Datasets[0].Label != "db-prod-eu-1" {
+		t.Errorf("expected only the output to be redacted, got %s", s)
+	}
+
+	chart.AddDataset(Dataset{Data: json.RawMessage(`[1]`)})
+	if _, err := json.Marshal(chart); err == nil {
+		t.Error("expected an error for data that cannot be redacted")
+	}
+}
+
+func TestRenderSVG(t *testing.T) {
+	wellFormed := func(b []byte) {
+		d := xml.NewDecoder(bytes.NewReader(b))
+		for {
+			if _, err := d.Token(); err == io.EOF {
+				return
+			} else if err != nil {
+				t.Fatalf("bad SVG: %v\n%s", err, b)
+			}
+		}
+	}
+
+	chart := Chart{Type: Bar, Data: Data{Labels: []string{"a", "b<c"}}}
+	chart.Options.Title = &Title{Display: True, Text: "Requests & errors"}
+	chart.AddDataset(Dataset{Label: "ok", Data: xy{x: []float64{3, 5}}})
+	chart.AddDataset(Dataset{Label: "err", Data: xy{x: []float64{1, 2}}})
+	var buf bytes.Buffer
+	if err := chart.RenderSVG(&buf); err != nil {
+		t.Fatal(err)
+	}
+	wellFormed(buf.Bytes())
+	s := buf.String()
+	for _, want := range []string{
+		`<svg xmlns="http://www.w3.org/2000/svg" width="640" height="400"`,
+		"Requests &amp; errors",
+		"b&lt;c",
+		">ok</text>",
+		">err</text>",
+	} {
+		if !strings.Contains(s, want) {
+			t.Errorf("expected %s in %s", want, s)
+		}
+	}
+	// 2 legend boxes, 4 bars and the background.
+	if n := strings.Count(s, "<rect"); n != 7 {
+		t.Errorf("expected 7 rects, got %d", n)
+	}
+
+	scatter := Chart{Type: Scatter}
+	scatter.AddDataset(Dataset{Data: xy{x: []float64{1, 2, 3}, y: []float64{-1, 0, 10}}})
+	buf.Reset()
+	if err := scatter.RenderSVG(&buf); err != nil {
+		t.Fatal(err)
+	}
+	wellFormed(buf.Bytes())
+	if n := strings.Count(buf.String(), "<circle"); n != 3 {
+		t.Errorf("expected 3 points, got %d", n)
+	}
+
+	if got := niceTicks(0, 10, 5); !reflect.DeepEqual(got, []float64{0, 2, 4, 6, 8, 10}) {
+		t.Errorf("unexpected ticks %v", got)
+	}
+	if got := niceTicks(1e17, 1e17+32, 5); len(got) > maxTicks+1 {
+		t.Errorf("expected at most %d ticks, got %d", maxTicks+1, len(got))
+	}
+
+	secret := Chart{Type: Bar, Redactor: func(label string, v float64) (string, float64) { return "redacted", 0 }}
+	secret.AddDataset(Dataset{Label: "salaries", Data: Floats([]float64{1, 2})})
+	buf.Reset()
+	if err := secret.RenderSVG(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if s := buf.String(); strings.Contains(s, "salaries") || !strings.Contains(s, "redacted") {
+		t.Errorf("expected a redacted image, got %s", s)
+	}
+	if err := (Chart{Type: Doughnut}).RenderSVG(&buf); err == nil {
+		t.Error("expected an error for a doughnut chart")
+	}
+}
+
+func TestDiffChart(t *testing.T) {
+	before := Measurements{"BenchmarkA": 100, "BenchmarkB": 50}
+	after := Measurements{"BenchmarkA": 80, "BenchmarkC": 10}
+	chart := Diff{Relative: true}.Chart(before, after)
+	if !reflect.DeepEqual(chart.Data.Labels, []string{"BenchmarkA", "BenchmarkB", "BenchmarkC"}) {
+		t.Errorf("unexpected labels %v", chart.Data.Labels)
+	}
+	if len(chart.Data.Datasets) != 3 {
+		t.Fatalf("expected before, after and change datasets, got %d", len(chart.Data.Datasets))
+	}
+	delta := chart.Data.Datasets[2]
+	if delta.Label != "change (%)" || delta.YAxisID != "delta" || delta.Data.(xyValues).xs[0] != -20 {
+		t.Errorf("unexpected change dataset %+v", delta)
+	}
+	b, err := json.Marshal(chart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := string(b)
+	for _, want := range []string{
+		`"data":[100.00,50.00,null]`,
+		`"data":[80.00,null,10.00]`,
+		`"type":"line"`,
+	} {
+		if !strings.Contains(s, want) {
+			t.Errorf("expected %s in %s", want, s)
+		}
+	}
+}
+
+func TestAddTarget(t *testing.T) {
+	chart := &Chart{Type: Bar, Data: Data{Labels: []string{"jan", "feb", "mar"}}}
+	chart.AddDataset(Dataset{Label: "spend", Data: xy{x: []float64{90, 120, math.NaN()}}})
+	if err := chart.AddTarget(Target{Values: []float64{100}}); err != nil {
+		t.Fatal(err)
+	}
+	want := []types.RGBA{DefaultPalette[4], DefaultPalette[2], DefaultPalette[0]}
+	if got := chart.Data.Datasets[0].BackgroundColors; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected under, over and unchanged colors, got %v", got)
+	}
+	b, err := json.Marshal(chart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := string(b)
+	for _, want := range []string{
+		`"borderDash":[6,4]`,
+		`"steppedLine":true`,
+		`"label":"target"`,
+		`"data":[100.00,100.00,100.00]`,
+		`"type":"line"`,
+	} {
+		if !strings.Contains(s, want) {
+			t.Errorf("expected %s in %s", want, s)
+		}
+	}
+	var got Chart
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Data.Datasets[0].Type != unsetType || got.Data.Datasets[1].Type != Line {
+		t.Errorf("expected the target to stay a line dataset, got %s and %s", got.Data.Datasets[0].Type, got.Data.Datasets[1].Type)
+	}
+	if err := chart.AddTarget(Target{Values: []float64{1, 2}}); err == nil {
+		t.Error("expected an error for a target per label mismatch")
+	}
+}
+
+func TestTimeSeries(t *testing.T) {
+	t0 := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	s := TimeSeries{Time: []time.Time{t0, t0.Add(time.Hour)}, Value: []float64{1, 2}}
+	chart := Chart{Type: Line}
+	chart.AddXAxis(Axis{Type: Time, Position: Bottom, Time: &TimeOptions{
+		Unit:           UnitHour,
+		DisplayFormats: map[string]string{"hour": "HH:mm"},
+		TooltipFormat:  "YYYY-MM-DD HH:mm",
+	}})
+	chart.AddDataset(Dataset{Data: s})
+	chart.AddDataset(Dataset{Data: s, TimeFormat: time.RFC3339})
+	b, err := json.Marshal(chart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	str := string(b)
+	for _, want := range []string{
+		`"data":[{"x":1709294400000,"y":1.00},{"x":1709298000000,"y":2.00}]`,
+		`"data":[{"x":"2024-03-01T12:00:00Z","y":1.00},{"x":"2024-03-01T13:00:00Z","y":2.00}]`,
+		`"time":{"unit":"hour","displayFormats":{"hour":"HH:mm"},"tooltipFormat":"YYYY-MM-DD HH:mm"}`,
+	} {
+		if !strings.Contains(str, want) {
+			t.Errorf("expected %s in %s", want, str)
+		}
+	}
+	if xs := s.Xs(); xs[1]-xs[0] != 3600000 {
+		t.Errorf("unexpected epoch millis %v", xs)
+	}
+
+	chart.Data.Datasets[0].Data = TimeSeries{Time: s.Time, Value: s.Value[:1]}
+	if _, err := json.Marshal(chart); err == nil {
+		t.Error("expected an error for mismatched lengths")
+	}
+}
+
+func TestTimeBuckets(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+	b := TimeBuckets{Location: ny}
+	// hourly points across the 23 hour day of 2024-03-10 and the 25 hour
+	// day of 2024-11-03.
+	var ts []time.Time
+	var ys []float64
+	for _, day := range []time.Time{time.Date(2024, 3, 9, 0, 0, 0, 0, ny), time.Date(2024, 11, 2, 0, 0, 0, 0, ny)} {
+		for h := 0; h < 72; h++ {
+			ts = append(ts, day.Add(time.Duration(h)*time.Hour))
+			ys = append(ys, 1)
+		}
+	}
+	s, err := b.Aggregate(ts, ys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for i, start := range s.Time {
+		got = append(got, fmt.Sprintf("%s %g", start.Format("01-02 15:04"), s.Value[i]))
+	}
+	want := []string{"03-09 00:00 24", "03-10 00:00 23", "03-11 00:00 24", "03-12 00:00 1",
+		"11-02 00:00 24", "11-03 00:00 25", "11-04 00:00 23"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	// the repeated hour of the fall transition is two buckets.
+	b.Unit = UnitHour
+	one := time.Date(2024, 11, 3, 5, 30, 0, 0, time.UTC)
+	if a, c := b.Truncate(one), b.Truncate(one.Add(time.Hour)); !c.Equal(a.Add(time.Hour)) || a.Format("15:04") != "01:00" || c.Format("15:04") != "01:00" {
+		t.Errorf("unexpected hours %v %v", a, c)
+	}
+	for _, c := range []struct {
+		unit timeUnit
+		want string
+	}{
+		{UnitWeek, "2024-11-04"},
+		{UnitMonth, "2024-11-01"},
+		{UnitQuarter, "2024-10-01"},
+		{UnitYear, "2024-01-01"},
+	} {
+		b.Unit = c.unit
+		if got := b.Truncate(time.Date(2024, 11, 10, 15, 0, 0, 0, ny)).Format("2006-01-02"); got != c.want {
+			t.Errorf("unit %d: expected %s, got %s", c.unit, c.want, got)
+		}
+	}
+	b.Unit = UnitMonth
+	if got := b.Next(time.Date(2024, 10, 15, 0, 0, 0, 0, time.UTC)); !got.Equal(time.Date(2024, 11, 1, 0, 0, 0, 0, ny)) {
+		t.Errorf("unexpected next month %v", got)
+	}
+
+	chart := Chart{Type: Line}
+	chart.AddXAxis(Axis{Type: Time, Position: Bottom, Adapters: ZoneAdapters(ny)})
+	chart.AddDataset(Dataset{Data: TimeSeries{Time: s.Time[:1], Value: s.Value[:1]}, TimeFormat: time.RFC3339, Location: time.UTC})
+	buf, err := json.Marshal(chart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"adapters":{"date":{"zone":"America/New_York"}}`, `"x":"2024-03-09T05:00:00Z"`} {
+		if !strings.Contains(string(buf), want) {
+			t.Errorf("expected %s in %s", want, buf)
+		}
+	}
+	if got := chart.RequiredPlugins(); !reflect.DeepEqual(got, []string{"luxon", "date-adapter-luxon"}) {
+		t.Errorf("expected the luxon date adapter, got %v", got)
+	}
+
+	strip := StatusStrip{Unit: UnitDay, Location: ny, Layout: "01-02"}
+	c, err := strip.Chart(nil, time.Date(2024, 11, 2, 0, 0, 0, 0, ny), time.Date(2024, 11, 5, 0, 0, 0, 0, ny))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var labels []string
+	for _, d := range c.Data.Datasets {
+		labels = append(labels, d.Label)
+	}
+	if !reflect.DeepEqual(labels, []string{"11-02", "11-03", "11-04"}) {
+		t.Errorf("unexpected daily segments %v", labels)
+	}
+}
+
+func TestCalendar(t *testing.T) {
+	// 2024-12-25 is a Wednesday.
+	cal := BusinessCalendar{Holidays: []time.Time{time.Date(2024, 12, 25, 0, 0, 0, 0, time.UTC)}}
+	day := func(d int) time.Time { return time.Date(2024, 12, d, 12, 0, 0, 0, time.UTC) }
+	for d, want := range map[int]bool{20: true, 21: false, 22: false, 24: true, 25: false, 26: true} {
+		if got := cal.IsBusinessDay(day(d)); got != want {
+			t.Errorf("Dec %d: expected %v", d, want)
+		}
+	}
+
+	var ts []time.Time
+	var ys []float64
+	for d := 20; d <= 26; d++ {
+		ts = append(ts, day(d))
+		ys = append(ys, float64(d))
+	}
+	format := func(s TimeSeries) []string {
+		var out []string
+		for i, t := range s.Time {
+			out = append(out, fmt.Sprintf("%d:%g", t.Day(), s.Value[i]))
+		}
+		return out
+	}
+	b := TimeBuckets{Calendar: cal}
+	s, err := b.Aggregate(ts, ys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := format(s), []string{"20:20", "23:23", "24:24", "26:26"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("skip: expected %v, got %v", want, got)
+	}
+	b.MergeDaysOff = true
+	if s, err = b.Aggregate(ts, ys); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := format(s), []string{"20:20", "23:66", "24:24", "26:51"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("merge: expected %v, got %v", want, got)
+	}
+	if got := b.Next(day(20)); got.Day() != 23 {
+		t.Errorf("expected Monday after Friday, got %v", got)
+	}
+	if got := b.Next(day(24)); got.Day() != 26 {
+		t.Errorf("expected the day after the holiday, got %v", got)
+	}
+
+	g := TimeGaps{Calendar: cal, Layout: "02"}
+	_, labels, err := g.Compress(ts, ys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(labels, []string{"20", "23", "24", "26"}) {
+		t.Errorf("unexpected compressed labels %v", labels)
+	}
+	never := CalendarFunc(func(time.Time) bool { return false })
+	if got := (TimeBuckets{Calendar: never}).Next(day(20)); got.Before(day(20)) {
+		t.Errorf("unexpected next day %v", got)
+	}
+}
+
+func TestValuesAdapters(t *testing.T) {
+	chart := Chart{Type: Bar}
+	chart.AddDataset(Dataset{Data: FromMap(map[string]float64{"b": 2, "a": 1, "c": 3})})
+	chart.AddDataset(Dataset{Data: Floats([]float64{4, 5, 6})})
+	if !reflect.DeepEqual(chart.Data.Labels, []string{"a", "b", "c"}) {
+		t.Errorf("expected labels from the map keys, got %v", chart.Data.Labels)
+	}
+	b, err := json.Marshal(chart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"data":[1.00,2.00,3.00]`, `"data":[4.00,5.00,6.00]`} {
+		if !strings.Contains(string(b), want) {
+			t.Errorf("expected %s in %s", want, b)
+		}
+	}
+
+	bubble := Chart{Type: Bubble}
+	bubble.AddDataset(Dataset{Data: XYR([]float64{1}, []float64{2}, []float64{3})})
+	bubble.AddDataset(Dataset{Data: XY([]float64{1, 2}, []float64{3})})
+	if _, err := json.Marshal(bubble.Data.Datasets[0]); err != nil {
+		t.Error(err)
+	}
+	if _, err := json.Marshal(bubble.Data.Datasets[1]); err == nil {
+		t.Error("expected an error for mismatched lengths")
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	chart := Chart{Type: Line}
+	chart.AddDataset(Dataset{Label: "a", Data: Floats([]float64{1, 2})})
+	var calls []string
+	chart.Use(
+		func(c *Chart) error {
+			calls = append(calls, "title")
+			c.Options.Title = &Title{Display: True, Text: "themed"}
+			c.Data.Datasets[0].Label = "renamed"
+			return nil
+		},
+		ApplyPalette(nil),
+		func(c *Chart) error {
+			calls = append(calls, "check")
+			if c.Data.Datasets[0].BorderColor == nil {
+				t.Error("expected the palette to be applied first")
+			}
+			return nil
+		},
+	)
+	b, err := json.Marshal(chart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := string(b)
+	for _, want := range []string{`"text":"themed"`, `"label":"renamed"`, `"borderColor":"rgba(78, 121, 167, 1.000)"`} {
+		if !strings.Contains(s, want) {
+			t.Errorf("expected %s in %s", want, s)
+		}
+	}
+	if !reflect.DeepEqual(calls, []string{"title", "check"}) {
+		t.Errorf("unexpected calls %v", calls)
+	}
+	if chart.Options.Title != nil || chart.Data.Datasets[0].Label != "a" {
+		t.Error("expected the chart to be unchanged")
+	}
+
+	chart.Use(func(c *Chart) error { return fmt.Errorf("rejected") })
+	if _, err := json.Marshal(chart); err == nil || !strings.Contains(err.Error(), "rejected") {
+		t.Errorf("expected the middleware error, got %v", err)
+	}
+	if err := chart.SaveHTML(io.Discard, RenderOptions{}); err == nil {
+		t.Error("expected SaveHTML to fail too")
+	}
+}
+
+func TestGenericSeries(t *testing.T) {
+	type count uint32
+	chart := Chart{Type: Bar}
+	chart.AddDataset(Dataset{Data: Series[int]{1, 2, 3}})
+	chart.AddDataset(Dataset{Data: Series[count]{4, 5}})
+	chart.AddDataset(Dataset{Data: Points[float32]{X: []float32{0.5}, Y: []float32{1.25}}})
+	b, err := json.Marshal(chart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`"data":[1.00,2.00,3.00]`,
+		`"data":[4.00,5.00]`,
+		`"data":[{"x":0.50,"y":1.25}]`,
+	} {
+		if !strings.Contains(string(b), want) {
+			t.Errorf("expected %s in %s", want, b)
+		}
+	}
+	if rs := (Points[int]{X: []int{1}, Y: []int{2}}).Rs(); rs != nil {
+		t.Errorf("expected no radii, got %v", rs)
+	}
+}
+
+type flow struct {
+	from, to string
+	flow     float64
+}
+
+var sankey, sankeyErr = RegisterChartType("sankey", func(d Dataset) ([]byte, error) {
+	flows := d.Data.([]flow)
+	out := make([]map[string]interface{}, len(flows))
+	for i, f := range flows {
+		out[i] = map[string]interface{}{"from": f.from, "to": f.to, "flow": f.flow}
+	}
+	return json.Marshal(out)
+}, "https://example.com/sankey-deps.js", "https://example.com/sankey.js")
+
+func TestRegisterChartType(t *testing.T) {
+	if sankeyErr != nil {
+		t.Fatal(sankeyErr)
+	}
+	if _, err := RegisterChartType("sankey", nil); err == nil {
+		t.Error("expected an error for a duplicate chart type")
+	}
+	if _, err := RegisterChartType("bar", nil); err == nil {
+		t.Error("expected an error for a built-in chart type")
+	}
+	if _, err := RegisterChartType("zoom", nil, "https://example.com/zoom-chart.js"); err == nil || Plugins["zoom"].Src == "https://example.com/zoom-chart.js" {
+		t.Error("expected an error for the name of a plugin")
+	}
+	chart := Chart{Type: sankey}
+	chart.AddDataset(Dataset{Label: "energy", Data: []flow{{"coal", "power", 10}}})
+	b, err := json.Marshal(chart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"type":"sankey"`, `"data":[{"flow":10,"from":"coal","to":"power"}]`} {
+		if !strings.Contains(string(b), want) {
+			t.Errorf("expected %s in %s", want, b)
+		}
+	}
+	var buf bytes.Buffer
+	if err := chart.SaveHTML(&buf, RenderOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	s := buf.String()
+	deps, main := strings.Index(s, "sankey-deps.js"), strings.Index(s, "/sankey.js")
+	if deps < 0 || main < deps {
+		t.Errorf("expected the scripts in order, got %s", s)
+	}
+}
+
+type labeled struct {
+	xy
+	labels []string
+}
+
+func (v labeled) Labels() []string { return v.labels }
+
+func TestLabeledValues(t *testing.T) {
+	chart := Chart{Type: Bar}
+	chart.Data.Datasets = []Dataset{
+		{Label: "a", Data: labeled{xy{x: []float64{1, 2}}, []string{"x", "y"}}},
+		{Label: "b", Data: Floats([]float64{3, 4})},
+	}
+	b, err := json.Marshal(chart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), `"labels":["x","y"]`) {
+		t.Errorf("expected labels from the values, got %s", b)
+	}
+
+	chart.Data.Datasets = append(chart.Data.Datasets, Dataset{Label: "c", Data: labeled{xy{x: []float64{5, 6}}, []string{"y", "x"}}})
+	if _, err := json.Marshal(chart); err == nil || !strings.Contains(err.Error(), `dataset "c"`) {
+		t.Errorf("expected an error for mismatched labels, got %v", err)
+	}
+	chart.Data.Datasets = chart.Data.Datasets[:1]
+	chart.Data.Labels = []string{"x", "z"}
+	if _, err := json.Marshal(chart); err == nil {
+		t.Error("expected an error for labels differing from the chart's")
+	}
+	chart.Data.Labels = nil
+	chart.Data.Datasets[0].Data = labeled{xy{x: []float64{1}}, []string{"x", "y"}}
+	if _, err := json.Marshal(chart); err == nil {
+		t.Error("expected an error for a label count mismatch")
+	}
+}
+
+type csvEncoder struct{}
+
+func (csvEncoder) ContentType() string { return "text/csv" }
+
+func (csvEncoder) Encode(w io.Writer, c Chart) error {
+	for _, d := range c.Data.Datasets {
+		fmt.Fprintf(w, "%s,%v\n", d.Label, d.Data.(Values).Xs())
+	}
+	return nil
+}
+
+func TestEncoders(t *testing.T) {
+	chart := &Chart{Type: Line}
+	chart.Options.OnClick = "function() {}"
+	chart.AddDataset(Dataset{Label: "a", Data: Floats([]float64{1})})
+
+	var buf bytes.Buffer
+	if err := (ModuleEncoder{}).Encode(&buf, *chart); err != nil {
+		t.Fatal(err)
+	}
+	if s := buf.String(); !strings.HasPrefix(s, `export default {"type":"line"`) || !strings.Contains(s, `"onClick":function() {}`) {
+		t.Errorf("unexpected module %s", s)
+	}
+	buf.Reset()
+	if err := (JSONEncoder{Indent: "  "}).Encode(&buf, *chart); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), "{\n  \"type\": \"line\"") {
+		t.Errorf("unexpected indented JSON %s", buf.String())
+	}
+	// plain JSON doesn't depend on the process.
+	b, err := json.Marshal(chart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := string(b); strings.Contains(s, jsTagPrefix) || !strings.Contains(s, `"onClick":"function() {}"`) {
+		t.Errorf("expected the code without its tag, got %s", s)
+	}
+	for _, e := range []Encoder{JSONEncoder{}, JSONEncoder{Indent: "  "}, MsgPackEncoder{}, CBOREncoder{}} {
+		buf.Reset()
+		if err := e.Encode(&buf, *chart); err != nil {
+			t.Fatal(err)
+		}
+		if s := buf.String(); strings.Contains(s, jsTagPrefix) || !strings.Contains(s, "function() {}") {
+			t.Errorf("%T: expected the code without its tag, got %q", e, s)
+		}
+	}
+	rec := httptest.NewRecorder()
+	DrillDownHandler(func([]string) (*Chart, error) { return chart, nil }).ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if s := rec.Body.String(); strings.Contains(s, jsTagPrefix) || !strings.Contains(s, `"onClick":function() {}`) {
+		t.Errorf("expected the code without its tag, got %s", s)
+	}
+
+	Encoders["csv"] = csvEncoder{}
+	defer delete(Encoders, "csv")
+	h := Handler(chart)
+	for path, want := range map[string]string{
+		"/c/data.csv":  "text/csv",
+		"/c/data.mjs":  "text/javascript",
+		"/c/data.json": "application/json",
+	} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		if got := rec.Header().Get("Content-Type"); rec.Code != 200 || got != want {
+			t.Errorf("%s: expected %s, got %d %s", path, want, rec.Code, got)
+		}
+	}
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/c/data.xml", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected not found for an unknown format, got %d", rec.Code)
+	}
+}
+
+func TestJSStripper(t *testing.T) {
+	b, err := json.Marshal(map[string]interface{}{"a": JSFunc("f"), "b": jsTag[:5], "c": JSFunc("g")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"a":"f","b":"` + jsTag[:5] + `","c":"g"}`
+	if got := string(StripJS(b)); got != want {
+		t.Errorf("StripJS: got %s, want %s", got, want)
+	}
+	// write byte by byte, so that tags span writes.
+	var buf bytes.Buffer
+	s := &jsStripper{w: &buf}
+	for i := range b {
+		s.Write(b[i : i+1])
+	}
+	if err := s.Flush(); err != nil || buf.String() != want {
+		t.Errorf("jsStripper: got %s %v, want %s", buf.String(), err, want)
+	}
+}
+
+func TestBinaryEncoders(t *testing.T) {
+	ys := make([]float64, 500)
+	for i := range ys {
+		ys[i] = float64(i*i - 1000)
+	}
+	ys[1] = 0.5
+	ys[2] = 1.1
+	chart := Chart{Type: Bar}
+	chart.AddDataset(Dataset{Label: "a", Data: Floats(ys), Fill: types.False})
+
+	var js bytes.Buffer
+	if err := (JSONEncoder{}).Encode(&js, chart); err != nil {
+		t.Fatal(err)
+	}
+	var want interface{}
+	if err := json.Unmarshal(js.Bytes(), &want); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		e      Encoder
+		decode func([]byte) ([]byte, error)
+	}{
+		{MsgPackEncoder{}, DecodeMsgPack},
+		{CBOREncoder{}, DecodeCBOR},
+	} {
+		var buf bytes.Buffer
+		if err := c.e.Encode(&buf, chart); err != nil {
+			t.Fatal(err)
+		}
+		if buf.Len() >= js.Len()/2 {
+			t.Errorf("%T: expected less than half of %d JSON bytes, got %d", c.e, js.Len(), buf.Len())
+		}
+		b, err := c.decode(buf.Bytes())
+		if err != nil {
+			t.Fatalf("%T: %v", c.e, err)
+		}
+		var got interface{}
+		if err := json.Unmarshal(b, &got); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%T: round trip changed the config to %s", c.e, b)
+		}
+		if _, err := c.decode(buf.Bytes()[:buf.Len()-1]); err == nil {
+			t.Errorf("%T: expected an error for truncated input", c.e)
+		}
+		if _, err := c.decode(append(buf.Bytes(), 0)); err == nil {
+			t.Errorf("%T: expected an error for trailing bytes", c.e)
+		}
+	}
+	// arrays of one array, nested a million times.
+	for _, c := range []struct {
+		decode func([]byte) ([]byte, error)
+		array  byte
+	}{{DecodeMsgPack, 0x91}, {DecodeCBOR, 0x81}} {
+		deep := append(bytes.Repeat([]byte{c.array}, 1e6), 0)
+		if _, err := c.decode(deep); err == nil {
+			t.Errorf("expected an error for deeply nested arrays")
+		}
+	}
+	if e, ok := EncoderFor("data.cbor"); !ok || e.ContentType() != "application/cbor" {
+		t.Errorf("expected the CBOR encoder for .cbor, got %v", e)
+	}
+}
+
+type failWriter struct{ n int }
+
+func (w *failWriter) Write(p []byte) (int, error) {
+	if w.n < len(p) {
+		return 0, fmt.Errorf("disk full")
+	}
+	w.n -= len(p)
+	return len(p), nil
+}
+
+func TestWriteJSON(t *testing.T) {
+	ys := make([]float64, 10000)
+	for i := range ys {
+		ys[i] = float64(i)
+	}
+	ys[3] = math.NaN()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	times := TimeSeries{Time: []time.Time{start, start.Add(time.Hour)}, Value: []float64{1, 2}}
+
+	chart := Chart{Type: Line}
+	chart.Options.OnClick = "function() { return '<b>'; }"
+	chart.AddDataset(Dataset{Label: "a<b>", Data: Floats(ys), BackgroundColors: []types.RGBA{{R: 1}}})
+	chart.AddDataset(Dataset{Label: "b", Data: xy{x: []float64{1, 2}, y: []float64{3, math.Inf(1)}}, NaNPolicy: NaNSkip})
+	chart.AddDataset(Dataset{Label: "c", Data: times, TimeFormat: time.RFC3339})
+	chart.AddDataset(Dataset{Label: "d", Data: metaXY{xy: xy{x: []float64{1}, y: []float64{2}}, meta: []map[string]string{{"k": "<m>"}}}})
+	chart.AddDataset(Dataset{Label: "e", Data: json.RawMessage(`[ 1, 2 ]`)})
+	chart.Data.LabelLines = [][]string{{"x", "y"}}
+
+	for _, c := range []Chart{chart, {Type: Bar}, func() Chart { c := chart; c.TargetVersion = V3; return c }()} {
+		want, err := c.MarshalJSON()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if err := c.WriteJSON(&buf); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != string(want) {
+			t.Errorf("expected %.200s..., got %.200s...", want, buf.String())
+		}
+	}
+
+	bad := chart
+	bad.Data.Datasets = []Dataset{{Data: xy{x: []float64{1}, y: []float64{1, 2}}}}
+	if err := bad.WriteJSON(io.Discard); err == nil {
+		t.Error("expected an error for bad values")
+	}
+	if err := chart.WriteJSON(&failWriter{n: 10000}); err == nil || err.Error() != "disk full" {
+		t.Errorf("expected the write error, got %v", err)
+	}
+}
+
+// decimal mimics decimal.Decimal of github.com/shopspring/decimal.
+type decimal string
+
+func (d decimal) String() string { return string(d) }
+
+func (d decimal) Float64() (float64, bool) {
+	f, err := strconv.ParseFloat(string(d), 64)
+	return f, err == nil
+}
+
+func TestExactValues(t *testing.T) {
+	sum := new(big.Float).SetPrec(200)
+	a, _ := new(big.Float).SetPrec(200).SetString("0.1")
+	b, _ := new(big.Float).SetPrec(200).SetString("0.2")
+	sum.Add(a, b)
+	huge, _ := new(big.Float).SetPrec(200).SetString("12345678901234567890.123456789")
+	chart := Chart{Type: Line, Data: Data{Labels: []string{"a", "b", "c"}}}
+	chart.AddDataset(Dataset{Data: BigFloats([]*big.Float{sum, huge, nil})})
+	chart.AddDataset(Dataset{Data: Decimals([]decimal{"1.005", "-0.000000001", "1e400"})})
+	chart.AddDataset(Dataset{Data: DecimalXY([]decimal{"1"}, []decimal{"99.995"})})
+	buf, err := json.Marshal(chart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`"data":[0.3,1.2345678901234567890123456789e+19,null]`,
+		`"data":[1.005,-0.000000001,1e400]`,
+		`"data":[{"x":1,"y":99.995}]`,
+	} {
+		if !strings.Contains(string(buf), want) {
+			t.Errorf("expected %s in %s", want, buf)
+		}
+	}
+	v := chart.Data.Datasets[0].Data.(Values)
+	if xs := v.Xs(); xs[0] != 0.3 || !math.IsNaN(xs[2]) {
+		t.Errorf("unexpected float values %v", xs)
+	}
+
+	chart.Data.Datasets = []Dataset{{Data: Decimals([]decimal{"1,5"})}}
+	if _, err := json.Marshal(chart); err == nil {
+		t.Error("expected an error for a decimal that is not a JSON number")
+	}
+}
+
+func TestNaNPolicy(t *testing.T) {
+	nan, inf := math.NaN(), math.Inf(1)
+	points := xy{x: []float64{1, nan, 3, 4}, y: []float64{1, 2, inf, 4}, r: []float64{1, 1, 1, nan}}
+	series := xy{x: []float64{1, nan, 3}}
+	for _, c := range []struct {
+		policy         nanPolicy
+		points, series string
+	}{
+		{NaNNull, `[{"x":1.00,"y":1.00,"r":1.00},{"x":null,"y":2.00,"r":1.00},{"x":3.00,"y":null,"r":1.00},{"x":4.00,"y":4.00,"r":null}]`, `[1.00,null,3.00]`},
+		{NaNSkip, `[{"x":1.00,"y":1.00,"r":1.00}]`, `[1.00,null,3.00]`},
+		{NaNZeroFill, `[{"x":1.00,"y":1.00,"r":1.00},{"x":0.00,"y":2.00,"r":1.00},{"x":3.00,"y":0.00,"r":1.00},{"x":4.00,"y":4.00,"r":0.00}]`, `[1.00,0.00,3.00]`},
+	} {
+		b, err := json.Marshal(Dataset{Data: points, NaNPolicy: c.policy})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(b), `"data":`+c.points) {
+			t.Errorf("policy %d: expected %s in %s", c.policy, c.points, b)
+		}
+		b, err = json.Marshal(Dataset{Data: series, NaNPolicy: c.policy})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(b), `"data":`+c.series) {
+			t.Errorf("policy %d: expected %s in %s", c.policy, c.series, b)
+		}
+	}
+	if _, err := json.Marshal(Dataset{Label: "p", Data: points, NaNPolicy: NaNError}); err == nil {
+		t.Error("expected an error for NaN values")
+	}
+
+	meta := metaXY{xy{x: []float64{1, 2}, y: []float64{nan, 3}}, []map[string]string{{"id": "a"}, {"id": "b"}}}
+	b, err := json.Marshal(Dataset{Data: meta, NaNPolicy: NaNSkip})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), `"data":[{"x":2.00,"y":3.00,"meta":{"id":"b"}}]`) {
+		t.Errorf("expected the metadata of the kept points, got %s", b)
+	}
+}
+
+func TestCurrency(t *testing.T) {
+	chart := Chart{Type: Bar}
+	chart.Options.Scales = map[string]Axis{
+		"y": {Type: Linear, Currency: &Currency{Code: "EUR", Locale: "de-DE", MinorUnits: true}},
+	}
+	chart.AddDataset(Dataset{Label: "revenue", Data: Floats([]float64{1050, 99})})
+	chart.AddDataset(Dataset{Label: "orders", YAxisID: "y", Data: Floats([]float64{3, 4}),
+		Currency: &Currency{Code: "JPY"}})
+	b, err := chart.MarshalTagged()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`"data":[10.50,0.99]`,
+		`"data":[3,4]`,
+		`"ticks":{"callback":"` + jsTagPrefix,
+		`function(value) { return new Intl.NumberFormat(\"de-DE\", {style: 'currency', currency: 'EUR'}).format(value); }`,
+		`"tooltip":{"callbacks":{"label":"` + jsTagPrefix,
+		`new Intl.NumberFormat(undefined, {style: 'currency', currency: 'JPY'})`,
+	} {
+		if !strings.Contains(string(b), want) {
+			t.Errorf("expected %s in %s", want, b)
+		}
+	}
+	if chart.Options.Scales["y"].Tick != nil {
+		t.Error("marshaling changed the axis")
+	}
+
+	chart.Data.Datasets[1].Currency = &Currency{Code: "yen"}
+	if _, err := json.Marshal(chart); err == nil {
+		t.Error("expected an error for a bad currency code")
+	}
+	chart.Data.Datasets[1].Currency = nil
+	chart.Data.Datasets[1].Data = []int{1}
+	if _, err := json.Marshal(chart); err == nil {
+		t.Error("expected an error for minor units of data other than Values")
+	}
+}
+
+func TestLegend(t *testing.T) {
+	chart := Chart{Type: Bar}
+	chart.AddDataset(Dataset{Label: "a", Data: Floats([]float64{1})})
+	chart.Options.Legend = &Legend{
+		Position: Right,
+		Align:    AlignStart,
+		Reverse:  True,
+		Labels: &LegendLabels{
+			BoxWidth:      12,
+			Font:          &Font{Family: "Inter", Size: 14, Weight: "bold"},
+			UsePointStyle: True,
+			Filter:        "function(item) { return item.text !== 'total'; }",
+		},
+		OnClick: "function(e, item, legend) {}",
+	}
+	var buf bytes.Buffer
+	if err := chart.SaveHTML(&buf, RenderOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`"legend":{"position":"right","align":"start","reverse":true,"labels":{"boxWidth":12,"font":{"family":"Inter","size":14,"weight":"bold"},"usePointStyle":true,"filter":function(item) { return item.text !== 'total'; }},"onClick":function(e, item, legend) {}}`,
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("expected %s in the page", want)
+		}
+	}
+
+	b, err := json.Marshal(chart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Chart
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got.Options.Legend, chart.Options.Legend) {
+		t.Errorf("unexpected legend %+v", got.Options.Legend)
+	}
+}
+
+func TestAnimation(t *testing.T) {
+	chart := Chart{Type: Line}
+	chart.AddDataset(Dataset{Data: Floats([]float64{1})})
+	one := 1000
+	chart.Options.Animation = Animation{Duration: 500, Easing: "easeOutQuart", Delay: 100}
+	chart.Options.Animations = map[string]PropertyAnimation{
+		"tension": {Duration: &one, Easing: "linear", From: 1, To: 0, Loop: True},
+	}
+	b, err := json.Marshal(chart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `"animation":{"duration":500,"easing":"easeOutQuart","delay":100},"animations":{"tension":{"duration":1000,"easing":"linear","loop":true,"from":1,"to":0}}`
+	if !strings.Contains(string(b), want) {
+		t.Errorf("expected %s in %s", want, b)
+	}
+
+	chart.DisableAnimation()
+	if b, err = json.Marshal(chart); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), `"animation":false`) || strings.Contains(string(b), `"animations"`) {
+		t.Errorf("expected animation to be disabled in %s", b)
+	}
+	var got Chart
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !got.Options.Animation.Disabled {
+		t.Error("expected a disabled animation after unmarshaling")
+	}
+}
+
+func TestPersonalize(t *testing.T) {
+	chart := &Chart{Type: Bar, Data: Data{Labels: []string{"a"}}}
+	chart.AddDataset(Dataset{Label: "acme", Data: Floats([]float64{1})})
+	chart.AddDataset(Dataset{Label: "globex", Data: Floats([]float64{2})})
+	p := func(r *http.Request, c *Chart) *Chart {
+		tenant := r.Header.Get("X-Tenant")
+		if tenant == "" {
+			return nil
+		}
+		var datasets []Dataset
+		for _, d := range c.Data.Datasets {
+			if d.Label == tenant {
+				datasets = append(datasets, d)
+			}
+		}
+		c.Data.Datasets = datasets
+		return c
+	}
+
+	reg := NewRegistry()
+	reg.Personalize = p
+	if err := reg.Register("sales", chart); err != nil {
+		t.Fatal(err)
+	}
+	for _, h := range []struct {
+		handler http.Handler
+		path    string
+	}{{PersonalizedHandler(chart, p), "/sales/data.json"}, {reg, "/sales"}} {
+		req := httptest.NewRequest("GET", h.path, nil)
+		req.Header.Set("X-Tenant", "acme")
+		rec := httptest.NewRecorder()
+		h.handler.ServeHTTP(rec, req)
+		if rec.Code != 200 || !strings.Contains(rec.Body.String(), "acme") || strings.Contains(rec.Body.String(), "globex") {
+			t.Errorf("%s: expected only the acme dataset, got %d %s", h.path, rec.Code, rec.Body)
+		}
+
+		rec = httptest.NewRecorder()
+		h.handler.ServeHTTP(rec, httptest.NewRequest("GET", h.path, nil))
+		if rec.Code != 404 {
+			t.Errorf("%s: expected 404 without a tenant, got %d", h.path, rec.Code)
+		}
+	}
+	if len(chart.Data.Datasets) != 2 {
+		t.Error("personalizing changed the chart")
+	}
+
+	req := httptest.NewRequest("GET", "/sales/og.png", nil)
+	req.Header.Set("X-Tenant", "acme")
+	rec := httptest.NewRecorder()
+	reg.ServeHTTP(rec, req)
+	if cc := rec.Header().Get("Cache-Control"); !strings.HasPrefix(cc, "private") {
+		t.Errorf("expected a private image, got %q", cc)
+	}
+}
+
+func TestDataCache(t *testing.T) {
+	ys := []float64{1, 2}
+	chart := Chart{Type: Bar}
+	chart.AddDataset(Dataset{Label: "a", Data: Floats(ys), Cache: &DataCache{}})
+	marshal := func() string {
+		t.Helper()
+		b, err := json.Marshal(chart)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+	if s := marshal(); !strings.Contains(s, `"data":[1.00,2.00]`) {
+		t.Fatalf("unexpected config %s", s)
+	}
+	ys[0] = 5
+	chart.Options.IndexAxis = "y"
+	if s := marshal(); !strings.Contains(s, `"data":[1.00,2.00]`) || !strings.Contains(s, `"indexAxis":"y"`) {
+		t.Errorf("expected the cached data with the new options, got %s", s)
+	}
+	chart.Data.Datasets[0].Touch()
+	if s := marshal(); !strings.Contains(s, `"data":[5.00,2.00]`) {
+		t.Errorf("expected the data after Touch, got %s", s)
+	}
+	chart.Data.Datasets[0].XFloatFormat = "%.0f"
+	if s := marshal(); !strings.Contains(s, `"data":[5,2]`) {
+		t.Errorf("expected the data in the new format, got %s", s)
+	}
+	if v := chart.Data.Datasets[0].Cache.Version(); v != 1 {
+		t.Errorf("unexpected version %d", v)
+	}
+
+	chart.Redactor = func(label string, v float64) (string, float64) { return "redacted", 0 }
+	if s := marshal(); !strings.Contains(s, `"data":[0,0]`) {
+		t.Errorf("expected redacted data, got %s", s)
+	}
+}
+
+func TestDataCachePersonalize(t *testing.T) {
+	chart := &Chart{Type: Bar, Data: Data{Labels: []string{"acme", "globex"}}}
+	chart.AddDataset(Dataset{Label: "sales", Data: Floats([]float64{1, 2}), Cache: &DataCache{}, XFloatFormat: "%.0f"})
+	p := func(r *http.Request, c *Chart) *Chart {
+		tenant := r.Header.Get("X-Tenant")
+		d := c.Data.Datasets[0]
+		ys := make([]float64, len(c.Data.Labels))
+		for i, label := range c.Data.Labels {
+			if label == tenant {
+				ys[i] = d.Data.(Values).Xs()[i]
+			}
+		}
+		d.Data = Floats(ys)
+		c.Data.Datasets = []Dataset{d}
+		return c
+	}
+	h := PersonalizedHandler(chart, p)
+	for _, tc := range []struct{ tenant, want string }{
+		{"globex", `"data":[0,2]`},
+		{"acme", `"data":[1,0]`},
+		{"acme", `"data":[1,0]`},
+	} {
+		req := httptest.NewRequest("GET", "/sales/data.json", nil)
+		req.Header.Set("X-Tenant", tc.tenant)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if !strings.Contains(rec.Body.String(), tc.want) {
+			t.Errorf("%s: expected %s, got %s", tc.tenant, tc.want, rec.Body)
+		}
+	}
+}
+
+func TestChartPool(t *testing.T) {
+	c := AcquireChart()
+	c.Type = Bar
+	c.Data.Labels = append(c.Data.Labels, "a")
+	c.AddDataset(Dataset{Label: "a", Data: Floats([]float64{1})})
+	c.AddAxis(Axis{Type: Linear, ID: "y"})
+	c.Reset()
+	if c.Type != unsetType || len(c.Data.Datasets) != 0 || cap(c.Data.Datasets) == 0 || len(c.Options.Scales) != 0 || c.Options.Scales == nil {
+		t.Errorf("unexpected chart after Reset: %+v", c)
+	}
+
+	c.AddDataset(Dataset{Label: "b", Data: Floats([]float64{2})})
+	b, err := json.Marshal(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fresh := Chart{}
+	fresh.AddDataset(Dataset{Label: "b", Data: Floats([]float64{2})})
+	want, err := json.Marshal(fresh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != string(want) {
+		t.Errorf("got %s, want %s", b, want)
+	}
+	ReleaseChart(c)
+	if c := AcquireChart(); len(c.Data.Datasets) != 0 {
+		t.Errorf("expected an empty chart from the pool")
+	}
+
+	v := FromMap(map[string]float64{"a": 1, "b": 2})
+	c = AcquireChart()
+	c.AddDataset(Dataset{Data: v})
+	ReleaseChart(c)
+	if labels := v.(LabeledValues).Labels(); !reflect.DeepEqual(labels, []string{"a", "b"}) {
+		t.Errorf("expected the labels of the values to be kept, got %q", labels)
+	}
+}
+
+func TestTitle(t *testing.T) {
+	chart := Chart{Type: Line}
+	chart.AddDataset(Dataset{Data: Floats([]float64{1})})
+	padding := 0
+	chart.Options.Title = &Title{Display: True, Text: "Revenue", Position: Left, Align: AlignEnd,
+		Font: &Font{Size: 18, Weight: "bold"}, Color: &types.RGBA{R: 255, A: 255}, Padding: &padding}
+	chart.Options.Subtitle = &Title{Display: True, TextLines: []string{"by region", "2024"}}
+	b, err := json.Marshal(chart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`"title":{"display":true,"text":"Revenue","position":"left","align":"end","font":{"size":18,"weight":"bold"},"color":"rgba(255, 0, 0, 1.000)","padding":0}`,
+		`"subtitle":{"display":true,"text":["by region","2024"]}`,
+	} {
+		if !strings.Contains(string(b), want) {
+			t.Errorf("expected %s in %s", want, b)
+		}
+	}
+	var got Chart
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got.Options.Option, chart.Options.Option) {
+		t.Errorf("got %+v, want %+v", got.Options.Option, chart.Options.Option)
+	}
+}
+
+func TestGrid(t *testing.T) {
+	chart := Chart{Type: Line}
+	chart.AddDataset(Dataset{Data: Floats([]float64{1})})
+	chart.AddAxis(Axis{ID: "x", Type: Category, GridLines: False})
+	chart.AddAxis(Axis{ID: "y", Type: Linear, GridLines: False, Grid: &Grid{
+		Display: True, Color: &types.RGBA{A: 255}, LineWidth: 0.5, DrawOnChartArea: False,
+		BorderDash: []float64{4, 2}, TickLength: 8,
+	}})
+	b, err := json.Marshal(chart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`"x":{"type":"category","grid":{"display":false}`,
+		`"y":{"type":"linear","grid":{"display":true,"color":"rgba(0, 0, 0, 1.000)","lineWidth":0.5,"drawOnChartArea":false,"borderDash":[4,2],"tickLength":8}`,
+	} {
+		if !strings.Contains(string(b), want) {
+			t.Errorf("expected %s in %s", want, b)
+		}
+	}
+
+	chart.TargetVersion = V2
+	if b, err = json.Marshal(chart); err != nil {
+		t.Fatal(err)
+	}
+	want := `"gridLines":{"borderDash":[4,2],"color":"rgba(0, 0, 0, 1.000)","display":true,"drawOnChartArea":false,"lineWidth":0.5,"tickMarkLength":8}`
+	if !strings.Contains(string(b), want) {
+		t.Errorf("expected %s in %s", want, b)
+	}
+}
+
+func TestAppendJSONString(t *testing.T) {
+	for _, s := range []string{"", "cpu", `a "quoted" \ label`, "<b>&</b>", "tab\there\nnew\r", "\x00\x1f\b\f", "日本語 ✓", "\u2028\u2029", "bad \xff utf8"} {
+		want, err := json.Marshal(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := appendJSONString(nil, s)
+		var a, b string
+		if err := json.Unmarshal(got, &a); err != nil {
+			t.Fatalf("%q: %v", got, err)
+		}
+		json.Unmarshal(want, &b)
+		if a != b || bytes.ContainsAny(got, "<>&\u2028\u2029") {
+			t.Errorf("got %s, want %s", got, want)
+		}
+	}
+}
+
+func TestFloatFormat(t *testing.T) {
+	for _, format := range []string{"%.2f", "%f", "%.0f", "%.f", "%g", "%.3g", "%e", "%.1e", "%5.2f", "%v", "%x"} {
+		f := parseFloatFormat(format)
+		for _, v := range []float64{0, math.Copysign(0, -1), 1.005, -2.5, 1e21, 123456789.125, 1e-7} {
+			got, err := f.append(nil, v)
+			want := fmt.Sprintf(format, v)
+			if err != nil {
+				if validNumber(want) {
+					t.Errorf("%s of %v: %v", format, v, err)
+				}
+				continue
+			}
+			if string(got) != want {
+				t.Errorf("%s of %v: got %s, want %s", format, v, got, want)
+			}
+		}
+	}
+}
+
+// TestWriteAllocs checks that writing points and labels allocates the same
+// for any number of them.
+func TestWriteAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("sync.Pool drops items under the race detector")
+	}
+	allocs := func(n int) float64 {
+		xs, ys := make([]float64, n), make([]float64, n)
+		ts := make([]time.Time, n)
+		labels := make([]string, n)
+		for i := range xs {
+			xs[i], ys[i] = float64(i), float64(i)/3
+			ts[i] = time.Unix(int64(i), 0)
+			labels[i] = fmt.Sprintf("label <%d>", i)
+		}
+		var buf bytes.Buffer
+		buf.Grow(200 * n)
+		return testing.AllocsPerRun(10, func() {
+			buf.Reset()
+			writeValuesJSON(&buf, xy{x: xs, y: ys}, "%.2f", "%.3f")
+			writeTimeValuesJSON(&buf, TimeSeries{Time: ts, Value: ys}, time.RFC3339, time.UTC, "%.2f")
+			writeStringsJSON(&buf, labels)
+		})
+	}
+	if a, b := allocs(10), allocs(1000); a != b {
+		t.Errorf("got %v allocations for 10 points and %v for 1000", a, b)
+	}
+}
+
+func BenchmarkWriteValuesJSON(b *testing.B) {
+	xs, ys := make([]float64, 10000), make([]float64, 10000)
+	for i := range xs {
+		xs[i], ys[i] = float64(i), float64(i)/3
+	}
+	var buf bytes.Buffer
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		if err := writeValuesJSON(&buf, xy{x: xs, y: ys}, "%.2f", "%.2f"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkWriteLabels(b *testing.B) {
+	labels := make([]string, 10000)
+	for i := range labels {
+		labels[i] = fmt.Sprintf("host-%d.example.com", i)
+	}
+	var buf bytes.Buffer
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		if err := writeStringsJSON(&buf, labels); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkMarshalChart(b *testing.B) {
+	labels := make([]string, 1000)
+	ys := make([]float64, 1000)
+	for i := range labels {
+		labels[i] = fmt.Sprintf("day %d", i)
+		ys[i] = float64(i)
+	}
+	chart := Chart{Type: Bar, Data: Data{Labels: labels}}
+	chart.AddDataset(Dataset{Label: "a", Data: Floats(ys)})
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := chart.WriteJSON(io.Discard); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestTickOptions(t *testing.T) {
+	chart := Chart{Type: Line}
+	chart.AddDataset(Dataset{Data: Floats([]float64{1})})
+	padding, rotation := 4, 0
+	chart.AddAxis(Axis{ID: "x", Type: Category, Tick: &Tick{
+		MaxTicksLimit: 8, AutoSkip: True, AutoSkipPadding: 10, MaxRotation: &rotation, Padding: &padding,
+		Font: &Font{Family: "Inter", Size: 11, Weight: "bold"}, Color: &types.RGBA{A: 255},
+		Callback: "function(value) { return this.getLabelForValue(value).slice(0, 3); }",
+	}})
+	var buf bytes.Buffer
+	if err := chart.SaveHTML(&buf, RenderOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	want := `"ticks":{"callback":function(value) { return this.getLabelForValue(value).slice(0, 3); },"maxRotation":0,"maxTicksLimit":8,"autoSkip":true,"autoSkipPadding":10,"padding":4,"font":{"family":"Inter","size":11,"weight":"bold"},"color":"rgba(0, 0, 0, 1.000)"}`
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("expected %s in the page", want)
+	}
+
+	chart.TargetVersion = V2
+	b, err := json.Marshal(chart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `"fontColor":"rgba(0, 0, 0, 1.000)","fontFamily":"Inter","fontSize":11,"fontStyle":"bold"`; !strings.Contains(string(b), want) {
+		t.Errorf("expected %s in %s", want, b)
+	}
+}
+
+func TestCheckFloatFormat(t *testing.T) {
+	for format, ok := range map[string]bool{
+		"%.2f": true, Precision(0): true, "%g": true, "%.3e": true, "%v": true,
+		"%d": false, "%s": false, "%5.2f": false, "%x": false, "%.2f%%": false, "abc": false, "": false,
+	} {
+		if err := CheckFloatFormat(format); (err == nil) != ok {
+			t.Errorf("%q: unexpected error %v", format, err)
+		}
+	}
+	if Precision(3) != "%.3f" {
+		t.Errorf("unexpected precision %s", Precision(3))
+	}
+
+	defer func(x, y string) { XFloatFormat, YFloatFormat = x, y }(XFloatFormat, YFloatFormat)
+	if err := SetFloatFormats("%.1f", "%d"); err == nil || XFloatFormat != "%.2f" {
+		t.Errorf("expected an error and the formats unchanged, got %v %s", err, XFloatFormat)
+	}
+	if err := SetFloatFormats("%.1f", "%g"); err != nil || XFloatFormat != "%.1f" || YFloatFormat != "%g" {
+		t.Errorf("unexpected formats %s %s %v", XFloatFormat, YFloatFormat, err)
+	}
+
+	chart := Chart{Type: Bar, Data: Data{Labels: []string{"a"}}}
+	chart.AddDataset(Dataset{Label: "a", Data: Floats([]float64{1}), XFloatFormat: "%s"})
+	if _, err := json.Marshal(chart); err == nil || !strings.Contains(err.Error(), `"%s"`) {
+		t.Errorf("expected an error for the format, got %v", err)
+	}
+	if err := chart.Validate(); err == nil {
+		t.Error("expected Validate to report the format")
+	}
+}
+
+func TestAutoTitleAxes(t *testing.T) {
+	chart := Chart{Type: Line, AutoTitleAxes: true}
+	chart.AddDataset(Dataset{Label: "api", Unit: "Requests/s", Data: TimeSeries{Time: []time.Time{time.Unix(0, 0)}, Value: []float64{1}}})
+	chart.AddDataset(Dataset{Label: "web", Unit: "Requests/s", Data: TimeSeries{Time: []time.Time{time.Unix(0, 0)}, Value: []float64{2}}})
+	chart.AddDataset(Dataset{Label: "p99", Unit: "ms", YAxisID: "latency", Data: Floats([]float64{3})})
+	chart.AddDataset(Dataset{Label: "errors", YAxisID: "errors", Data: Floats([]float64{4})})
+	chart.AddAxis(Axis{ID: "x", Type: Time, Adapters: ZoneAdapters(time.UTC)})
+	chart.AddAxis(Axis{ID: "latency", Type: Linear, Position: Right})
+	chart.AddAxis(Axis{ID: "errors", Type: Linear, Position: Right, Title: AxisTitle{Display: true, Text: "Errors/min"}})
+	prepared, err := chart.prepare()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for id, want := range map[string]string{"x": "Time (UTC)", "y": "Requests/s", "latency": "p99 (ms)", "errors": "Errors/min"} {
+		if got := prepared.Options.Scales[id].Title.Text; got != want {
+			t.Errorf("%s: got title %q, want %q", id, got, want)
+		}
+	}
+	if len(chart.Options.Scales) != 3 || chart.Options.Scales["latency"].Title.Text != "" {
+		t.Error("titling changed the chart")
+	}
+}
+
+func TestLegendOverflow(t *testing.T) {
+	chart := Chart{Type: Line}
+	for i := 0; i < 30; i++ {
+		chart.AddDataset(Dataset{Label: fmt.Sprint("host", i), Data: Floats([]float64{1})})
+	}
+	chart.Options.Legend = &Legend{MaxItems: 10, Labels: &LegendLabels{BoxWidth: 8}}
+	prepared, err := chart.prepare()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l := prepared.Options.Legend; !strings.Contains(string(l.Labels.Filter), "return i < 10;") || l.Labels.BoxWidth != 8 {
+		t.Errorf("expected a filter keeping 10 items, got %+v", l.Labels)
+	}
+	if chart.Options.Legend.Labels.Filter != "" {
+		t.Error("capping changed the chart")
+	}
+
+	chart.Options.Legend.HideAbove = 20
+	if prepared, err = chart.prepare(); err != nil {
+		t.Fatal(err)
+	}
+	if l := prepared.Options.Legend; l.Display == nil || *l.Display {
+		t.Error("expected the legend to be hidden")
+	}
+
+	pie := Chart{Type: Doughnut, Data: Data{Labels: []string{"a", "b", "c"}}}
+	pie.AddDataset(Dataset{Data: Floats([]float64{1, 2, 3})})
+	pie.Options.Legend = &Legend{HideAbove: 2}
+	if prepared, err = pie.prepare(); err != nil {
+		t.Fatal(err)
+	}
+	if l := prepared.Options.Legend; l.Display == nil || *l.Display {
+		t.Error("expected the legend of 3 labels to be hidden")
+	}
+}
+
+func TestEnableStacking(t *testing.T) {
+	chart := Chart{Type: Bar, Data: Data{Labels: []string{"q1", "q2"}}}
+	chart.AddDataset(Dataset{Label: "eu", Stack: "2024", Data: Floats([]float64{1, 2})})
+	chart.AddDataset(Dataset{Label: "us", Stack: "2024", Data: Floats([]float64{3, 4})})
+	chart.AddDataset(Dataset{Label: "eu", Stack: "2023", YAxisID: "y2", Data: Floats([]float64{5, 6})})
+	chart.AddAxis(Axis{ID: "y2", Type: Linear, Position: Right})
+	chart.EnableStacking()
+	for id, typ := range map[string]axisType{"x": Category, "y": Linear, "y2": Linear} {
+		a, ok := chart.Options.Scales[id]
+		if !ok || a.Stacked == nil || !*a.Stacked || a.Type != typ {
+			t.Errorf("%s: expected a stacked %s axis, got %+v", id, axisTypes[typ], a)
+		}
+	}
+	if chart.Options.Scales["y2"].Position != Right {
+		t.Error("expected the axis options to be kept")
+	}
+	b, err := json.Marshal(chart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), `"stack":"2023"`) {
+		t.Errorf("expected the stack of the datasets in %s", b)
+	}
+
+	own := Chart{Type: Bar}
+	own.AddDataset(Dataset{Stack: "a", XAxisID: "x2", YAxisID: "y2", Data: Floats([]float64{1})})
+	own.EnableStacking()
+	if _, ok := own.Options.Scales["x"]; ok || len(own.Options.Scales) != 2 {
+		t.Errorf("expected only the axes of the datasets, got %+v", own.Options.Scales)
+	}
+
+	horizontal := Chart{Type: Bar}
+	horizontal.Options.IndexAxis = "y"
+	horizontal.AddDataset(Dataset{Stack: "a", Data: Floats([]float64{1})})
+	horizontal.EnableStacking()
+	if horizontal.Options.Scales["x"].Type != Linear || horizontal.Options.Scales["y"].Type != Category {
+		t.Errorf("unexpected axes of horizontal bars %+v", horizontal.Options.Scales)
+	}
+}
+
+func TestHTMLLegend(t *testing.T) {
+	chart := Chart{Type: Line}
+	chart.AddDataset(Dataset{Label: "a <very> long series name", Data: Floats([]float64{1})})
+	chart.Options.Legend = &Legend{HTML: true, Position: Bottom}
+	var buf bytes.Buffer
+	if err := chart.SaveHTML(&buf, RenderOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	s := buf.String()
+	for _, want := range []string{
+		`<ul id="legend0" class="chartjs-legend" aria-label="Legend"`,
+		`function chartjsHTMLLegend(chart, index)`,
+		`chartjsHTMLLegend(chart,  0 );`,
+		`"legend":{"display":false,"position":"bottom"}`,
+	} {
+		if !strings.Contains(s, want) {
+			t.Errorf("expected %s in the page", want)
+		}
+	}
+	if chart.Options.Legend.Display != nil {
+		t.Error("rendering changed the chart")
+	}
+
+	chart.Options.Legend = nil
+	buf.Reset()
+	if err := chart.SaveHTML(&buf, RenderOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "chartjsHTMLLegend") {
+		t.Error("expected no HTML legend by default")
+	}
+}
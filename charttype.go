@@ -0,0 +1,94 @@
+package chartjs
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+// DatasetMarshaler returns the JSON of the "data" of a dataset of a
+// registered chart type.
+type DatasetMarshaler func(d Dataset) ([]byte, error)
+
+type customChartType struct {
+	name    string
+	marshal DatasetMarshaler
+}
+
+var (
+	customMu         sync.RWMutex
+	customChartTypes []customChartType
+)
+
+// RegisterChartType adds a chart type provided by a third-party chart.js
+// extension, e.g. "sankey", and returns it for use as Chart.Type or
+// Dataset.Type. marshal encodes the data of its datasets; if nil, data is
+// marshaled as for the built-in types. scripts are the URLs of the
+// extension, loaded in order by SaveCharts, and are added to Plugins under
+// name, which must not name a plugin yet. Types are usually registered from
+// an init function.
+func RegisterChartType(name string, marshal DatasetMarshaler, scripts ...string) (chartType, error) {
+	customMu.Lock()
+	defer customMu.Unlock()
+	for _, n := range chartTypes {
+		if n == name {
+			return 0, fmt.Errorf("chart: chart type %q is built in", name)
+		}
+	}
+	for _, t := range customChartTypes {
+		if t.name == name {
+			return 0, fmt.Errorf("chart: chart type %q already registered", name)
+		}
+	}
+	if len(scripts) > 0 {
+		names := []string{name}
+		for i := range scripts[:len(scripts)-1] {
+			names = append(names, name+":"+strconv.Itoa(i))
+		}
+		for _, n := range names {
+			if _, ok := Plugins[n]; ok {
+				return 0, fmt.Errorf("chart: plugin %q already exists", n)
+			}
+		}
+	}
+	t := chartType(len(chartTypes) + len(customChartTypes))
+	customChartTypes = append(customChartTypes, customChartType{name: name, marshal: marshal})
+	if len(scripts) > 0 {
+		// the scripts before the last are loaded as its dependencies.
+		var deps []string
+		for i, src := range scripts[:len(scripts)-1] {
+			dep := name + ":" + strconv.Itoa(i)
+			Plugins[dep] = Plugin{Src: src}
+			deps = append(deps, dep)
+		}
+		Plugins[name] = Plugin{Src: scripts[len(scripts)-1]}
+		pluginDeps[name] = deps
+		chartTypePlugins[t] = name
+	}
+	return t, nil
+}
+
+// custom returns the registration of a registered chart type.
+func (c chartType) custom() (customChartType, bool) {
+	i := int(c) - len(chartTypes)
+	customMu.RLock()
+	defer customMu.RUnlock()
+	if i < 0 || i >= len(customChartTypes) {
+		return customChartType{}, false
+	}
+	return customChartTypes[i], true
+}
+
+// String returns the chart.js name of the type.
+func (c chartType) String() string {
+	if c == unsetType {
+		return Line.String()
+	}
+	if int(c) < len(chartTypes) {
+		return chartTypes[c]
+	}
+	if t, ok := c.custom(); ok {
+		return t.name
+	}
+	return "chartType(" + strconv.Itoa(int(c)) + ")"
+}
@@ -0,0 +1,33 @@
+package chartjs
+
+// URLClick is an onClick handler that navigates to the URLTemplate of the
+// dataset of the clicked point. The placeholders {x}, {y}, {label} (the
+// dataset label) and {index} are replaced with the URL-escaped values of the
+// point; {x} is the category label for charts without x values.
+const URLClick JSFunc = `function(evt, elements) {
+	var chart = this;
+	var el = chart.getElementAtEvent ? chart.getElementAtEvent(evt)[0] : elements[0];
+	if (!el) { return; }
+	var di = el._datasetIndex !== undefined ? el._datasetIndex : el.datasetIndex;
+	var i = el._index !== undefined ? el._index : el.index;
+	var ds = chart.data.datasets[di];
+	if (!ds.urlTemplate) { return; }
+	var p = ds.data[i];
+	var isObj = p !== null && typeof p === "object";
+	var x = isObj ? p.x : undefined;
+	if (x === undefined) { x = (chart.data.labels || [])[i]; }
+	var vals = {x: x, y: isObj ? p.y : p, label: ds.label, index: i};
+	window.location.href = ds.urlTemplate.replace(/\{(\w+)\}/g, function(m, k) {
+		return k in vals ? encodeURIComponent(vals[k]) : m;
+	});
+}`
+
+// hasURLs reports whether any dataset has a URLTemplate.
+func (c Chart) hasURLs() bool {
+	for _, d := range c.Data.Datasets {
+		if d.URLTemplate != "" {
+			return true
+		}
+	}
+	return false
+}
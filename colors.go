@@ -0,0 +1,74 @@
+package chartjs
+
+import (
+	"hash/fnv"
+
+	"github.com/iszk1215/go-chartjs/types"
+)
+
+// DefaultPalette is the Tableau 10 palette.
+var DefaultPalette = []types.RGBA{
+	{R: 78, G: 121, B: 167, A: 255},
+	{R: 242, G: 142, B: 43, A: 255},
+	{R: 225, G: 87, B: 89, A: 255},
+	{R: 118, G: 183, B: 178, A: 255},
+	{R: 89, G: 161, B: 79, A: 255},
+	{R: 237, G: 201, B: 72, A: 255},
+	{R: 176, G: 122, B: 161, A: 255},
+	{R: 255, G: 157, B: 167, A: 255},
+	{R: 156, G: 117, B: 95, A: 255},
+	{R: 186, G: 176, B: 172, A: 255},
+}
+
+// ColorMapper assigns colors to series by hashing their names, so that a
+// series such as "us-east-1" has the same color on every chart and across
+// page reloads. Different names may share a color; use Set to pin colors.
+type ColorMapper struct {
+	// Palette defaults to DefaultPalette.
+	Palette []types.RGBA
+	fixed   map[string]types.RGBA
+}
+
+// NewColorMapper returns a ColorMapper using palette.
+func NewColorMapper(palette []types.RGBA) *ColorMapper {
+	return &ColorMapper{Palette: palette}
+}
+
+// Set pins the color of a series.
+func (m *ColorMapper) Set(name string, c types.RGBA) {
+	if m.fixed == nil {
+		m.fixed = map[string]types.RGBA{}
+	}
+	m.fixed[name] = c
+}
+
+// Color returns the color of a series.
+func (m *ColorMapper) Color(name string) types.RGBA {
+	if c, ok := m.fixed[name]; ok {
+		return c
+	}
+	p := m.Palette
+	if len(p) == 0 {
+		p = DefaultPalette
+	}
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	return p[h.Sum32()%uint32(len(p))]
+}
+
+// Apply colors the datasets of the chart by their Label. Colors that are
+// already set are kept; backgrounds get the series color at half opacity.
+func (m *ColorMapper) Apply(c *Chart) {
+	for i := range c.Data.Datasets {
+		d := &c.Data.Datasets[i]
+		col := m.Color(d.Label)
+		if d.BorderColor == nil {
+			bc := col
+			d.BorderColor = &bc
+		}
+		if d.BackgroundColor == nil {
+			bg := col.WithAlpha(0.5)
+			d.BackgroundColor = &bg
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package chartjs
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/iszk1215/go-chartjs/types"
+)
+
+type corrMethod int
+
+const (
+	// Pearson measures linear correlation.
+	Pearson corrMethod = iota
+	// Spearman measures monotonic correlation, as the Pearson correlation of
+	// the ranks.
+	Spearman
+)
+
+// ranks returns the ranks of vs, averaging ties.
+func ranks(vs []float64) []float64 {
+	idx := make([]int, len(vs))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(a, b int) bool { return vs[idx[a]] < vs[idx[b]] })
+	r := make([]float64, len(vs))
+	for i := 0; i < len(idx); {
+		j := i
+		for j < len(idx) && vs[idx[j]] == vs[idx[i]] {
+			j++
+		}
+		for k := i; k < j; k++ {
+			r[idx[k]] = float64(i+j+1) / 2
+		}
+		i = j
+	}
+	return r
+}
+
+func pearson(xs, ys []float64) float64 {
+	n := float64(len(xs))
+	var sx, sy float64
+	for i := range xs {
+		sx += xs[i]
+		sy += ys[i]
+	}
+	mx, my := sx/n, sy/n
+	var cov, vx, vy float64
+	for i := range xs {
+		dx, dy := xs[i]-mx, ys[i]-my
+		cov += dx * dy
+		vx += dx * dx
+		vy += dy * dy
+	}
+	return cov / math.Sqrt(vx*vy)
+}
+
+// Corr returns the correlation matrix of the series, which must have the same
+// length. Pairs with a NaN are skipped. Correlations with a constant series
+// are NaN.
+func Corr(series [][]float64, method corrMethod) ([][]float64, error) {
+	for _, s := range series {
+		if len(s) != len(series[0]) {
+			return nil, fmt.Errorf("chart: series of a correlation matrix must be of the same length")
+		}
+	}
+	m := make([][]float64, len(series))
+	for i := range m {
+		m[i] = make([]float64, len(series))
+	}
+	for i := range series {
+		for j := i; j < len(series); j++ {
+			var xs, ys []float64
+			for k, x := range series[i] {
+				if y := series[j][k]; !math.IsNaN(x) && !math.IsNaN(y) {
+					xs, ys = append(xs, x), append(ys, y)
+				}
+			}
+			if method == Spearman {
+				xs, ys = ranks(xs), ranks(ys)
+			}
+			m[i][j] = pearson(xs, ys)
+			m[j][i] = m[i][j]
+		}
+	}
+	return m, nil
+}
+
+// CorrChart returns the correlation matrix of the series as a heatmap labeled
+// by labels, colored from blue for -1 through white to red for 1.
+func CorrChart(labels []string, series [][]float64, method corrMethod) (*Chart, error) {
+	if len(labels) != len(series) {
+		return nil, fmt.Errorf("chart: got %d labels for %d series", len(labels), len(series))
+	}
+	m, err := Corr(series, method)
+	if err != nil {
+		return nil, err
+	}
+	// RdBu runs from red to blue, so map 1 to its start.
+	c := NewMatrix(m, ColorScale{Colormap: types.RdBu, Min: 1, Max: -1})
+	names, err := json.Marshal(labels)
+	if err != nil {
+		return nil, err
+	}
+	callback := JSFunc(fmt.Sprintf("function(value) { return %s[value]; }", names))
+	for _, id := range []string{"x", "y"} {
+		a := c.Options.Scales[id]
+		t := *a.Tick
+		t.StepSize, t.Callback = 1, callback
+		a.Tick = &t
+		c.Options.Scales[id] = a
+	}
+	return c, nil
+}
@@ -0,0 +1,28 @@
+package chartjs
+
+// crossFilterJS is the onClick handler used with RenderOptions.CrossFilter.
+// Clicking a category (or a dataset for charts without
+// labels) shows only the datasets with that label in the sibling charts.
+// Clicking the same category again clears the filter.
+const crossFilterJS = `function chartjsCrossFilter(evt, elements) {
+	var source = this;
+	var el = source.getElementAtEvent ? source.getElementAtEvent(evt)[0] : elements[0];
+	var key = null;
+	if (el) {
+		var i = el._index !== undefined ? el._index : el.index;
+		var di = el._datasetIndex !== undefined ? el._datasetIndex : el.datasetIndex;
+		key = (source.data.labels || [])[i];
+		if (key === undefined) { key = source.data.datasets[di].label; }
+	}
+	if (key === chartjsCrossFilter.key) { key = null; }
+	chartjsCrossFilter.key = key;
+	charts.forEach(function(c) {
+		if (!c || c === source) { return; }
+		var any = key !== null && c.data.datasets.some(function(d) { return d.label === key; });
+		c.data.datasets.forEach(function(d, k) {
+			c.getDatasetMeta(k).hidden = any && d.label !== key ? true : null;
+		});
+		c.update();
+	});
+}
+`
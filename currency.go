@@ -0,0 +1,174 @@
+package chartjs
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// Currency formats values as money with the Intl.NumberFormat of the
+// browser. Set on an Axis it formats the ticks, and is taken by the
+// datasets on the axis without their own. Set on a Dataset it formats the
+// tooltips of its values.
+type Currency struct {
+	// Code is the ISO 4217 code, e.g. "EUR".
+	Code string
+	// Locale is the BCP 47 locale, e.g. "de-DE". The locale of the browser
+	// is used if it is empty.
+	Locale string
+	// MinorUnits means that values are integer minor units, e.g. cents,
+	// which are divided into display units when the chart is marshaled.
+	MinorUnits bool
+	// Digits is the number of digits of the minor unit, taken from Code if
+	// zero, e.g. 2 for EUR and 0 for JPY. Set it to -1 for none.
+	Digits int
+}
+
+// minorDigits are the ISO 4217 minor unit digits other than 2.
+var minorDigits = map[string]int{
+	"BHD": 3, "BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "IQD": 3, "ISK": 0,
+	"JOD": 3, "JPY": 0, "KMF": 0, "KRW": 0, "KWD": 3, "LYD": 3, "OMR": 3,
+	"PYG": 0, "RWF": 0, "TND": 3, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0,
+	"XAF": 0, "XOF": 0, "XPF": 0,
+}
+
+func (c Currency) digits() int {
+	switch {
+	case c.Digits < 0:
+		return 0
+	case c.Digits > 0:
+		return c.Digits
+	}
+	if d, ok := minorDigits[c.Code]; ok {
+		return d
+	}
+	return 2
+}
+
+func (c Currency) check() error {
+	if len(c.Code) != 3 {
+		return fmt.Errorf("chart: bad currency code %q", c.Code)
+	}
+	for _, r := range c.Code {
+		if r < 'A' || r > 'Z' {
+			return fmt.Errorf("chart: bad currency code %q", c.Code)
+		}
+	}
+	return nil
+}
+
+// formatter returns the javascript expression of the Intl.NumberFormat.
+func (c Currency) formatter() (string, error) {
+	if err := c.check(); err != nil {
+		return "", err
+	}
+	locale := []byte("undefined")
+	if c.Locale != "" {
+		var err error
+		if locale, err = json.Marshal(c.Locale); err != nil {
+			return "", err
+		}
+	}
+	return fmt.Sprintf("new Intl.NumberFormat(%s, {style: 'currency', currency: '%s'})", locale, c.Code), nil
+}
+
+// TickCallback returns a tick callback writing values in the currency.
+func (c Currency) TickCallback() (JSFunc, error) {
+	f, err := c.formatter()
+	if err != nil {
+		return "", err
+	}
+	return JSFunc("function(value) { return " + f + ".format(value); }"), nil
+}
+
+// LabelCallback returns a tooltip label callback writing the value of the
+// hovered point in the currency, after the label of its dataset.
+func (c Currency) LabelCallback() (JSFunc, error) {
+	f, err := c.formatter()
+	if err != nil {
+		return "", err
+	}
+	return JSFunc(`function(item) {
+	var v = typeof item.raw === 'number' ? item.raw : item.raw.y;
+	return (item.dataset.label ? item.dataset.label + ': ' : '') + ` + f + `.format(v);
+}`), nil
+}
+
+// scale returns v with its plotted values divided into display units.
+// The per point data of the other kinds of Values is kept.
+func (c Currency) scale(v Values) Values {
+	f := math.Pow10(c.digits())
+	div := func(vs []float64) []float64 {
+		out := make([]float64, len(vs))
+		for i, x := range vs {
+			out[i] = x / f
+		}
+		return out
+	}
+	out := xyValues{xs: v.Xs(), ys: v.Ys(), rs: v.Rs()}
+	if len(out.ys) > 0 {
+		out.ys = div(out.ys)
+	} else {
+		out.xs = div(out.xs)
+	}
+	switch v := v.(type) {
+	case TimeValues:
+		return TimeSeries{Time: v.Times(), Value: out.ys}
+	case MetaValues:
+		return metaValues{xyValues: out, meta: v.Meta()}
+	case LabeledValues:
+		return mapValues{xyValues: out, labels: v.Labels()}
+	}
+	return out
+}
+
+// applyCurrencies formats the ticks of the axes with a Currency, and gives
+// their Currency to the datasets on them without their own.
+func (c *Chart) applyCurrencies() error {
+	var scales map[string]Axis
+	for id, a := range c.Options.Scales {
+		if a.Currency == nil {
+			continue
+		}
+		if scales == nil {
+			scales = make(map[string]Axis, len(c.Options.Scales))
+			for id, a := range c.Options.Scales {
+				scales[id] = a
+			}
+		}
+		tick := Tick{}
+		if a.Tick != nil {
+			tick = *a.Tick
+		}
+		if tick.Callback == "" {
+			cb, err := a.Currency.TickCallback()
+			if err != nil {
+				return err
+			}
+			tick.Callback = cb
+		}
+		a.Tick = &tick
+		scales[id] = a
+	}
+	if scales == nil {
+		return nil
+	}
+	c.Options.Scales = scales
+	datasets := make([]Dataset, len(c.Data.Datasets))
+	for i, d := range c.Data.Datasets {
+		if d.Currency == nil {
+			// the value axis, which is x for horizontal bars.
+			id, def := d.YAxisID, defaultYAxisID
+			if c.Options.IndexAxis == "y" {
+				id, def = d.XAxisID, defaultXAxisID
+			}
+			if id == "" {
+				id = def
+			}
+			d.Currency = scales[id].Currency
+		}
+		datasets[i] = d
+	}
+	c.Data.Datasets = datasets
+	return nil
+}
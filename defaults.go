@@ -0,0 +1,84 @@
+package chartjs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/iszk1215/go-chartjs/types"
+)
+
+// GlobalDefaults holds site-wide chart.js defaults, emitted once per page
+// instead of being repeated in every chart.
+type GlobalDefaults struct {
+	FontFamily string
+	FontSize   int
+	// FontColor is the default color of all text.
+	FontColor *types.RGBA
+	// BorderColor is the default color of lines and borders.
+	BorderColor *types.RGBA
+	// AnimationDuration is in milliseconds. Zero disables animation.
+	AnimationDuration int
+}
+
+// Defaults is used by SaveCharts unless overridden by RenderOptions.Defaults.
+var Defaults GlobalDefaults
+
+// The paths under Chart.defaults of the line interpolation, the font family,
+// size and color, the border color and the animation duration in chart.js 2
+// and in chart.js 3 and later.
+var (
+	v2DefaultPaths = [...]string{"line.cubicInterpolationMode", "global.defaultFontFamily", "global.defaultFontSize",
+		"global.defaultFontColor", "global.defaultColor", "global.animation.duration"}
+	v3DefaultPaths = [...]string{"datasets.line.cubicInterpolationMode", "font.family", "font.size",
+		"color", "borderColor", "animation.duration"}
+)
+
+// js returns the assignments to Chart.defaults for chart.js version v.
+func (d GlobalDefaults) js(v chartJSVersion) (string, error) {
+	paths := v2DefaultPaths
+	if v >= V3 {
+		paths = v3DefaultPaths
+	}
+	var buf bytes.Buffer
+	set := func(path string, v interface{}) error {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(&buf, "Chart.defaults.%s = %s;\n", path, b)
+		return err
+	}
+	err := set(paths[0], "monotone")
+	if err == nil && d.FontFamily != "" {
+		err = set(paths[1], d.FontFamily)
+	}
+	if err == nil && d.FontSize != 0 {
+		err = set(paths[2], d.FontSize)
+	}
+	if err == nil && d.FontColor != nil {
+		err = set(paths[3], d.FontColor)
+	}
+	if err == nil && d.BorderColor != nil {
+		err = set(paths[4], d.BorderColor)
+	}
+	if err == nil {
+		err = set(paths[5], d.AnimationDuration)
+	}
+	return buf.String(), err
+}
+
+// pageVersion returns the chart.js version targeted by the charts of a page,
+// which must agree on using chart.js 2 or a later version.
+func pageVersion(charts []Chart) (chartJSVersion, error) {
+	var v chartJSVersion
+	for i, c := range charts {
+		if i > 0 && (c.TargetVersion >= V3) != (v >= V3) {
+			return 0, fmt.Errorf("chart: chart %q targets chart.js %d, unlike the charts before it", c.Label, c.TargetVersion)
+		}
+		if c.TargetVersion > v {
+			v = c.TargetVersion
+		}
+	}
+	return v, nil
+}
@@ -0,0 +1,299 @@
+// Package devserver serves a directory of chart.js chart specs and reloads
+// open pages whenever a spec changes, to speed up iterating on dashboards.
+//
+//	s := devserver.New("charts")
+//	go s.Watch(ctx)
+//	http.ListenAndServe("localhost:8080", s)
+//
+// Each file "{name}.json" holds a chart config and is served at /{name}.
+// Specs in other formats, e.g. YAML, are served once a converter to JSON is
+// registered for their extension with RegisterFormat. Open pages are told to
+// reload through server-sent events.
+package devserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	chartjs "github.com/iszk1215/go-chartjs"
+)
+
+const pageTmpl = `<!DOCTYPE html>
+<html>
+    <head>
+		<title>{{ .Name }}</title>
+		<script src="{{ .ChartJS }}"></script>
+    </head>
+    <body>
+	<p><a href="/">specs</a></p>
+	{{ if .Err }}<pre style="color:#c00">{{ .Err }}</pre>{{ else }}
+	<canvas id="canvas" style="height:{{ .Height }}px;width:{{ .Width }}px"></canvas>{{ end }}
+    </body>
+    <script>
+	{{ if not .Err }}var chart = new Chart(document.getElementById("canvas").getContext("2d"), {{ .Spec }});{{ end }}
+	new EventSource("/_events").onmessage = function() { location.reload(); };
+    </script>
+</html>`
+
+const indexTmpl = `<!DOCTYPE html>
+<html>
+    <head><title>specs</title></head>
+    <body>
+	<ul>{{ range . }}<li><a href="/{{ . }}">{{ . }}</a></li>{{ end }}</ul>
+    </body>
+    <script>new EventSource("/_events").onmessage = function() { location.reload(); };</script>
+</html>`
+
+var (
+	page  = template.Must(template.New("page").Parse(pageTmpl))
+	index = template.Must(template.New("index").Parse(indexTmpl))
+)
+
+// Server serves the chart specs in Dir.
+type Server struct {
+	Dir string
+	// Interval between scans of Dir for changes. Defaults to 500ms.
+	Interval time.Duration
+	// Width and Height of the canvas. Default to 800x400.
+	Width, Height int
+
+	mu      sync.Mutex
+	clients map[chan struct{}]bool
+}
+
+// New returns a Server for the specs in dir.
+func New(dir string) *Server {
+	return &Server{Dir: dir}
+}
+
+// Converter returns the JSON of a spec in another format.
+type Converter func(b []byte) ([]byte, error)
+
+var (
+	formatsMu sync.RWMutex
+	// formats maps the extensions of spec files to their converters.
+	formats = map[string]Converter{".json": nil}
+)
+
+// RegisterFormat serves the spec files with extension ext, e.g. ".yaml",
+// converted to JSON by conv. Formats are usually registered from an init
+// function.
+func RegisterFormat(ext string, conv Converter) error {
+	if !strings.HasPrefix(ext, ".") || conv == nil {
+		return fmt.Errorf("devserver: bad format %q", ext)
+	}
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+	if _, ok := formats[ext]; ok {
+		return fmt.Errorf("devserver: format %q already registered", ext)
+	}
+	formats[ext] = conv
+	return nil
+}
+
+// specExts returns the extensions of spec files, ".json" first.
+func specExts() []string {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+	exts := make([]string, 0, len(formats))
+	for ext := range formats {
+		if ext != ".json" {
+			exts = append(exts, ext)
+		}
+	}
+	sort.Strings(exts)
+	return append([]string{".json"}, exts...)
+}
+
+// readSpec returns the JSON of the spec name, and the file it was read from.
+// The file is empty if there is no spec of that name.
+func (s *Server) readSpec(name string) ([]byte, string, error) {
+	for _, ext := range specExts() {
+		file := name + ext
+		b, err := os.ReadFile(filepath.Join(s.Dir, file))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, file, err
+		}
+		formatsMu.RLock()
+		conv := formats[ext]
+		formatsMu.RUnlock()
+		if conv != nil {
+			if b, err = conv(b); err != nil {
+				return nil, file, fmt.Errorf("devserver: %s: %v", file, err)
+			}
+		}
+		if !json.Valid(b) {
+			return nil, file, fmt.Errorf("devserver: %s is not valid JSON", file)
+		}
+		return b, file, nil
+	}
+	return nil, "", nil
+}
+
+// specs returns the modification times of the spec files by file name.
+func (s *Server) specs() (map[string]time.Time, error) {
+	m := map[string]time.Time{}
+	for _, ext := range specExts() {
+		paths, err := filepath.Glob(filepath.Join(s.Dir, "*"+ext))
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range paths {
+			fi, err := os.Stat(p)
+			if err != nil {
+				continue
+			}
+			m[filepath.Base(p)] = fi.ModTime()
+		}
+	}
+	return m, nil
+}
+
+// Watch scans Dir for added, removed or modified specs and reloads the open
+// pages when it finds any, until ctx is done.
+func (s *Server) Watch(ctx context.Context) error {
+	interval := s.Interval
+	if interval <= 0 {
+		interval = 500 * time.Millisecond
+	}
+	last, err := s.specs()
+	if err != nil {
+		return err
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+		cur, err := s.specs()
+		if err != nil {
+			return err
+		}
+		if changed(last, cur) {
+			s.Reload()
+		}
+		last = cur
+	}
+}
+
+func changed(a, b map[string]time.Time) bool {
+	if len(a) != len(b) {
+		return true
+	}
+	for k, t := range a {
+		if u, ok := b[k]; !ok || !u.Equal(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// Reload tells every open page to reload.
+func (s *Server) Reload() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for c := range s.clients {
+		select {
+		case c <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (s *Server) events(w http.ResponseWriter, r *http.Request) {
+	f, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "devserver: streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	c := make(chan struct{}, 1)
+	s.mu.Lock()
+	if s.clients == nil {
+		s.clients = map[chan struct{}]bool{}
+	}
+	s.clients[c] = true
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.clients, c)
+		s.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	f.Flush()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-c:
+			fmt.Fprint(w, "data: reload\n\n")
+			f.Flush()
+		}
+	}
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name := strings.Trim(r.URL.Path, "/")
+	switch {
+	case name == "_events":
+		s.events(w, r)
+		return
+	case name == "":
+		m, err := s.specs()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		seen := map[string]bool{}
+		names := make([]string, 0, len(m))
+		for file := range m {
+			n := strings.TrimSuffix(file, filepath.Ext(file))
+			if !seen[n] {
+				seen[n] = true
+				names = append(names, n)
+			}
+		}
+		sort.Strings(names)
+		index.Execute(w, names)
+		return
+	case strings.ContainsAny(name, "/\\") || strings.HasPrefix(name, "."):
+		http.NotFound(w, r)
+		return
+	}
+
+	b, file, err := s.readSpec(name)
+	if file == "" {
+		http.NotFound(w, r)
+		return
+	}
+	data := map[string]interface{}{"Name": name, "ChartJS": chartjs.ChartJS, "Width": s.Width, "Height": s.Height}
+	if s.Width == 0 || s.Height == 0 {
+		data["Width"], data["Height"] = 800, 400
+	}
+	if err != nil {
+		// show the error on the page so that it reloads once fixed.
+		data["Err"] = err.Error()
+	} else {
+		data["Spec"] = template.JS(b)
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	page.Execute(w, data)
+}
@@ -0,0 +1,77 @@
+package devserver
+
+import (
+	"bufio"
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServer(t *testing.T) {
+	dir, err := os.MkdirTemp("", "devserver")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	spec := filepath.Join(dir, "cpu.json")
+	if err := os.WriteFile(spec, []byte(`{"type":"line","data":{"datasets":[]}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := New(dir)
+	s.Interval = 10 * time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Watch(ctx)
+
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	resp, err := srv.Client().Get(srv.URL + "/cpu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := make([]byte, 4096)
+	n, _ := resp.Body.Read(b)
+	resp.Body.Close()
+	if !strings.Contains(string(b[:n]), `{"type":"line"`) {
+		t.Errorf("expected spec in page: %s", b[:n])
+	}
+
+	if err := RegisterFormat(".upper", func(b []byte) ([]byte, error) { return []byte(strings.ToLower(string(b))), nil }); err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterFormat(".upper", nil); err == nil {
+		t.Error("expected an error for a registered format")
+	}
+	if err := os.WriteFile(filepath.Join(dir, "disk.upper"), []byte(`{"TYPE":"BAR"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if !strings.Contains(rec.Body.String(), `href="/disk"`) {
+		t.Errorf("expected the spec of a registered format to be listed: %s", rec.Body)
+	}
+	rec = httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest("GET", "/disk", nil))
+	if !strings.Contains(rec.Body.String(), `{"type":"bar"}`) {
+		t.Errorf("expected the converted spec in page: %s", rec.Body)
+	}
+
+	resp, err = srv.Client().Get(srv.URL + "/_events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if err := os.WriteFile(filepath.Join(dir, "mem.json"), []byte(`{}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	line, err := bufio.NewReader(resp.Body).ReadString('\n')
+	if err != nil || line != "data: reload\n" {
+		t.Errorf("expected reload event, got %q, %v", line, err)
+	}
+}
@@ -0,0 +1,93 @@
+package chartjs
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/iszk1215/go-chartjs/types"
+)
+
+// normalQuantile is the inverse of the standard normal CDF.
+func normalQuantile(p float64) float64 {
+	return math.Sqrt2 * math.Erfinv(2*p-1)
+}
+
+// QQPlot returns a scatter chart of the sorted sample against the quantiles
+// of the standard normal distribution, with a reference line through the
+// quartiles. Points near the line indicate normally distributed data.
+func QQPlot(sample []float64) (*Chart, error) {
+	sorted := make([]float64, 0, len(sample))
+	for _, v := range sample {
+		if !math.IsNaN(v) {
+			sorted = append(sorted, v)
+		}
+	}
+	if len(sorted) < 2 {
+		return nil, fmt.Errorf("chart: need at least 2 values for a QQ plot")
+	}
+	sort.Float64s(sorted)
+	n := float64(len(sorted))
+	theory := make([]float64, len(sorted))
+	for i := range sorted {
+		theory[i] = normalQuantile((float64(i) + 0.5) / n)
+	}
+	c, err := NewScatter(theory, sorted)
+	if err != nil {
+		return nil, err
+	}
+	c.Data.Datasets[0].Label = "sample"
+
+	q1, q3 := quantile(sorted, 0.25), quantile(sorted, 0.75)
+	t1, t3 := normalQuantile(0.25), normalQuantile(0.75)
+	slope := (q3 - q1) / (t3 - t1)
+	line := func(t float64) float64 { return q1 + slope*(t-t1) }
+	lo, hi := theory[0], theory[len(theory)-1]
+	color := types.RGBA{R: 214, G: 39, B: 40, A: 255}
+	c.AddDataset(Dataset{
+		Label:       "normal",
+		Data:        xyValues{xs: []float64{lo, hi}, ys: []float64{line(lo), line(hi)}},
+		ShowLine:    True,
+		Fill:        False,
+		BorderColor: &color,
+		BorderWidth: 1,
+	})
+	c.Options.Scales["x"] = withTitle(c.Options.Scales["x"], "theoretical quantiles")
+	c.AddAxis(withTitle(Axis{ID: "y", Type: Linear, Position: Left}, "sample quantiles"))
+	return c, nil
+}
+
+// Residuals returns a scatter chart of observed minus fitted values against
+// the fitted values, with a line at zero.
+func Residuals(fitted, observed []float64) (*Chart, error) {
+	if len(fitted) != len(observed) {
+		return nil, fmt.Errorf("chart: bad format of Values. X and Y must be of the same length")
+	}
+	res := make([]float64, len(fitted))
+	for i, f := range fitted {
+		res[i] = observed[i] - f
+	}
+	c, err := NewScatter(fitted, res)
+	if err != nil {
+		return nil, err
+	}
+	c.Data.Datasets[0].Label = "residuals"
+	c.Options.Scales["x"] = withTitle(c.Options.Scales["x"], "fitted")
+	c.AddAxis(withTitle(Axis{ID: "y", Type: Linear, Position: Left}, "residual"))
+	zero := 0.0
+	color := types.RGBA{R: 127, G: 127, B: 127, A: 255}
+	c.AddAnnotation(Annotation{
+		Type:        LineAnnotation,
+		Mode:        "horizontal",
+		ScaleID:     "y",
+		Value:       &zero,
+		BorderColor: &color,
+		BorderWidth: 1,
+	})
+	return c, nil
+}
+
+func withTitle(a Axis, title string) Axis {
+	a.Title = AxisTitle{Display: true, Text: title}
+	return a
+}
@@ -0,0 +1,88 @@
+package chartjs
+
+import (
+	"math"
+	"sort"
+
+	"github.com/iszk1215/go-chartjs/types"
+)
+
+// Measurements hold the values of a set of series at one point in time, e.g.
+// benchmark results or the capacity of each cluster, by name.
+type Measurements map[string]float64
+
+// Diff compares two snapshots of the same series.
+type Diff struct {
+	// BeforeLabel and AfterLabel name the snapshots, "before" and "after" by
+	// default.
+	BeforeLabel, AfterLabel string
+	// Relative shows the change in percent of the before value instead of the
+	// difference.
+	Relative bool
+}
+
+// Chart returns grouped bars of the before and after values of each series,
+// sorted by name, and a line of their change on a right-hand axis. Series
+// missing from either snapshot have no change.
+func (d Diff) Chart(before, after Measurements) *Chart {
+	names := make([]string, 0, len(before))
+	for name := range before {
+		names = append(names, name)
+	}
+	for name := range after {
+		if _, ok := before[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	value := func(s Measurements, name string) float64 {
+		if v, ok := s[name]; ok {
+			return v
+		}
+		return math.NaN()
+	}
+	b, a, delta := make([]float64, len(names)), make([]float64, len(names)), make([]float64, len(names))
+	for i, name := range names {
+		b[i], a[i] = value(before, name), value(after, name)
+		delta[i] = a[i] - b[i]
+		if d.Relative {
+			delta[i] = 100 * delta[i] / math.Abs(b[i])
+		}
+	}
+
+	bl, al := d.BeforeLabel, d.AfterLabel
+	if bl == "" {
+		bl = "before"
+	}
+	if al == "" {
+		al = "after"
+	}
+	deltaLabel := "change"
+	if d.Relative {
+		deltaLabel = "change (%)"
+	}
+
+	c := &Chart{Type: Bar, Data: Data{Labels: names}}
+	c.AddXAxis(Axis{Type: Category, Position: Bottom})
+	c.AddYAxis(Axis{Type: Linear, Position: Left})
+	c.AddYAxis(Axis{ID: "delta", Type: Linear, Position: Right, GridLines: False,
+		ScaleLabel: &ScaleLabel{Display: True, LabelString: deltaLabel}})
+
+	gray := types.RGBA{R: 186, G: 176, B: 172, A: 255}
+	color := DefaultPalette[0]
+	line := DefaultPalette[1]
+	c.AddDataset(Dataset{Label: bl, Data: xyValues{xs: b}, BackgroundColor: &gray, Order: 1})
+	c.AddDataset(Dataset{Label: al, Data: xyValues{xs: a}, BackgroundColor: &color, Order: 1})
+	c.AddDataset(Dataset{
+		Type:        Line,
+		Label:       deltaLabel,
+		Data:        xyValues{xs: delta},
+		YAxisID:     "delta",
+		BorderColor: &line,
+		BorderWidth: 2,
+		PointRadius: 3,
+		Fill:        False,
+	})
+	return c
+}
@@ -0,0 +1,96 @@
+package chartjs
+
+import (
+	"fmt"
+	"math"
+)
+
+// LogHistogram returns a bar chart counting xs in logarithmic bins, with
+// binsPerDecade bins between each power of ten. Bars are labeled by the
+// lower edges of the bins. Values that are not positive are dropped.
+func LogHistogram(xs []float64, binsPerDecade int) (*Chart, error) {
+	return LogHistogramWeighted(xs, nil, binsPerDecade)
+}
+
+// LogHistogramWeighted is like LogHistogram with each value counted
+// weights[i] times.
+func LogHistogramWeighted(xs, weights []float64, binsPerDecade int) (*Chart, error) {
+	if err := checkWeights(len(xs), weights); err != nil {
+		return nil, err
+	}
+	if binsPerDecade <= 0 {
+		return nil, fmt.Errorf("chart: bad number of bins per decade %d", binsPerDecade)
+	}
+	bpd := float64(binsPerDecade)
+	// the epsilon keeps exact powers of ten in the bin they start.
+	index := func(x float64) int { return int(math.Floor(math.Log10(x)*bpd + 1e-9)) }
+	first, last := math.MaxInt32, math.MinInt32
+	for _, x := range xs {
+		if x > 0 && !math.IsInf(x, 1) {
+			i := index(x)
+			if i < first {
+				first = i
+			}
+			if i > last {
+				last = i
+			}
+		}
+	}
+	c := &Chart{Type: Bar}
+	if first > last {
+		return c, nil
+	}
+	counts := make([]float64, last-first+1)
+	for i, x := range xs {
+		if x > 0 && !math.IsInf(x, 1) {
+			counts[index(x)-first] += weightAt(weights, i)
+		}
+	}
+	for i := range counts {
+		c.Data.Labels = append(c.Data.Labels, fmt.Sprintf("%.3g", math.Pow(10, float64(first+i)/bpd)))
+	}
+	color := DefaultPalette[0]
+	c.AddDataset(Dataset{Label: "count", Data: xyValues{xs: counts}, BackgroundColor: &color, XFloatFormat: "%g"})
+	return c, nil
+}
+
+// ECDF returns a step line chart of the empirical cumulative distribution
+// of xs on a linear x-axis, ignoring NaNs.
+func ECDF(xs []float64) (*Chart, error) {
+	return ECDFWeighted(xs, nil)
+}
+
+// ECDFWeighted is like ECDF with each value counted weights[i] times.
+func ECDFWeighted(xs, weights []float64) (*Chart, error) {
+	if err := checkWeights(len(xs), weights); err != nil {
+		return nil, err
+	}
+	s, total := sortedSample(xs, weights)
+	// two points per value draw the vertical steps.
+	v := xyValues{}
+	var cum float64
+	for _, p := range s {
+		v.xs = append(v.xs, p.v, p.v)
+		v.ys = append(v.ys, cum/total, (cum+p.w)/total)
+		cum += p.w
+	}
+	c := &Chart{Type: Line}
+	if _, err := c.AddXAxis(Axis{Type: Linear, Position: Bottom}); err != nil {
+		return nil, err
+	}
+	if _, err := c.AddYAxis(Axis{Type: Linear, Position: Left, Tick: &Tick{Max: 1, BeginAtZero: True}}); err != nil {
+		return nil, err
+	}
+	color := DefaultPalette[0]
+	c.AddDataset(Dataset{
+		Label:                  "ECDF",
+		Data:                   v,
+		BorderColor:            &color,
+		BorderWidth:            2,
+		Fill:                   False,
+		CubicInterpolationMode: InterpDefault,
+		YFloatFormat:           "%.4f",
+		XFloatFormat:           "%g",
+	})
+	return c, nil
+}
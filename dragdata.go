@@ -0,0 +1,69 @@
+package chartjs
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/iszk1215/go-chartjs/types"
+)
+
+// DragData holds the chartjs-plugin-dragdata options, which let users drag
+// points to edit their values. The plugin is loaded from Plugins["dragdata"].
+type DragData struct {
+	// DragX allows dragging along the x axis too.
+	DragX types.Bool
+	// Round is the number of decimals dragged values are rounded to.
+	Round int
+	// URL receives each edit as a DragEvent, see DragHandler.
+	URL string
+}
+
+// DragEvent is posted when a user finishes dragging a point.
+type DragEvent struct {
+	// Chart is the Label of the chart.
+	Chart        string  `json:"chart"`
+	Dataset      string  `json:"dataset"`
+	DatasetIndex int     `json:"datasetIndex"`
+	Index        int     `json:"index"`
+	X            float64 `json:"x"`
+	Y            float64 `json:"y"`
+}
+
+// EnableDragData makes the points of the chart draggable.
+func (c *Chart) EnableDragData(d DragData) {
+	c.Options.DragData = types.True
+	c.Options.DragX = d.DragX
+	c.Options.DragDataRound = d.Round
+	if d.URL == "" {
+		return
+	}
+	url, _ := json.Marshal(d.URL)
+	label, _ := json.Marshal(c.Label)
+	c.Options.OnDragEnd = JSFunc(`function(e, datasetIndex, index, value) {
+	var ds = this.data ? this.data.datasets[datasetIndex] : {};
+	var isObj = value !== null && typeof value === "object";
+	fetch(` + string(url) + `, {method: "POST", headers: {"Content-Type": "application/json"}, body: JSON.stringify({
+		chart: ` + string(label) + `, dataset: (ds && ds.label) || "", datasetIndex: datasetIndex, index: index,
+		x: isObj ? value.x : index, y: isObj ? value.y : value})});
+}`)
+}
+
+// DragHandler receives the edits posted by charts with DragData.
+func DragHandler(fn func(DragEvent) error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			http.Error(w, "chart: edits must be posted", http.StatusMethodNotAllowed)
+			return
+		}
+		var e DragEvent
+		if err := json.NewDecoder(r.Body).Decode(&e); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := fn(e); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
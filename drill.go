@@ -0,0 +1,84 @@
+package chartjs
+
+import (
+	"bytes"
+	"net/http"
+)
+
+// DrillDown lets users click a bar or slice to replace the chart with a child
+// chart served by a DrillDownHandler. Breadcrumbs above the canvas lead back up
+// the hierarchy.
+type DrillDown struct {
+	// URL of the DrillDownHandler. The clicked categories are passed as
+	// repeated "path" query parameters.
+	URL string
+	// RootLabel is the first breadcrumb. Defaults to "All".
+	RootLabel string
+}
+
+// DrillDownFunc returns the child chart for the categories clicked so far.
+// A nil chart means there is nothing to drill into.
+type DrillDownFunc func(path []string) (*Chart, error)
+
+// DrillDownHandler serves the child charts of a DrillDown as javascript
+// object literals, as JSEncoder writes them, so that their JSFunc values are
+// code.
+func DrillDownHandler(fn DrillDownFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := fn(r.URL.Query()["path"])
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if c == nil {
+			http.NotFound(w, r)
+			return
+		}
+		var buf bytes.Buffer
+		if err := (JSEncoder{}).Encode(&buf, *c); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", JSEncoder{}.ContentType())
+		buf.WriteTo(w)
+	})
+}
+
+// drillDownJS is the client side of DrillDown. root returns a fresh copy of the
+// top-level config. Child configs are evaluated as they hold code.
+const drillDownJS = `function chartjsDrillDown(i, root, url, rootLabel) {
+	var path = [];
+	var canvas = document.getElementById("canvas" + i);
+	var crumbs = document.getElementById("crumbs" + i);
+	function show(cfg) {
+		cfg.options = cfg.options || {};
+		cfg.options.onClick = function(evt, elements) {
+			var el = this.getElementAtEvent ? this.getElementAtEvent(evt)[0] : elements[0];
+			if (!el) { return; }
+			var label = (this.data.labels || [])[el._index !== undefined ? el._index : el.index];
+			if (label !== undefined) { load(path.concat([String(label)])); }
+		};
+		if (charts[i]) { charts[i].destroy(); }
+		charts[i] = new Chart(canvas.getContext("2d"), cfg);
+		crumbs.innerHTML = "";
+		[rootLabel].concat(path).forEach(function(name, k) {
+			if (k > 0) { crumbs.appendChild(document.createTextNode(" / ")); }
+			var a = document.createElement("a");
+			a.href = "#";
+			a.textContent = name;
+			a.onclick = function(e) { e.preventDefault(); load(path.slice(0, k)); };
+			crumbs.appendChild(a);
+		});
+	}
+	function load(p) {
+		if (p.length === 0) { path = p; show(root()); return; }
+		var q = p.map(function(s) { return "path=" + encodeURIComponent(s); }).join("&");
+		fetch(url + (url.indexOf("?") < 0 ? "?" : "&") + q).then(function(r) {
+			return r.ok ? r.text() : null;
+		}).then(function(js) {
+			if (js) { path = p; show(new Function("return " + js)()); }
+		});
+	}
+	show(root());
+}
+`
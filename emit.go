@@ -0,0 +1,180 @@
+package chartjs
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// scratchPool holds the buffers values are formatted into before they are
+// written, so that writing a point or a label allocates nothing.
+var scratchPool = sync.Pool{New: func() interface{} {
+	b := make([]byte, 0, 64)
+	return &b
+}}
+
+// writeScratch calls fill with an empty buffer and writes what it appended.
+func writeScratch(w jsonWriter, fill func(b []byte) ([]byte, error)) error {
+	p := scratchPool.Get().(*[]byte)
+	b, err := fill((*p)[:0])
+	if err == nil {
+		_, err = w.Write(b)
+	}
+	// keep the grown buffer, unless it grew too large to hold on to.
+	if cap(b) <= 64<<10 {
+		*p = b
+	}
+	scratchPool.Put(p)
+	return err
+}
+
+// floatFormat is a printf format of floats. The verbs f, e and g with an
+// optional precision, e.g. "%.2f", are appended by strconv, other formats
+// by fmt and checked to produce JSON numbers.
+type floatFormat struct {
+	format string
+	verb   byte
+	prec   int
+}
+
+func parseFloatFormat(format string) floatFormat {
+	f := floatFormat{format: format, prec: -1}
+	if len(format) < 2 || format[0] != '%' {
+		return f
+	}
+	s := format[1 : len(format)-1]
+	if s != "" {
+		if s[0] != '.' {
+			return f
+		}
+		f.prec = 0
+		for i := 1; i < len(s); i++ {
+			if s[i] < '0' || s[i] > '9' || f.prec > 100 {
+				return f
+			}
+			f.prec = f.prec*10 + int(s[i]-'0')
+		}
+	}
+	switch verb := format[len(format)-1]; verb {
+	case 'f', 'e':
+		if s == "" {
+			// as fmt does.
+			f.prec = 6
+		}
+		f.verb = verb
+	case 'g':
+		f.verb = verb
+	}
+	return f
+}
+
+// append appends v to b. NaN and infinities are appended as null.
+func (f floatFormat) append(b []byte, v float64) ([]byte, error) {
+	if !finite(v) {
+		return append(b, "null"...), nil
+	}
+	if f.verb != 0 {
+		return strconv.AppendFloat(b, v, f.verb, f.prec, 64), nil
+	}
+	s := fmt.Sprintf(f.format, v)
+	if !validNumber(s) {
+		return b, fmt.Errorf("chart: format %q produced invalid JSON number %q", f.format, s)
+	}
+	return append(b, s...), nil
+}
+
+const hexDigits = "0123456789abcdef"
+
+// appendJSONString appends s as a JSON string escaped as json.Marshal does,
+// including <, > and & for HTML. Invalid UTF-8 is replaced by U+FFFD.
+func appendJSONString(b []byte, s string) []byte {
+	b = append(b, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' && c != '<' && c != '>' && c != '&' {
+				i++
+				continue
+			}
+			b = append(b, s[start:i]...)
+			switch c {
+			case '"', '\\':
+				b = append(b, '\\', c)
+			case '\n':
+				b = append(b, `\n`...)
+			case '\r':
+				b = append(b, `\r`...)
+			case '\t':
+				b = append(b, `\t`...)
+			default:
+				b = append(b, `\u00`...)
+				b = append(b, hexDigits[c>>4], hexDigits[c&0xf])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			b = append(b, s[start:i]...)
+			b = append(b, `\ufffd`...)
+		case r == '\u2028' || r == '\u2029':
+			// line separators end javascript strings.
+			b = append(b, s[start:i]...)
+			b = append(b, `\u202`...)
+			b = append(b, hexDigits[r&0xf])
+		default:
+			i += size
+			continue
+		}
+		i += size
+		start = i
+	}
+	b = append(b, s[start:]...)
+	return append(b, '"')
+}
+
+// writeStringsJSON writes ss as a JSON array of strings, null if nil.
+func writeStringsJSON(w jsonWriter, ss []string) error {
+	if ss == nil {
+		_, err := w.WriteString("null")
+		return err
+	}
+	w.WriteByte('[')
+	for i, s := range ss {
+		if i > 0 {
+			w.WriteByte(',')
+		}
+		if err := writeScratch(w, func(b []byte) ([]byte, error) {
+			return appendJSONString(b, s), nil
+		}); err != nil {
+			return err
+		}
+	}
+	return w.WriteByte(']')
+}
+
+// appendTime appends t in epoch milliseconds, or formatted with layout in
+// loc as a JSON string when layout is set.
+func appendTime(b []byte, t time.Time, layout string, loc *time.Location) []byte {
+	if layout == "" {
+		return strconv.AppendInt(b, t.UnixNano()/int64(time.Millisecond), 10)
+	}
+	if loc != nil {
+		t = t.In(loc)
+	}
+	// format after the quote, then escape the formatted time in place if
+	// needed, which layouts of digits and letters are not.
+	n := len(b)
+	b = t.AppendFormat(append(b, '"'), layout)
+	for _, c := range b[n+1:] {
+		if c < 0x20 || c >= utf8.RuneSelf || c == '"' || c == '\\' || c == '<' || c == '>' || c == '&' {
+			return appendJSONString(b[:n], string(b[n+1:]))
+		}
+	}
+	return append(b, '"')
+}
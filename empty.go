@@ -0,0 +1,29 @@
+package chartjs
+
+// DefaultEmptyText is shown in place of a chart without data when Chart.EmptyText is not set.
+var DefaultEmptyText = "No data"
+
+// IsEmpty reports whether the chart has no points to draw. Datasets whose Data
+// is not Values are assumed to have points.
+func (c Chart) IsEmpty() bool {
+	for _, d := range c.Data.Datasets {
+		v, ok := d.Data.(Values)
+		if !ok {
+			if d.Data != nil {
+				return false
+			}
+			continue
+		}
+		if len(plotted(v)) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (c Chart) emptyText() string {
+	if c.EmptyText != "" {
+		return c.EmptyText
+	}
+	return DefaultEmptyText
+}
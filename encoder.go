@@ -0,0 +1,99 @@
+package chartjs
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"path"
+)
+
+// Encoder writes charts in an output format, e.g. for an API or a bundler.
+type Encoder interface {
+	// ContentType is the MIME type of the output.
+	ContentType() string
+	Encode(w io.Writer, c Chart) error
+}
+
+// Encoders maps file extensions to the encoders used for them, e.g. by
+// Handler to serve data.json. Add to it to support more formats.
+var Encoders = map[string]Encoder{
+	"json": JSONEncoder{},
+	"js":   JSEncoder{},
+	"mjs":  ModuleEncoder{},
+
+	"msgpack": MsgPackEncoder{},
+	"cbor":    CBOREncoder{},
+}
+
+// EncoderFor returns the encoder for the extension of name, e.g. "data.json".
+func EncoderFor(name string) (Encoder, bool) {
+	ext := path.Ext(name)
+	if ext == "" {
+		return nil, false
+	}
+	e, ok := Encoders[ext[1:]]
+	return e, ok
+}
+
+// JSONEncoder writes the chart config as JSON. JSFunc values are strings of
+// their code.
+type JSONEncoder struct {
+	// Indent indents the output when set, e.g. to "  ".
+	Indent string
+}
+
+// ContentType implements Encoder.
+func (JSONEncoder) ContentType() string { return "application/json" }
+
+// Encode implements Encoder.
+func (e JSONEncoder) Encode(w io.Writer, c Chart) error {
+	if e.Indent == "" {
+		return c.WriteJSON(w)
+	}
+	b, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, b, "", e.Indent); err != nil {
+		return err
+	}
+	_, err = buf.WriteTo(w)
+	return err
+}
+
+// JSEncoder writes the chart config as a javascript object literal with the
+// JSFunc values as code, as embedded by SaveCharts.
+type JSEncoder struct{}
+
+// ContentType implements Encoder.
+func (JSEncoder) ContentType() string { return "text/javascript" }
+
+// Encode implements Encoder.
+func (JSEncoder) Encode(w io.Writer, c Chart) error {
+	b, err := c.MarshalTagged()
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(inlineJS(b))
+	return err
+}
+
+// ModuleEncoder writes the chart config as a javascript module exporting it
+// by default.
+type ModuleEncoder struct{}
+
+// ContentType implements Encoder.
+func (ModuleEncoder) ContentType() string { return "text/javascript" }
+
+// Encode implements Encoder.
+func (ModuleEncoder) Encode(w io.Writer, c Chart) error {
+	if _, err := io.WriteString(w, "export default "); err != nil {
+		return err
+	}
+	if err := (JSEncoder{}).Encode(w, c); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, ";\n")
+	return err
+}
@@ -0,0 +1,112 @@
+package chartjs
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// Equal compares two charts by the configuration they marshal to, ignoring
+// field order, number formatting and the difference between nil and empty
+// values. If the charts differ, it returns false and a description of the
+// first differing path, e.g. "data.datasets[0].label: "a" != "b"".
+func Equal(a, b *Chart) (bool, string) {
+	va, err := decodeChart(a)
+	if err != nil {
+		return false, err.Error()
+	}
+	vb, err := decodeChart(b)
+	if err != nil {
+		return false, err.Error()
+	}
+	if d := diff("", va, vb); d != "" {
+		return false, d
+	}
+	return true, ""
+}
+
+func decodeChart(c *Chart) (interface{}, error) {
+	if c == nil {
+		return nil, nil
+	}
+	buf, err := json.Marshal(c)
+	if err != nil {
+		return nil, fmt.Errorf("chart: %v", err)
+	}
+	var v interface{}
+	err = json.Unmarshal(buf, &v)
+	return v, err
+}
+
+func emptyJSON(v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case []interface{}:
+		return len(v) == 0
+	case map[string]interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+func diff(path string, a, b interface{}) string {
+	if emptyJSON(a) && emptyJSON(b) {
+		return ""
+	}
+	root := path
+	if root == "" {
+		root = "."
+	}
+	switch a := a.(type) {
+	case map[string]interface{}:
+		b, ok := b.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(a)+len(b))
+		for k := range a {
+			keys = append(keys, k)
+		}
+		for k := range b {
+			if _, ok := a[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			p := k
+			if path != "" {
+				p = path + "." + k
+			}
+			if d := diff(p, a[k], b[k]); d != "" {
+				return d
+			}
+		}
+		return ""
+	case []interface{}:
+		b, ok := b.([]interface{})
+		if !ok {
+			break
+		}
+		if len(a) != len(b) {
+			return fmt.Sprintf("%s: length %d != %d", root, len(a), len(b))
+		}
+		for i := range a {
+			if d := diff(fmt.Sprintf("%s[%d]", path, i), a[i], b[i]); d != "" {
+				return d
+			}
+		}
+		return ""
+	default:
+		if a == b {
+			return ""
+		}
+	}
+	return fmt.Sprintf("%s: %s != %s", root, jsonString(a), jsonString(b))
+}
+
+func jsonString(v interface{}) string {
+	buf, _ := json.Marshal(v)
+	return string(buf)
+}
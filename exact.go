@@ -0,0 +1,133 @@
+package chartjs
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+)
+
+// Decimal is implemented by arbitrary-precision decimal types, e.g.
+// decimal.Decimal of github.com/shopspring/decimal.
+type Decimal interface {
+	// String returns the exact value in decimal notation.
+	String() string
+	// Float64 returns the nearest float64.
+	Float64() (f float64, exact bool)
+}
+
+// exactValues are Values written with the exact text of each value instead
+// of its float64 formatted by XFloatFormat and YFloatFormat. An empty text
+// is written as null. The float64 values serve the other helpers, e.g.
+// alerts and RenderSVG.
+type exactValues struct {
+	xyValues
+	xtext, ytext []string
+}
+
+// MarshalJSON implements json.Marshaler interface.
+func (v exactValues) MarshalJSON() ([]byte, error) {
+	if v.xtext != nil && len(v.xtext) != len(v.ytext) {
+		return nil, fmt.Errorf("chart: bad format of Values. X and Y must be of the same length")
+	}
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for i, y := range v.ytext {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		if v.xtext == nil {
+			if err := writeExact(&buf, y); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		buf.WriteString(`{"x":`)
+		if err := writeExact(&buf, v.xtext[i]); err != nil {
+			return nil, err
+		}
+		buf.WriteString(`,"y":`)
+		if err := writeExact(&buf, y); err != nil {
+			return nil, err
+		}
+		buf.WriteByte('}')
+	}
+	buf.WriteByte(']')
+	return buf.Bytes(), nil
+}
+
+var jsonNumber = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
+func writeExact(buf *bytes.Buffer, s string) error {
+	if s == "" {
+		buf.WriteString("null")
+		return nil
+	}
+	// the value may be out of the range of float64, so check only the syntax.
+	if !jsonNumber.MatchString(s) {
+		return fmt.Errorf("chart: value %q is not a JSON number", s)
+	}
+	buf.WriteString(s)
+	return nil
+}
+
+// bigTexts returns the shortest texts that read back as the values, and
+// the nearest float64 values. Nil and infinite values are written as null.
+func bigTexts(vs []*big.Float) ([]string, []float64) {
+	if vs == nil {
+		return nil, nil
+	}
+	texts, fs := make([]string, len(vs)), make([]float64, len(vs))
+	for i, v := range vs {
+		if v == nil || v.IsInf() {
+			fs[i] = math.NaN()
+			continue
+		}
+		texts[i] = v.Text('g', -1)
+		fs[i], _ = v.Float64()
+	}
+	return texts, fs
+}
+
+func decimalTexts[D Decimal](vs []D) ([]string, []float64) {
+	if vs == nil {
+		return nil, nil
+	}
+	texts, fs := make([]string, len(vs)), make([]float64, len(vs))
+	for i, v := range vs {
+		texts[i] = v.String()
+		fs[i], _ = v.Float64()
+	}
+	return texts, fs
+}
+
+// BigFloats returns Values of a single series written exactly, in the
+// shortest decimal form that reads back as each value at its precision.
+func BigFloats(ys []*big.Float) Values {
+	texts, fs := bigTexts(ys)
+	return exactValues{xyValues: xyValues{xs: fs}, ytext: texts}
+}
+
+// BigFloatXY returns Values of the points (xs[i], ys[i]) written exactly,
+// see BigFloats.
+func BigFloatXY(xs, ys []*big.Float) Values {
+	xtext, xfs := bigTexts(xs)
+	ytext, yfs := bigTexts(ys)
+	return exactValues{xyValues: xyValues{xs: xfs, ys: yfs}, xtext: xtext, ytext: ytext}
+}
+
+// Decimals returns Values of a single series of decimals, e.g.
+// []decimal.Decimal, written exactly as their String.
+func Decimals[D Decimal](ys []D) Values {
+	texts, fs := decimalTexts(ys)
+	return exactValues{xyValues: xyValues{xs: fs}, ytext: texts}
+}
+
+// DecimalXY returns Values of the points (xs[i], ys[i]) of decimals written
+// exactly, see Decimals.
+func DecimalXY[D Decimal](xs, ys []D) Values {
+	xtext, xfs := decimalTexts(xs)
+	ytext, yfs := decimalTexts(ys)
+	return exactValues{xyValues: xyValues{xs: xfs, ys: yfs}, xtext: xtext, ytext: ytext}
+}
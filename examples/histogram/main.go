@@ -139,7 +139,7 @@ func main() {
 	wtr, err := os.Create("hist.html")
 
 	check(err)
-	if err := chart.SaveHTML(wtr, nil); err != nil {
+	if err := chart.SaveHTML(wtr, chartjs.RenderOptions{}); err != nil {
 		log.Fatal(err)
 	}
 	wtr.Close()
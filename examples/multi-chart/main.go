@@ -79,7 +79,7 @@ func main() {
 	wtr, err := os.Create("example-chartjs-multi.html")
 	if err != nil {
 	}
-	if err := chart.SaveHTML(wtr, nil); err != nil {
+	if err := chart.SaveHTML(wtr, chartjs.RenderOptions{}); err != nil {
 		log.Fatal(err)
 	}
 	wtr.Close()
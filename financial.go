@@ -0,0 +1,46 @@
+package chartjs
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+)
+
+// FinancialValues are the open, high, low and close prices of a series of
+// periods, plotted by Candlestick and OHLC charts with chartjs-chart-financial.
+type FinancialValues interface {
+	Time() []time.Time
+	Open() []float64
+	High() []float64
+	Low() []float64
+	Close() []float64
+}
+
+// marshalFinancialValuesJSON emits {x, o, h, l, c} points with x in epoch
+// milliseconds.
+func marshalFinancialValuesJSON(v FinancialValues, format string) ([]byte, error) {
+	ts, op, hi, lo, cl := v.Time(), v.Open(), v.High(), v.Low(), v.Close()
+	if len(op) != len(ts) || len(hi) != len(ts) || len(lo) != len(ts) || len(cl) != len(ts) {
+		return nil, fmt.Errorf("chart: bad format of FinancialValues. All fields must be of the same length")
+	}
+	buf := bytes.NewBuffer(make([]byte, 0, 64*len(ts)))
+	buf.WriteRune('[')
+	for i, t := range ts {
+		if i > 0 {
+			buf.WriteRune(',')
+		}
+		fmt.Fprintf(buf, `{"x":%d`, t.UnixNano()/int64(time.Millisecond))
+		for _, f := range []struct {
+			key string
+			v   float64
+		}{{"o", op[i]}, {"h", hi[i]}, {"l", lo[i]}, {"c", cl[i]}} {
+			buf.WriteString(`,"` + f.key + `":`)
+			if err := writeFloat(buf, format, f.v); err != nil {
+				return nil, err
+			}
+		}
+		buf.WriteRune('}')
+	}
+	buf.WriteRune(']')
+	return buf.Bytes(), nil
+}
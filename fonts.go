@@ -0,0 +1,105 @@
+package chartjs
+
+import (
+	"encoding/base64"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// WebFont is a font loaded by the generated page with @font-face. Pass a
+// []WebFont as "fonts" in the SaveCharts tmap. The first family also becomes
+// the default chart font unless the page defaults set one.
+type WebFont struct {
+	Family string
+	// URL of the font file.
+	URL string
+	// Data holds the font file, e.g. from go:embed, and is inlined in the
+	// page instead of URL.
+	Data []byte
+	// Format is "woff2", "woff", "truetype" or "opentype". It is guessed
+	// from the URL extension if empty, and defaults to "woff2".
+	Format string
+	// Weight and Style are the font-weight and font-style descriptors.
+	Weight, Style string
+}
+
+var fontFormats = map[string]string{
+	".woff2": "woff2",
+	".woff":  "woff",
+	".ttf":   "truetype",
+	".otf":   "opentype",
+}
+
+func (f WebFont) format() string {
+	if f.Format != "" {
+		return f.Format
+	}
+	if ff, ok := fontFormats[strings.ToLower(path.Ext(f.URL))]; ok && f.Data == nil {
+		return ff
+	}
+	return "woff2"
+}
+
+var (
+	fontWeight = regexp.MustCompile(`^(normal|bold|[1-9]00)$`)
+	fontStyle  = regexp.MustCompile(`^(normal|italic|oblique)$`)
+)
+
+// css returns the @font-face rule of the font.
+func (f WebFont) css() (string, error) {
+	if f.Family == "" || strings.ContainsAny(f.Family, `"\;{}`) {
+		return "", fmt.Errorf("chart: bad font family %q", f.Family)
+	}
+	format := f.format()
+	src := f.URL
+	if f.Data != nil {
+		mime := "font/" + format
+		if format == "truetype" {
+			mime = "font/ttf"
+		} else if format == "opentype" {
+			mime = "font/otf"
+		}
+		src = "data:" + mime + ";base64," + base64.StdEncoding.EncodeToString(f.Data)
+	}
+	if src == "" || strings.ContainsAny(src, `"\`) {
+		return "", fmt.Errorf("chart: bad source for font %q", f.Family)
+	}
+	if f.Weight != "" && !fontWeight.MatchString(f.Weight) {
+		return "", fmt.Errorf("chart: bad weight %q of font %q", f.Weight, f.Family)
+	}
+	if f.Style != "" && !fontStyle.MatchString(f.Style) {
+		return "", fmt.Errorf("chart: bad style %q of font %q", f.Style, f.Family)
+	}
+	css := fmt.Sprintf("@font-face { font-family: \"%s\"; src: url(\"%s\") format(\"%s\");", f.Family, src, format)
+	if f.Weight != "" {
+		css += " font-weight: " + f.Weight + ";"
+	}
+	if f.Style != "" {
+		css += " font-style: " + f.Style + ";"
+	}
+	return css + " }\n", nil
+}
+
+// fontCSS returns the @font-face rules of the fonts.
+func fontCSS(fonts []WebFont) (string, error) {
+	var css string
+	for _, f := range fonts {
+		c, err := f.css()
+		if err != nil {
+			return "", err
+		}
+		css += c
+	}
+	return css, nil
+}
+
+// fontsReadyJS redraws the charts once the web fonts are loaded, as canvas
+// text drawn earlier uses a fallback font.
+const fontsReadyJS = `if (document.fonts) {
+	document.fonts.ready.then(function() {
+		charts.forEach(function(c) { if (c) { c.update(); } });
+	});
+}
+`
@@ -0,0 +1,55 @@
+package chartjs
+
+import (
+	"bytes"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// Handler serves the chart as an HTML page, and its config at the data.json
+// sub-path. Mount it on a subtree, e.g.
+//
+//	mux.Handle("/cpu/", chartjs.Handler(c))
+//
+// to render the chart at /cpu/ and serve its config at /cpu/data.json.
+// The config is also served in the other formats of Encoders, e.g. as a
+// javascript module at /cpu/data.mjs. The chart is rendered on every
+// request, so changes to c show up on reload.
+func Handler(c *Chart) http.Handler {
+	return PersonalizedHandler(c, nil)
+}
+
+// PersonalizedHandler is Handler serving the chart returned by p for each
+// request, see Personalizer.
+func PersonalizedHandler(c *Chart, p Personalizer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c := personalize(p, r, c)
+		if c == nil {
+			http.NotFound(w, r)
+			return
+		}
+		// render to a buffer so that errors can still be reported.
+		var buf bytes.Buffer
+		if name := path.Base(r.URL.Path); strings.HasPrefix(name, "data.") {
+			e, ok := EncoderFor(name)
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			if err := e.Encode(&buf, *c); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			w.Header().Set("Content-Type", e.ContentType())
+			w.Write(buf.Bytes())
+			return
+		}
+		if err := c.SaveHTML(&buf, RenderOptions{}); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(buf.Bytes())
+	})
+}
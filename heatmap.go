@@ -0,0 +1,41 @@
+package chartjs
+
+import "github.com/iszk1215/go-chartjs/types"
+
+// stackedHeatmap draws counts[column][row] as stacked bars with one segment
+// per row, colored by cmap from its share of the largest count. Rows are
+// stacked from the bottom. cmap defaults to types.Viridis.
+func stackedHeatmap(columns, rows []string, counts [][]float64, cmap types.Colormap) *Chart {
+	if cmap == nil {
+		cmap = types.Viridis
+	}
+	var max float64
+	for _, col := range counts {
+		for _, n := range col {
+			if n > max {
+				max = n
+			}
+		}
+	}
+
+	c := &Chart{Type: Bar, Data: Data{Labels: columns}}
+	c.AddAxis(Axis{ID: "x", Type: Category, Stacked: True})
+	c.AddAxis(Axis{ID: "y", Type: Linear, Stacked: True, Display: False})
+	ones := make([]float64, len(columns))
+	for i := range ones {
+		ones[i] = 1
+	}
+	for r, label := range rows {
+		d := Dataset{Label: label, Data: xyValues{xs: ones}, BackgroundColors: make([]types.RGBA, len(columns))}
+		for i := range columns {
+			t := 0.0
+			if max > 0 {
+				t = counts[i][r] / max
+			}
+			d.BackgroundColors[i] = cmap(t)
+		}
+		c.AddDataset(d)
+	}
+	c.Options.Legend = &Legend{Display: False}
+	return c
+}
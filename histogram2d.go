@@ -0,0 +1,131 @@
+package chartjs
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/iszk1215/go-chartjs/types"
+)
+
+// Histogram2D bins (x, y) samples into a grid, for point clouds too dense
+// to read as a scatter plot.
+type Histogram2D struct {
+	XBins, YBins int
+	// XRange and YRange bound the grid. They default to the range of the data.
+	XRange, YRange [2]float64
+	// Colormap colors the cells, types.Viridis by default.
+	Colormap types.Colormap
+	// Format formats bin edges in labels, "%.2f" by default.
+	Format string
+}
+
+// span returns r, or the range of vs when r is unset.
+func span(r [2]float64, vs []float64) (float64, float64) {
+	if r[0] != r[1] {
+		return r[0], r[1]
+	}
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, v := range vs {
+		if !math.IsNaN(v) {
+			lo, hi = math.Min(lo, v), math.Max(hi, v)
+		}
+	}
+	if hi <= lo {
+		hi = lo + 1
+	}
+	return lo, hi
+}
+
+func bin(v, lo, hi float64, n int) int {
+	i := int(float64(n) * (v - lo) / (hi - lo))
+	if i == n && v == hi {
+		i--
+	}
+	return i
+}
+
+// Counts returns counts[xbin][ybin] and the bin edges. Samples outside the
+// ranges and NaNs are dropped.
+func (h Histogram2D) Counts(xs, ys []float64) (counts [][]float64, xedges, yedges []float64, err error) {
+	return h.WeightedCounts(xs, ys, nil)
+}
+
+// WeightedCounts is like Counts with each sample counted weights[i] times.
+func (h Histogram2D) WeightedCounts(xs, ys, weights []float64) (counts [][]float64, xedges, yedges []float64, err error) {
+	if err := checkWeights(len(xs), weights); err != nil {
+		return nil, nil, nil, err
+	}
+	if len(xs) != len(ys) {
+		return nil, nil, nil, fmt.Errorf("chart: bad format of Values. X and Y must be of the same length")
+	}
+	if h.XBins <= 0 || h.YBins <= 0 {
+		return nil, nil, nil, fmt.Errorf("chart: bad number of bins %d x %d", h.XBins, h.YBins)
+	}
+	xlo, xhi := span(h.XRange, xs)
+	ylo, yhi := span(h.YRange, ys)
+	counts = make([][]float64, h.XBins)
+	for i := range counts {
+		counts[i] = make([]float64, h.YBins)
+	}
+	for i, x := range xs {
+		xi, yi := bin(x, xlo, xhi, h.XBins), bin(ys[i], ylo, yhi, h.YBins)
+		if xi < 0 || xi >= h.XBins || yi < 0 || yi >= h.YBins {
+			continue
+		}
+		counts[xi][yi] += weightAt(weights, i)
+	}
+	edges := func(lo, hi float64, n int) []float64 {
+		e := make([]float64, n+1)
+		for i := range e {
+			e[i] = lo + (hi-lo)*float64(i)/float64(n)
+		}
+		return e
+	}
+	return counts, edges(xlo, xhi, h.XBins), edges(ylo, yhi, h.YBins), nil
+}
+
+// Chart returns the histogram as a heatmap with one column per x bin and one
+// row per y bin, labeled by the bin starts.
+func (h Histogram2D) Chart(xs, ys []float64) (*Chart, error) {
+	return h.WeightedChart(xs, ys, nil)
+}
+
+// WeightedChart is like Chart with each sample counted weights[i] times.
+func (h Histogram2D) WeightedChart(xs, ys, weights []float64) (*Chart, error) {
+	counts, xedges, yedges, err := h.WeightedCounts(xs, ys, weights)
+	if err != nil {
+		return nil, err
+	}
+	format := h.Format
+	if format == "" {
+		format = "%.2f"
+	}
+	labels := func(edges []float64) []string {
+		ls := make([]string, len(edges)-1)
+		for i := range ls {
+			ls[i] = fmt.Sprintf(format, edges[i])
+		}
+		return ls
+	}
+	return stackedHeatmap(labels(xedges), labels(yedges), counts, h.Colormap), nil
+}
+
+// DensityScatter returns a scatter plot of many points drawn small and
+// translucent, so that overlapping points blend into a density.
+func DensityScatter(xs, ys []float64, color types.RGBA) (*Chart, error) {
+	c, err := NewScatter(xs, ys)
+	if err != nil {
+		return nil, err
+	}
+	alpha := 1.0
+	if len(xs) > 0 {
+		alpha = math.Max(0.02, math.Min(1, 100/float64(len(xs))))
+	}
+	fill := color.WithAlpha(alpha)
+	d := &c.Data.Datasets[0]
+	d.PointBackgroundColor = &fill
+	d.PointBorderWidth = 0
+	d.PointRadius = 1.5
+	d.PointHoverRadius = 3
+	return c, nil
+}
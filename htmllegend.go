@@ -0,0 +1,40 @@
+package chartjs
+
+// htmlLegendJS is the client side of Legend.HTML. It lists the datasets, or
+// the labels of Doughnut and PolarArea charts, with checkboxes toggling them.
+const htmlLegendJS = `function chartjsHTMLLegend(chart, index) {
+	var list = document.getElementById("legend" + index);
+	if (!list) { return; }
+	var type = chart.config.type;
+	var perLabel = type === "doughnut" || type === "pie" || type === "polarArea";
+	var items = perLabel ? (chart.data.labels || []) : chart.data.datasets.map(function(d) { return d.label; });
+	list.innerHTML = "";
+	items.forEach(function(text, i) {
+		var ds = (perLabel ? chart.data.datasets[0] : chart.data.datasets[i]) || {};
+		var color = ds.backgroundColor || ds.borderColor;
+		if (Array.isArray(color)) { color = color[perLabel ? i : 0]; }
+		var li = document.createElement("li");
+		var label = document.createElement("label");
+		var box = document.createElement("input");
+		box.type = "checkbox";
+		box.checked = perLabel || !chart.getDatasetMeta(i).hidden;
+		box.addEventListener("change", function() {
+			if (!perLabel) {
+				chart.getDatasetMeta(i).hidden = !box.checked;
+			} else if (chart.toggleDataVisibility) {
+				chart.toggleDataVisibility(i);
+			} else {
+				chart.getDatasetMeta(0).data[i].hidden = !box.checked;
+			}
+			chart.update();
+		});
+		var swatch = document.createElement("span");
+		swatch.style.cssText = "display:inline-block;width:12px;height:12px;margin:0 6px;vertical-align:middle";
+		swatch.style.background = color || "#888";
+		label.appendChild(box);
+		label.appendChild(swatch);
+		label.appendChild(document.createTextNode(text == null ? "" : String(text)));
+		li.appendChild(label);
+		list.appendChild(li);
+	});
+}`
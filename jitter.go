@@ -0,0 +1,53 @@
+package chartjs
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// Jitter spreads overplotted points, e.g. a scatter of categorical x values,
+// by displacing each point by a uniform random amount. The displacement is
+// drawn from a generator seeded with Seed, so that the same data gives the
+// same chart, e.g. in golden-file tests.
+type Jitter struct {
+	// Width and Height are the total spread along x and y. A point moves by
+	// at most half of them either way.
+	Width, Height float64
+	Seed          int64
+}
+
+// Apply returns v with its points displaced. Rs are kept as they are.
+func (j Jitter) Apply(v Values) Values {
+	return j.apply(v, rand.New(rand.NewSource(j.Seed)))
+}
+
+func (j Jitter) apply(v Values, r *rand.Rand) Values {
+	spread := func(vs []float64, w float64) []float64 {
+		if vs == nil {
+			return nil
+		}
+		out := make([]float64, len(vs))
+		for i, x := range vs {
+			out[i] = x
+			if w != 0 {
+				out[i] += (r.Float64() - 0.5) * w
+			}
+		}
+		return out
+	}
+	return xyValues{xs: spread(v.Xs(), j.Width), ys: spread(v.Ys(), j.Height), rs: v.Rs()}
+}
+
+// Jitter displaces the points of every dataset of the chart, see Jitter.
+// The datasets share one generator, so the result depends on their order.
+func (c *Chart) Jitter(j Jitter) error {
+	r := rand.New(rand.NewSource(j.Seed))
+	for i, d := range c.Data.Datasets {
+		v, ok := d.Data.(Values)
+		if !ok {
+			return fmt.Errorf("chart: dataset %q does not hold Values", d.Label)
+		}
+		c.Data.Datasets[i].Data = j.apply(v, r)
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package chartjs
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// jsTag marks JSON strings that hold JavaScript source. It carries a random
+// nonce so that labels from untrusted sources cannot be mistaken for code.
+var jsTag = newJSTag()
+
+const jsTagPrefix = "__chartjs_js_"
+
+func newJSTag() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return jsTagPrefix + hex.EncodeToString(b) + ":"
+}
+
+// JSFunc is JavaScript source, usually a function expression, placed in a chart
+// config. JSON cannot carry functions, so it is marshaled as a tagged string
+// which the HTML renderer replaces with the code itself. Chart.MarshalJSON
+// removes the tags, leaving strings of the code.
+type JSFunc string
+
+// MarshalJSON implements json.Marshaler interface.
+func (f JSFunc) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsTag + string(f))
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface. The tag of the
+// marshaled string is removed, whichever process wrote it.
+func (f *JSFunc) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	if strings.HasPrefix(s, jsTagPrefix) && len(s) > len(jsTag)-1 && s[len(jsTag)-1] == ':' {
+		s = s[len(jsTag):]
+	}
+	*f = JSFunc(s)
+	return nil
+}
+
+// InlineJS replaces the strings marshaled from JSFunc values in b, e.g. the
+// output of Chart.MarshalTagged, with their code. The result is a javascript
+// object literal, as embedded by SaveCharts.
+func InlineJS(b []byte) []byte {
+	return inlineJS(b)
+}
+
+// inlineJS replaces the tagged strings produced by JSFunc with their code.
+func inlineJS(b []byte) []byte {
+	tag := []byte(`"` + jsTag)
+	if !bytes.Contains(b, tag) {
+		return b
+	}
+	out := make([]byte, 0, len(b))
+	for {
+		i := bytes.Index(b, tag)
+		if i < 0 {
+			return append(out, b...)
+		}
+		out = append(out, b[:i]...)
+		// find the closing quote, skipping escaped characters.
+		j := i + 1
+		for ; j < len(b) && b[j] != '"'; j++ {
+			if b[j] == '\\' {
+				j++
+			}
+		}
+		var code string
+		if j >= len(b) || json.Unmarshal(b[i:j+1], &code) != nil {
+			return append(out, b[i:]...)
+		}
+		code = strings.Replace(strings.TrimPrefix(code, jsTag), "</", `<\/`, -1)
+		out = append(out, code...)
+		b = b[j+1:]
+	}
+}
+
+// StripJS removes the tags of the strings marshaled from JSFunc values in b,
+// the JSON of a chart, leaving their code as plain strings. It is used for
+// JSON read by programs rather than embedded in pages, which would otherwise
+// carry the tag.
+func StripJS(b []byte) []byte {
+	tag := []byte(jsTag)
+	if !bytes.Contains(b, tag) {
+		return b
+	}
+	return bytes.Replace(b, tag, nil, -1)
+}
+
+// jsStripper is a writer removing the tags of JSFunc strings from JSON
+// streamed through it. Bytes which may start a tag are held back until the
+// next Write or Flush.
+type jsStripper struct {
+	w       io.Writer
+	pending []byte
+}
+
+// Write implements io.Writer.
+func (s *jsStripper) Write(p []byte) (int, error) {
+	tag := []byte(jsTag)
+	b := append(s.pending, p...)
+	for {
+		i := bytes.Index(b, tag)
+		if i < 0 {
+			break
+		}
+		if _, err := s.w.Write(b[:i]); err != nil {
+			return 0, err
+		}
+		b = b[i+len(tag):]
+	}
+	// hold back the longest end of b starting the tag.
+	keep := len(tag) - 1
+	if keep > len(b) {
+		keep = len(b)
+	}
+	for keep > 0 && !bytes.HasPrefix(tag, b[len(b)-keep:]) {
+		keep--
+	}
+	if _, err := s.w.Write(b[:len(b)-keep]); err != nil {
+		return 0, err
+	}
+	s.pending = append(s.pending[:0:0], b[len(b)-keep:]...)
+	return len(p), nil
+}
+
+// Flush writes the bytes held back.
+func (s *jsStripper) Flush() error {
+	_, err := s.w.Write(s.pending)
+	s.pending = nil
+	return err
+}
@@ -0,0 +1,141 @@
+package chartjs
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/iszk1215/go-chartjs/types"
+)
+
+// KDE2D estimates the density of scattered points with a gaussian kernel and
+// draws iso-density bands under them, approximating a contour plot.
+//
+// Each band covers, for every column of the grid, the y-range where the
+// density is above its level, so separate clusters at the same x are merged.
+type KDE2D struct {
+	// Bandwidth of the kernel in x and y, by Scott's rule by default.
+	Bandwidth [2]float64
+	// GridSize is the number of grid points along each axis, 50 by default.
+	GridSize int
+	// Levels are the densities of the bands as fractions of the largest
+	// density, 0.25, 0.5 and 0.75 by default.
+	Levels []float64
+	// Color of the bands, drawn translucent so that bands stack up.
+	Color types.RGBA
+}
+
+func stddev(vs []float64) float64 {
+	var sum, sq float64
+	for _, v := range vs {
+		sum += v
+		sq += v * v
+	}
+	n := float64(len(vs))
+	return math.Sqrt(math.Max(sq/n-(sum/n)*(sum/n), 0))
+}
+
+// Density returns the density d[i][j] at (gx[i], gy[j]) on a grid spanning
+// the points and two bandwidths around them.
+func (k KDE2D) Density(xs, ys []float64) (gx, gy []float64, d [][]float64, err error) {
+	if len(xs) != len(ys) {
+		return nil, nil, nil, fmt.Errorf("chart: bad format of Values. X and Y must be of the same length")
+	}
+	if len(xs) < 2 {
+		return nil, nil, nil, fmt.Errorf("chart: need at least 2 points for a density estimate")
+	}
+	n := k.GridSize
+	if n <= 1 {
+		n = 50
+	}
+	scott := math.Pow(float64(len(xs)), -1.0/6)
+	hx, hy := k.Bandwidth[0], k.Bandwidth[1]
+	if hx <= 0 {
+		hx = stddev(xs) * scott
+	}
+	if hy <= 0 {
+		hy = stddev(ys) * scott
+	}
+	if hx <= 0 || hy <= 0 {
+		return nil, nil, nil, fmt.Errorf("chart: points have no spread for a density estimate")
+	}
+	grid := func(vs []float64, h float64) []float64 {
+		lo, hi := span([2]float64{}, vs)
+		lo, hi = lo-2*h, hi+2*h
+		g := make([]float64, n)
+		for i := range g {
+			g[i] = lo + (hi-lo)*float64(i)/float64(n-1)
+		}
+		return g
+	}
+	gx, gy = grid(xs, hx), grid(ys, hy)
+	d = make([][]float64, n)
+	norm := 1 / (2 * math.Pi * hx * hy * float64(len(xs)))
+	for i, x := range gx {
+		d[i] = make([]float64, n)
+		for j, y := range gy {
+			var sum float64
+			for p := range xs {
+				u, v := (x-xs[p])/hx, (y-ys[p])/hy
+				sum += math.Exp(-(u*u + v*v) / 2)
+			}
+			d[i][j] = sum * norm
+		}
+	}
+	return gx, gy, d, nil
+}
+
+// Datasets returns two datasets per level, the lower and upper edge of the
+// band, drawn as lines filled between each other.
+func (k KDE2D) Datasets(xs, ys []float64) ([]Dataset, error) {
+	gx, gy, d, err := k.Density(xs, ys)
+	if err != nil {
+		return nil, err
+	}
+	var max float64
+	for _, col := range d {
+		for _, v := range col {
+			max = math.Max(max, v)
+		}
+	}
+	levels := k.Levels
+	if levels == nil {
+		levels = []float64{0.25, 0.5, 0.75}
+	}
+	fill := k.Color.WithAlpha(0.2)
+	var ds []Dataset
+	for _, level := range levels {
+		lower := xyValues{xs: gx, ys: make([]float64, len(gx))}
+		upper := xyValues{xs: gx, ys: make([]float64, len(gx))}
+		for i, col := range d {
+			lower.ys[i], upper.ys[i] = math.NaN(), math.NaN()
+			for j, v := range col {
+				if v < level*max {
+					continue
+				}
+				if math.IsNaN(lower.ys[i]) {
+					lower.ys[i] = gy[j]
+				}
+				upper.ys[i] = gy[j]
+			}
+		}
+		label := fmt.Sprintf("density ≥ %g%%", level*100)
+		ds = append(ds,
+			Dataset{Label: label, Data: lower, Fill: False, ShowLine: True, Order: 1},
+			Dataset{Label: label, Data: upper, FillTarget: "-1", BackgroundColor: &fill, ShowLine: True, Order: 1},
+		)
+	}
+	return ds, nil
+}
+
+// AddDensityBands adds the density bands of the points under the existing
+// datasets of the chart.
+func (c *Chart) AddDensityBands(k KDE2D, xs, ys []float64) error {
+	ds, err := k.Datasets(xs, ys)
+	if err != nil {
+		return err
+	}
+	for _, d := range ds {
+		c.AddDataset(d)
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package chartjs
+
+import (
+	"html/template"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// WrapLabel splits a label into lines of at most width characters, breaking
+// at spaces where possible.
+func WrapLabel(label string, width int) []string {
+	if width <= 0 || utf8.RuneCountInString(label) <= width {
+		return []string{label}
+	}
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(label) {
+		for utf8.RuneCountInString(word) > width {
+			if line != "" {
+				lines = append(lines, line)
+				line = ""
+			}
+			r := []rune(word)
+			lines = append(lines, string(r[:width]))
+			word = string(r[width:])
+		}
+		switch {
+		case line == "":
+			line = word
+		case utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) <= width:
+			line += " " + word
+		default:
+			lines = append(lines, line)
+			line = word
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// TruncateLabel shortens a label to at most max characters, ending it with
+// an ellipsis.
+func TruncateLabel(label string, max int) string {
+	r := []rune(label)
+	if max <= 0 || len(r) <= max {
+		return label
+	}
+	return string(r[:max-1]) + "…"
+}
+
+// FullLabelTitle is a tooltip title callback showing the untruncated label.
+// It works with the callback arguments of chart.js 2 and of later versions.
+const FullLabelTitle template.JSStr = `function(items, data) {
+	if (!items.length) { return ""; }
+	var item = items[0];
+	data = data || item.chart.data;
+	var i = item.dataIndex !== undefined ? item.dataIndex : item.index;
+	return (data.fullLabels || data.labels)[i];
+}`
+
+// WrapLabels splits the category labels into lines of at most width characters.
+func (c *Chart) WrapLabels(width int) {
+	c.Data.LabelLines = make([][]string, len(c.Data.Labels))
+	for i, l := range c.Data.Labels {
+		c.Data.LabelLines[i] = WrapLabel(l, width)
+	}
+}
+
+// TruncateLabels shortens the category labels to max characters and shows
+// the full labels in tooltips.
+func (c *Chart) TruncateLabels(max int) {
+	c.setShortLabels(func(l string) string { return TruncateLabel(l, max) })
+}
+
+// setShortLabels replaces the labels with short ones, keeping the originals
+// for the tooltip title.
+func (c *Chart) setShortLabels(short func(string) string) {
+	if c.Data.FullLabels == nil {
+		c.Data.FullLabels = c.Data.Labels
+	}
+	labels := make([]string, len(c.Data.FullLabels))
+	for i, l := range c.Data.FullLabels {
+		labels[i] = short(l)
+	}
+	c.Data.Labels = labels
+	c.tooltipCallbacks().Title = FullLabelTitle
+}
+
+type abbreviation int
+
+const (
+	// MiddleEllipsis keeps the start and end of a label, e.g. "kube…ode-1".
+	MiddleEllipsis abbreviation = iota
+	// Initialism keeps the first letter of each word, e.g. "CRM".
+	Initialism
+)
+
+// AbbreviateLabel shortens a label to at most max characters.
+func AbbreviateLabel(label string, max int, mode abbreviation) string {
+	r := []rune(label)
+	if max <= 0 || len(r) <= max {
+		return label
+	}
+	if mode == Initialism {
+		words := strings.FieldsFunc(label, func(c rune) bool {
+			return c == ' ' || c == '-' || c == '_' || c == '/' || c == '.'
+		})
+		var init []rune
+		for _, w := range words {
+			first, _ := utf8.DecodeRuneInString(w)
+			init = append(init, []rune(strings.ToUpper(string(first)))...)
+		}
+		if len(init) > 1 && len(init) <= max {
+			return string(init)
+		}
+	}
+	if max < 3 {
+		return TruncateLabel(label, max)
+	}
+	head := max / 2
+	tail := max - 1 - head
+	return string(r[:head]) + "…" + string(r[len(r)-tail:])
+}
+
+// AbbreviateLabels shortens the category labels to max characters and shows
+// the full labels in tooltips. Labels that would abbreviate to the text of
+// an earlier label are abbreviated further to make room for a numeric
+// suffix, so that categories stay distinct.
+func (c *Chart) AbbreviateLabels(max int, mode abbreviation) {
+	emitted := map[string]bool{}
+	c.setShortLabels(func(l string) string {
+		a := AbbreviateLabel(l, max, mode)
+		for n := 2; emitted[a]; n++ {
+			suffix := strconv.Itoa(n)
+			room := max - len(suffix)
+			if max > 0 && room < 1 {
+				// only the suffix fits.
+				a = suffix
+				continue
+			}
+			a = AbbreviateLabel(l, room, mode) + suffix
+		}
+		emitted[a] = true
+		return a
+	})
+}
@@ -0,0 +1,155 @@
+package chartjs
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/iszk1215/go-chartjs/types"
+)
+
+// HistogramSnapshot is a latency histogram at a point in time, in the layout of
+// OpenTelemetry explicit-bucket histograms: Counts[i] counts the values up to
+// Bounds[i], and the last count the values above the last bound.
+type HistogramSnapshot struct {
+	Time   time.Time
+	Bounds []float64
+	Counts []uint64
+}
+
+func (h HistogramSnapshot) check() error {
+	if len(h.Counts) != len(h.Bounds)+1 {
+		return fmt.Errorf("chart: histogram at %v has %d counts for %d bounds", h.Time, len(h.Counts), len(h.Bounds))
+	}
+	return nil
+}
+
+// Total returns the number of values in the histogram.
+func (h HistogramSnapshot) Total() uint64 {
+	var n uint64
+	for _, c := range h.Counts {
+		n += c
+	}
+	return n
+}
+
+// Quantile estimates the q-quantile, e.g. 0.99 for p99, by interpolating
+// linearly within the bucket that holds it. Values in the overflow bucket
+// are reported as the last bound. It returns NaN for an empty histogram.
+func (h HistogramSnapshot) Quantile(q float64) float64 {
+	total := h.Total()
+	if total == 0 || len(h.Bounds) == 0 {
+		return math.NaN()
+	}
+	rank := q * float64(total)
+	var seen float64
+	for i, c := range h.Counts {
+		if i == len(h.Bounds) {
+			break
+		}
+		if c > 0 && seen+float64(c) >= rank {
+			lo := 0.0
+			if i > 0 {
+				lo = h.Bounds[i-1]
+			}
+			return lo + (h.Bounds[i]-lo)*(rank-seen)/float64(c)
+		}
+		seen += float64(c)
+	}
+	return h.Bounds[len(h.Bounds)-1]
+}
+
+// HistogramDeltas turns cumulative snapshots, as exported by HDR histograms
+// and cumulative OpenTelemetry metrics, into the counts of each interval.
+// A count going down is taken as a reset.
+func HistogramDeltas(snaps []HistogramSnapshot) ([]HistogramSnapshot, error) {
+	out := make([]HistogramSnapshot, 0, len(snaps))
+	for i, s := range snaps {
+		if err := s.check(); err != nil {
+			return nil, err
+		}
+		if i == 0 {
+			continue
+		}
+		prev := snaps[i-1]
+		d := HistogramSnapshot{Time: s.Time, Bounds: s.Bounds, Counts: make([]uint64, len(s.Counts))}
+		reset := len(prev.Counts) != len(s.Counts)
+		for j := range s.Counts {
+			if reset || s.Counts[j] < prev.Counts[j] {
+				reset = true
+				break
+			}
+			d.Counts[j] = s.Counts[j] - prev.Counts[j]
+		}
+		if reset {
+			copy(d.Counts, s.Counts)
+		}
+		out = append(out, d)
+	}
+	return out, nil
+}
+
+// PercentileChart returns a line chart with one dataset per quantile, e.g.
+// 0.5, 0.9 and 0.99, on a Time x-axis.
+func PercentileChart(snaps []HistogramSnapshot, quantiles ...float64) (*Chart, error) {
+	c := &Chart{Type: Line}
+	if _, err := c.AddXAxis(Axis{Type: Time, Position: Bottom}); err != nil {
+		return nil, err
+	}
+	for i, q := range quantiles {
+		v := xyValues{xs: make([]float64, len(snaps)), ys: make([]float64, len(snaps))}
+		for j, s := range snaps {
+			if err := s.check(); err != nil {
+				return nil, err
+			}
+			v.xs[j] = float64(s.Time.UnixNano() / int64(time.Millisecond))
+			v.ys[j] = s.Quantile(q)
+		}
+		color := DefaultPalette[i%len(DefaultPalette)]
+		c.AddDataset(Dataset{
+			Label:        fmt.Sprintf("p%g", q*100),
+			Data:         v,
+			BorderColor:  &color,
+			XFloatFormat: "%.0f",
+		})
+	}
+	return c, nil
+}
+
+// HeatmapChart returns a time by bucket heatmap of the snapshots, drawn as
+// stacked bars with one segment per bucket colored by its share of the
+// largest count. Times are labeled with layout, and cmap defaults to
+// types.Viridis.
+func HeatmapChart(snaps []HistogramSnapshot, layout string, cmap types.Colormap) (*Chart, error) {
+	if len(snaps) == 0 {
+		return &Chart{Type: Bar}, nil
+	}
+	bounds := snaps[0].Bounds
+	columns := make([]string, len(snaps))
+	counts := make([][]float64, len(snaps))
+	for i, s := range snaps {
+		if err := s.check(); err != nil {
+			return nil, err
+		}
+		if len(s.Bounds) != len(bounds) {
+			return nil, fmt.Errorf("chart: histograms at %v and %v have different buckets", snaps[0].Time, s.Time)
+		}
+		columns[i] = s.Time.Format(layout)
+		counts[i] = make([]float64, len(s.Counts))
+		for b, n := range s.Counts {
+			counts[i][b] = float64(n)
+		}
+	}
+	rows := make([]string, len(bounds)+1)
+	for b := range rows {
+		switch {
+		case b < len(bounds):
+			rows[b] = fmt.Sprintf("≤ %g", bounds[b])
+		case b > 0:
+			rows[b] = fmt.Sprintf("> %g", bounds[b-1])
+		default:
+			rows[b] = "all"
+		}
+	}
+	return stackedHeatmap(columns, rows, counts, cmap), nil
+}
@@ -0,0 +1,16 @@
+package chartjs
+
+// lazyJS builds a chart once its canvas is about to scroll into view. Browsers
+// without IntersectionObserver build it right away.
+const lazyJS = `function chartjsLazy(i, build) {
+	var el = document.getElementById("canvas" + i);
+	if (!("IntersectionObserver" in window) || !el) { build(); return; }
+	var obs = new IntersectionObserver(function(entries) {
+		if (entries.some(function(e) { return e.isIntersecting; })) {
+			obs.disconnect();
+			build();
+		}
+	}, {rootMargin: "200px"});
+	obs.observe(el);
+}
+`
@@ -0,0 +1,138 @@
+package chartjs
+
+import (
+	"fmt"
+
+	"github.com/iszk1215/go-chartjs/types"
+)
+
+type align int
+
+const (
+	// AlignStart aligns to the left or top.
+	AlignStart align = iota + 1
+	// AlignCenter centers, which is the default of chart.js.
+	AlignCenter
+	// AlignEnd aligns to the right or bottom.
+	AlignEnd
+)
+
+var aligns = []string{
+	"",
+	"start",
+	"center",
+	"end",
+}
+
+func (a align) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + aligns[a] + `"`), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface.
+func (a *align) UnmarshalJSON(b []byte) error {
+	i, err := unmarshalEnum(b, aligns, "align")
+	*a = align(i)
+	return err
+}
+
+// Font is a chart.js font. Zero fields take the chart defaults.
+type Font struct {
+	Family string `json:"family,omitempty"`
+	// Size in pixels.
+	Size int `json:"size,omitempty"`
+	// Style is e.g. "italic".
+	Style string `json:"style,omitempty"`
+	// Weight is e.g. "bold" or "600".
+	Weight     string  `json:"weight,omitempty"`
+	LineHeight float64 `json:"lineHeight,omitempty"`
+}
+
+// Legend wraps chartjs "legend".
+type Legend struct {
+	Display types.Bool `json:"display,omitempty"`
+	// Position is Top by default.
+	Position axisPosition `json:"position,omitempty"`
+	Align    align        `json:"align,omitempty"`
+	// Reverse shows the datasets in reverse order.
+	Reverse types.Bool    `json:"reverse,omitempty"`
+	Labels  *LegendLabels `json:"labels,omitempty"`
+
+	// OnClick is called with the event, the legend item and the legend. It
+	// hides the dataset of the item by default.
+	OnClick JSFunc `json:"onClick,omitempty"`
+	// OnHover and OnLeave are called like OnClick when the mouse enters and
+	// leaves a legend item.
+	OnHover JSFunc `json:"onHover,omitempty"`
+	OnLeave JSFunc `json:"onLeave,omitempty"`
+
+	// MaxItems caps the number of items shown, the first ones of the
+	// datasets, or of the labels for Doughnut and PolarArea charts. It is
+	// ignored when Labels has a Filter.
+	MaxItems int `json:"-"`
+	// HideAbove hides the legend of charts with more items than this.
+	HideAbove int `json:"-"`
+	// HTML renders the legend in pages as a list next to the canvas, with a
+	// checkbox toggling each item, instead of on the canvas. The list
+	// scrolls past the height of the chart, and long labels wrap.
+	HTML bool `json:"-"`
+}
+
+// legendItems returns the number of legend items of the chart: one per
+// label for Doughnut and PolarArea charts, and one per dataset otherwise.
+func (c Chart) legendItems() int {
+	if c.Type == Doughnut || c.Type == PolarArea {
+		if c.Data.LabelLines != nil {
+			return len(c.Data.LabelLines)
+		}
+		labels, _ := c.Data.valueLabels()
+		return len(labels)
+	}
+	return len(c.Data.Datasets)
+}
+
+// applyLegendOverflow applies MaxItems and HideAbove of the legend.
+func (c *Chart) applyLegendOverflow() {
+	l := c.Options.Legend
+	if l == nil || l.MaxItems <= 0 && l.HideAbove <= 0 {
+		return
+	}
+	n := c.legendItems()
+	legend := *l
+	switch {
+	case legend.HideAbove > 0 && n > legend.HideAbove:
+		legend.Display = types.False
+	case legend.MaxItems > 0 && n > legend.MaxItems && (legend.Labels == nil || legend.Labels.Filter == ""):
+		labels := LegendLabels{}
+		if legend.Labels != nil {
+			labels = *legend.Labels
+		}
+		labels.Filter = JSFunc(fmt.Sprintf(`function(item) {
+	var i = item.datasetIndex !== undefined ? item.datasetIndex : item.index;
+	return i < %d;
+}`, legend.MaxItems))
+		legend.Labels = &labels
+	}
+	c.Options.Legend = &legend
+}
+
+// LegendLabels are the options of the legend items.
+type LegendLabels struct {
+	// BoxWidth and BoxHeight are the size of the colored box in pixels.
+	BoxWidth  int         `json:"boxWidth,omitempty"`
+	BoxHeight int         `json:"boxHeight,omitempty"`
+	Color     *types.RGBA `json:"color,omitempty"`
+	Font      *Font       `json:"font,omitempty"`
+	// Padding between the items in pixels.
+	Padding int `json:"padding,omitempty"`
+	// UsePointStyle draws the point style of the dataset instead of a box.
+	UsePointStyle types.Bool `json:"usePointStyle,omitempty"`
+
+	// Filter is called with a legend item and the chart data, and hides
+	// the item when it returns false.
+	Filter JSFunc `json:"filter,omitempty"`
+	// Sort compares two legend items and the chart data, like
+	// Array.prototype.sort.
+	Sort JSFunc `json:"sort,omitempty"`
+	// GenerateLabels is called with the chart and returns the legend items.
+	GenerateLabels JSFunc `json:"generateLabels,omitempty"`
+}
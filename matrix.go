@@ -0,0 +1,138 @@
+package chartjs
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+
+	"github.com/iszk1215/go-chartjs/types"
+)
+
+// MatrixValues are the cells of a Matrix chart: the value Vs()[i] at
+// (Xs()[i], Ys()[i]).
+type MatrixValues interface {
+	Xs() []float64
+	Ys() []float64
+	Vs() []float64
+}
+
+// marshalMatrixValuesJSON emits {x, y, v} points.
+func marshalMatrixValuesJSON(m MatrixValues, xformat, yformat string) ([]byte, error) {
+	xs, ys, vs := m.Xs(), m.Ys(), m.Vs()
+	if len(ys) != len(xs) || len(vs) != len(xs) {
+		return nil, fmt.Errorf("chart: bad format of MatrixValues. All axes must be of the same length")
+	}
+	buf := bytes.NewBuffer(make([]byte, 0, 32*len(xs)))
+	buf.WriteRune('[')
+	for i := range xs {
+		if i > 0 {
+			buf.WriteRune(',')
+		}
+		for _, f := range []struct {
+			key, format string
+			v           float64
+		}{{`{"x":`, xformat, xs[i]}, {`,"y":`, yformat, ys[i]}, {`,"v":`, "%g", vs[i]}} {
+			buf.WriteString(f.key)
+			if err := writeFloat(buf, f.format, f.v); err != nil {
+				return nil, err
+			}
+		}
+		buf.WriteRune('}')
+	}
+	buf.WriteRune(']')
+	return buf.Bytes(), nil
+}
+
+// ColorScale maps values to colors.
+type ColorScale struct {
+	// Colormap defaults to types.Viridis.
+	Colormap types.Colormap
+	// Min and Max are the values mapped to the ends of the colormap. They
+	// default to the range of the data when equal.
+	Min, Max float64
+}
+
+// fit returns the scale with Min and Max set from vs if unset.
+func (s ColorScale) fit(vs []float64) ColorScale {
+	if s.Colormap == nil {
+		s.Colormap = types.Viridis
+	}
+	if s.Min == s.Max {
+		s.Min, s.Max = span([2]float64{}, vs)
+	}
+	return s
+}
+
+// Color returns the color of v. NaN is transparent.
+func (s ColorScale) Color(v float64) types.RGBA {
+	if math.IsNaN(v) {
+		return types.RGBA{}
+	}
+	cmap := s.Colormap
+	if cmap == nil {
+		cmap = types.Viridis
+	}
+	if s.Max == s.Min {
+		return cmap(0.5)
+	}
+	return cmap((v - s.Min) / (s.Max - s.Min))
+}
+
+// matrixCellJS sizes cells to fill the chart area with n cells along one side.
+const matrixCellJS = `function(ctx) {
+	var a = ctx.chart.chartArea;
+	return a ? (a.%s - a.%s) / %d - 1 : 0;
+}`
+
+func distinct(vs []float64) int {
+	seen := map[float64]bool{}
+	for _, v := range vs {
+		seen[v] = true
+	}
+	return len(seen)
+}
+
+// NewMatrixDataset returns a dataset of the cells colored by scale, with cells
+// sized to fill the chart area.
+func NewMatrixDataset(label string, m MatrixValues, scale ColorScale) Dataset {
+	vs := m.Vs()
+	scale = scale.fit(vs)
+	d := Dataset{
+		Type:             Matrix,
+		Label:            label,
+		Data:             m,
+		BackgroundColors: make([]types.RGBA, len(vs)),
+		Width:            JSFunc(fmt.Sprintf(matrixCellJS, "right", "left", distinct(m.Xs()))),
+		Height:           JSFunc(fmt.Sprintf(matrixCellJS, "bottom", "top", distinct(m.Ys()))),
+	}
+	for i, v := range vs {
+		d.BackgroundColors[i] = scale.Color(v)
+	}
+	return d
+}
+
+// matrixValues is a plain MatrixValues implementation.
+type matrixValues struct {
+	xs, ys, vs []float64
+}
+
+func (m matrixValues) Xs() []float64 { return m.xs }
+func (m matrixValues) Ys() []float64 { return m.ys }
+func (m matrixValues) Vs() []float64 { return m.vs }
+
+// NewMatrix returns a Matrix chart of the grid vs[row][column], with row 0 at
+// the top, and linear axes through the cell centers.
+func NewMatrix(vs [][]float64, scale ColorScale) *Chart {
+	var m matrixValues
+	for r, row := range vs {
+		for col, v := range row {
+			m.xs, m.ys, m.vs = append(m.xs, float64(col)), append(m.ys, float64(r)), append(m.vs, v)
+		}
+	}
+	c := &Chart{Type: Matrix}
+	c.AddDataset(NewMatrixDataset("", m, scale))
+	c.AddAxis(Axis{ID: "x", Type: Linear, Position: Bottom, Tick: &Tick{Min: -0.5, Max: float64(distinct(m.xs)) - 0.5}})
+	c.AddAxis(Axis{ID: "y", Type: Linear, Position: Left, Tick: &Tick{Min: -0.5, Max: float64(len(vs)) - 0.5, Reverse: True}})
+	c.Options.Legend = &Legend{Display: False}
+	return c
+}
@@ -0,0 +1,121 @@
+package chartjs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+)
+
+// MetaValues are Values that carry metadata for each point, e.g. a request ID or
+// commit SHA. The metadata is emitted as "meta" on each point so that tooltip
+// callbacks such as MetaFooter can show it.
+type MetaValues interface {
+	Values
+	Meta() []map[string]string
+}
+
+// MetaFooter is a tooltip footer callback listing the metadata of the hovered
+// points. It works with the callback arguments of chart.js 2 and of later
+// versions.
+const MetaFooter template.JSStr = `function(items, data) {
+	var lines = [];
+	items.forEach(function(item) {
+		var p = item.raw !== undefined ? item.raw : data.datasets[item.datasetIndex].data[item.index];
+		if (p && p.meta) {
+			for (var k in p.meta) { lines.push(k + ": " + p.meta[k]); }
+		}
+	});
+	return lines;
+}`
+
+// ShowMetaInTooltips sets the tooltip footer to list point metadata.
+func (c *Chart) ShowMetaInTooltips() {
+	c.tooltipCallbacks().Footer = MetaFooter
+}
+
+// tooltipCallbacks returns the tooltip callbacks, creating them if unset.
+func (c *Chart) tooltipCallbacks() *TooltipCallbacks {
+	if c.Options.Tooltip == nil {
+		c.Options.Tooltip = &Tooltip{}
+	}
+	if c.Options.Tooltip.Callbacks == nil {
+		c.Options.Tooltip.Callbacks = &TooltipCallbacks{}
+	}
+	return c.Options.Tooltip.Callbacks
+}
+
+func marshalMetaValuesJSON(v MetaValues, xformat, yformat string) ([]byte, error) {
+	xs, ys, rs, meta := v.Xs(), v.Ys(), v.Rs(), v.Meta()
+	vals := plotted(v)
+	if len(meta) != len(vals) {
+		return nil, fmt.Errorf("chart: bad format of Values. Meta must have one entry per point")
+	}
+	if len(ys) > 0 && len(xs) != len(ys) || len(rs) > 0 && len(rs) != len(ys) {
+		return nil, fmt.Errorf("chart: bad format of Values. All axes must be of the same length")
+	}
+	buf := bytes.NewBuffer(make([]byte, 0, 32*len(vals)))
+	buf.WriteRune('[')
+	for i, y := range vals {
+		if i > 0 {
+			buf.WriteRune(',')
+		}
+		buf.WriteRune('{')
+		if len(ys) > 0 {
+			buf.WriteString(`"x":`)
+			if err := writeFloat(buf, xformat, xs[i]); err != nil {
+				return nil, err
+			}
+			buf.WriteRune(',')
+		}
+		buf.WriteString(`"y":`)
+		if err := writeFloat(buf, yformat, y); err != nil {
+			return nil, err
+		}
+		if len(rs) > 0 {
+			buf.WriteString(`,"r":`)
+			if err := writeFloat(buf, yformat, rs[i]); err != nil {
+				return nil, err
+			}
+		}
+		m, err := json.Marshal(meta[i])
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteString(",\"meta\":")
+		buf.Write(m)
+		buf.WriteRune('}')
+	}
+	buf.WriteRune(']')
+	return buf.Bytes(), nil
+}
+
+// rawTooltipLabel returns a tooltip label callback showing raw[dataset][index]
+// instead of the plotted value, for charts that plot transformed values.
+func rawTooltipLabel(raw [][]float64) (template.JSStr, error) {
+	var buf bytes.Buffer
+	buf.WriteString(`(function(raw) {
+	return function(item, data) {
+		var label = data ? data.datasets[item.datasetIndex].label : item.dataset.label;
+		var i = item.dataIndex !== undefined ? item.dataIndex : item.index;
+		return label + ": " + raw[item.datasetIndex][i];
+	};
+})([`)
+	for i, vs := range raw {
+		if i > 0 {
+			buf.WriteRune(',')
+		}
+		buf.WriteRune('[')
+		for j, v := range vs {
+			if j > 0 {
+				buf.WriteRune(',')
+			}
+			if err := writeFloat(&buf, "%g", v); err != nil {
+				return "", err
+			}
+		}
+		buf.WriteRune(']')
+	}
+	buf.WriteString("])")
+	return template.JSStr(buf.String()), nil
+}
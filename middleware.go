@@ -0,0 +1,62 @@
+package chartjs
+
+import "github.com/iszk1215/go-chartjs/types"
+
+// Middleware changes a chart before it is marshaled or rendered, e.g. to
+// apply a palette or theme, validate or decimate the data, or add overlays.
+// It gets a copy of the chart: its datasets, scales and middlewares may be
+// changed in place, other fields shared with the original must be replaced.
+type Middleware func(*Chart) error
+
+// Use appends middlewares to the chart. They are applied in order every time
+// the chart is marshaled, leaving the chart itself unchanged.
+func (c *Chart) Use(m ...Middleware) {
+	c.Middlewares = append(c.Middlewares, m...)
+}
+
+// applyMiddlewares returns a copy of the chart with its middlewares applied
+// and cleared.
+func (c Chart) applyMiddlewares() (Chart, error) {
+	if len(c.Middlewares) == 0 {
+		return c, nil
+	}
+	ms := c.Middlewares
+	c.Middlewares = nil
+	c.Data.Datasets = append([]Dataset(nil), c.Data.Datasets...)
+	if c.Options.Scales != nil {
+		scales := make(map[string]Axis, len(c.Options.Scales))
+		for id, a := range c.Options.Scales {
+			scales[id] = a
+		}
+		c.Options.Scales = scales
+	}
+	for _, m := range ms {
+		if err := m(&c); err != nil {
+			return c, err
+		}
+	}
+	// middlewares may add more.
+	return c.applyMiddlewares()
+}
+
+// ApplyPalette is a Middleware coloring the datasets without colors in turn
+// from palette, DefaultPalette if empty.
+func ApplyPalette(palette []types.RGBA) Middleware {
+	if len(palette) == 0 {
+		palette = DefaultPalette
+	}
+	return func(c *Chart) error {
+		for i := range c.Data.Datasets {
+			d := &c.Data.Datasets[i]
+			color := palette[i%len(palette)]
+			if d.BorderColor == nil {
+				d.BorderColor = &color
+			}
+			if d.BackgroundColor == nil && d.BackgroundColors == nil {
+				fill := color.WithAlpha(0.5)
+				d.BackgroundColor = &fill
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,213 @@
+package chartjs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// SchemaMigration upgrades a decoded chart config from one schema version of
+// this package to the next.
+type SchemaMigration func(config map[string]interface{}) error
+
+// schemaMigrations[i] upgrades configs of schema version i+1 to i+2. A
+// release that changes the JSON of charts in the default schema appends one.
+var schemaMigrations = []SchemaMigration{migrateGridLine}
+
+// migrateGridLine moves the "gridLine" of the axes, written by Axis.GridLines
+// up to schema version 1, to "grid".
+func migrateGridLine(c map[string]interface{}) error {
+	opts, _ := c["options"].(object)
+	scales, _ := opts["scales"].(object)
+	for _, a := range scales {
+		a, ok := a.(object)
+		if !ok {
+			continue
+		}
+		if g, ok := a["gridLine"]; ok {
+			delete(a, "gridLine")
+			grid := child(a, "grid")
+			if _, ok := grid["display"]; !ok {
+				grid["display"] = g
+			}
+		}
+	}
+	return nil
+}
+
+// schemaVersion returns the schema version of the configs of this release.
+func schemaVersion() int { return len(schemaMigrations) + 1 }
+
+// StoredConfig is a chart config persisted with the versions it was written
+// for, so that it can be migrated after library upgrades, e.g.
+//
+//	s, err := chartjs.Persist(c)
+//	// store and later load s as JSON
+//	config, err := s.Migrate(chartjs.V4)
+type StoredConfig struct {
+	// Schema is the schema version of this package.
+	Schema int `json:"schema"`
+	// ChartJS is the TargetVersion of the chart, zero for the default schema.
+	ChartJS chartJSVersion  `json:"chartjs,omitempty"`
+	Config  json.RawMessage `json:"config"`
+}
+
+// Persist returns the config of c stamped with the current schema version.
+func Persist(c Chart) (StoredConfig, error) {
+	b, err := c.MarshalJSON()
+	if err != nil {
+		return StoredConfig{}, err
+	}
+	return StoredConfig{Schema: schemaVersion(), ChartJS: c.TargetVersion, Config: b}, nil
+}
+
+// ParseStoredConfig reads a StoredConfig, or a bare chart config stored
+// before Persist was used. Bare configs are taken as schema version 1, with
+// the Chart.js version guessed from their layout.
+func ParseStoredConfig(b []byte) (StoredConfig, error) {
+	var s StoredConfig
+	if err := json.Unmarshal(b, &s); err != nil {
+		return s, err
+	}
+	if s.Config != nil {
+		return s, nil
+	}
+	var c object
+	if err := json.Unmarshal(b, &c); err != nil {
+		return s, err
+	}
+	return StoredConfig{Schema: 1, ChartJS: guessChartJS(c), Config: b}, nil
+}
+
+// guessChartJS returns the Chart.js version of a bare config: V2 for lists of
+// axes, V3 for the tooltip or legend under plugins, and zero for the default
+// schema.
+func guessChartJS(c object) chartJSVersion {
+	if c["type"] == "horizontalBar" {
+		return V2
+	}
+	opts, _ := c["options"].(object)
+	if scales, ok := opts["scales"].(object); ok {
+		if _, ok := scales["xAxes"]; ok {
+			return V2
+		}
+		if _, ok := scales["yAxes"]; ok {
+			return V2
+		}
+	}
+	// plugins and indexAxis are also written by the default schema, only
+	// the tooltip and legend moved under plugins in V3.
+	if plugins, ok := opts["plugins"].(object); ok {
+		if _, ok := plugins["tooltip"]; ok {
+			return V3
+		}
+		if _, ok := plugins["legend"]; ok {
+			return V3
+		}
+	}
+	return 0
+}
+
+// Migrate returns the config upgraded to the current schema version and
+// converted to the Chart.js version target, zero for the default schema.
+// Configs stored for V3 or V4 cannot be converted to older versions.
+func (s StoredConfig) Migrate(target chartJSVersion) (json.RawMessage, error) {
+	switch {
+	case s.Schema < 1:
+		return nil, fmt.Errorf("chart: stored config has no schema version")
+	case s.Schema > schemaVersion():
+		return nil, fmt.Errorf("chart: stored config has schema version %d, newer than %d", s.Schema, schemaVersion())
+	}
+	dec := json.NewDecoder(bytes.NewReader(s.Config))
+	dec.UseNumber()
+	var c object
+	if err := dec.Decode(&c); err != nil {
+		return nil, err
+	}
+	if s.ChartJS == 0 {
+		// configs converted to a Chart.js version do not change with the
+		// default schema.
+		for _, m := range schemaMigrations[s.Schema-1:] {
+			if err := m(c); err != nil {
+				return nil, err
+			}
+		}
+	}
+	from := s.ChartJS
+	if from == V4 {
+		from = V3
+	}
+	switch {
+	case from == target || from == V3 && target == V4:
+	case from == V3:
+		return nil, fmt.Errorf("chart: cannot convert a config for chart.js %d to %d", s.ChartJS, target)
+	default:
+		if from == V2 {
+			fromV2(c)
+		}
+		switch target {
+		case V2:
+			toV2(c)
+		case V3, V4:
+			toV3(c)
+		}
+	}
+	return json.Marshal(c)
+}
+
+// Upgrade returns s migrated to the current schema version and target, to
+// be stored back.
+func (s StoredConfig) Upgrade(target chartJSVersion) (StoredConfig, error) {
+	b, err := s.Migrate(target)
+	if err != nil {
+		return StoredConfig{}, err
+	}
+	return StoredConfig{Schema: schemaVersion(), ChartJS: target, Config: b}, nil
+}
+
+// fromV2 reverts toV2, turning the xAxes and yAxes lists back into the scale
+// map of the default schema.
+func fromV2(c object) {
+	opts, _ := c["options"].(object)
+	if c["type"] == "horizontalBar" {
+		c["type"] = "bar"
+		if opts == nil {
+			opts = child(c, "options")
+		}
+		opts["indexAxis"] = "y"
+	}
+	if opts == nil {
+		return
+	}
+	scales := object{}
+	if old, ok := opts["scales"].(object); ok {
+		for _, key := range []string{"xAxes", "yAxes"} {
+			axes, _ := old[key].([]interface{})
+			for i, a := range axes {
+				a, ok := a.(object)
+				if !ok {
+					continue
+				}
+				id, _ := a["id"].(string)
+				if id == "" {
+					id = fmt.Sprintf("%c-axis-%d", key[0], i)
+				}
+				delete(a, "id")
+				if g, ok := a["gridLines"].(object); ok {
+					delete(a, "gridLines")
+					rename(g, "tickMarkLength", "tickLength")
+					a["grid"] = g
+				}
+				scales[id] = a
+			}
+		}
+	}
+	if a, ok := opts["scale"].(object); ok {
+		delete(opts, "scale")
+		scales["r"] = a
+	}
+	delete(opts, "scales")
+	if len(scales) > 0 {
+		opts["scales"] = scales
+	}
+}
@@ -0,0 +1,185 @@
+package chartjs
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"sync"
+)
+
+// DefaultMaxPoints is the number of points a MappedValues is decimated to when
+// MaxPoints is not set.
+var DefaultMaxPoints = 2000
+
+const pairSize = 16
+
+// MappedValues is a series of (x, y) points read from a memory-mapped file, so
+// that series much larger than memory can be charted. The file holds pairs of
+// little-endian float64s sorted by x, as written by WriteMappedValues.
+//
+// MappedValues implements Values by decimating the whole series to MaxPoints;
+// use Range to chart a part of it.
+type MappedValues struct {
+	// MaxPoints bounds the number of points sent to the browser. Set it before
+	// the values are first used.
+	MaxPoints int
+
+	data  []byte
+	unmap func([]byte) error
+	all   *mappedRange
+}
+
+// OpenMappedValues maps the file at path. Call Close to release it.
+func OpenMappedValues(path string) (*MappedValues, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if fi.Size()%pairSize != 0 {
+		return nil, fmt.Errorf("chart: %s is not a file of float64 pairs", path)
+	}
+	m := &MappedValues{}
+	if fi.Size() > 0 {
+		if m.data, m.unmap, err = mmapFile(f, int(fi.Size())); err != nil {
+			return nil, err
+		}
+	}
+	m.all = &mappedRange{m: m, hi: m.Len()}
+	return m, nil
+}
+
+// Close unmaps the file. Values returned by Range must not be used after.
+func (m *MappedValues) Close() error {
+	if m.unmap == nil {
+		return nil
+	}
+	data, unmap := m.data, m.unmap
+	m.data, m.unmap = nil, nil
+	return unmap(data)
+}
+
+// Len returns the number of points in the file.
+func (m *MappedValues) Len() int { return len(m.data) / pairSize }
+
+// At returns the i-th point.
+func (m *MappedValues) At(i int) (x, y float64) {
+	b := m.data[i*pairSize : (i+1)*pairSize]
+	return math.Float64frombits(binary.LittleEndian.Uint64(b)),
+		math.Float64frombits(binary.LittleEndian.Uint64(b[8:]))
+}
+
+// Search returns the index of the first point with an x of at least x.
+func (m *MappedValues) Search(x float64) int {
+	return sort.Search(m.Len(), func(i int) bool {
+		xi, _ := m.At(i)
+		return xi >= x
+	})
+}
+
+// Range returns the points with x in [from, to], decimated to MaxPoints.
+func (m *MappedValues) Range(from, to float64) Values {
+	lo, hi := m.Search(from), m.Search(math.Nextafter(to, math.Inf(1)))
+	return &mappedRange{m: m, lo: lo, hi: hi}
+}
+
+func (m *MappedValues) Xs() []float64 { return m.all.Xs() }
+func (m *MappedValues) Ys() []float64 { return m.all.Ys() }
+func (m *MappedValues) Rs() []float64 { return nil }
+
+// mappedRange decimates points [lo, hi) of a MappedValues on first use.
+type mappedRange struct {
+	m      *MappedValues
+	lo, hi int
+	once   sync.Once
+	xs, ys []float64
+}
+
+func (r *mappedRange) load() {
+	n := r.m.MaxPoints
+	if n <= 0 {
+		n = DefaultMaxPoints
+	}
+	r.xs, r.ys = minMax(r.m, r.lo, r.hi, n/2)
+}
+
+// pointSource is a series of points sorted by x.
+type pointSource interface {
+	Len() int
+	At(i int) (x, y float64)
+}
+
+// minMax keeps the min and max of each of n buckets of points [lo, hi) of src,
+// in x order, like decimate. All points are kept if there are at most 2n.
+func minMax(src pointSource, lo, hi, n int) (xs, ys []float64) {
+	count := hi - lo
+	if n < 1 {
+		n = 1
+	}
+	if count <= 2*n {
+		for i := lo; i < hi; i++ {
+			x, y := src.At(i)
+			xs, ys = append(xs, x), append(ys, y)
+		}
+		return xs, ys
+	}
+	for b := 0; b < n; b++ {
+		start, end := lo+count*b/n, lo+count*(b+1)/n
+		imin, imax := start, start
+		_, ymin := src.At(start)
+		ymax := ymin
+		for i := start + 1; i < end; i++ {
+			_, y := src.At(i)
+			if y < ymin {
+				imin, ymin = i, y
+			}
+			if y > ymax {
+				imax, ymax = i, y
+			}
+		}
+		if imin > imax {
+			imin, imax = imax, imin
+		}
+		x, y := src.At(imin)
+		xs, ys = append(xs, x), append(ys, y)
+		if imax != imin {
+			x, y = src.At(imax)
+			xs, ys = append(xs, x), append(ys, y)
+		}
+	}
+	return xs, ys
+}
+
+func (r *mappedRange) Xs() []float64 { r.once.Do(r.load); return r.xs }
+func (r *mappedRange) Ys() []float64 { r.once.Do(r.load); return r.ys }
+func (r *mappedRange) Rs() []float64 { return nil }
+
+// WriteMappedValues writes v in the format read by OpenMappedValues. The Xs of
+// v must be sorted.
+func WriteMappedValues(w io.Writer, v Values) error {
+	xs, ys := v.Xs(), v.Ys()
+	if len(xs) != len(ys) {
+		return fmt.Errorf("chart: bad format of Values. X and Y must be of the same length")
+	}
+	bw := bufio.NewWriter(w)
+	var b [pairSize]byte
+	for i, x := range xs {
+		if i > 0 && x < xs[i-1] {
+			return fmt.Errorf("chart: Xs must be sorted to write mapped values")
+		}
+		binary.LittleEndian.PutUint64(b[:], math.Float64bits(x))
+		binary.LittleEndian.PutUint64(b[8:], math.Float64bits(ys[i]))
+		if _, err := bw.Write(b[:]); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
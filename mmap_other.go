@@ -0,0 +1,17 @@
+//go:build !unix
+
+package chartjs
+
+import (
+	"io"
+	"os"
+)
+
+// mmapFile reads the whole file on platforms without mmap.
+func mmapFile(f *os.File, size int) ([]byte, func([]byte) error, error) {
+	data := make([]byte, size)
+	if _, err := io.ReadFull(f, data); err != nil {
+		return nil, nil, err
+	}
+	return data, func([]byte) error { return nil }, nil
+}
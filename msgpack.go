@@ -0,0 +1,313 @@
+package chartjs
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// configValue returns the JSON config of the chart decoded into maps,
+// slices, strings, bools, nil and json.Numbers. JSFunc values are strings of
+// their code.
+func configValue(c Chart) (interface{}, error) {
+	b, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	var v interface{}
+	err = d.Decode(&v)
+	return v, err
+}
+
+// number returns n as an int64 if it is an integer that fits, else as a
+// float64.
+func number(n json.Number) (interface{}, error) {
+	if i, err := strconv.ParseInt(string(n), 10, 64); err == nil {
+		return i, nil
+	}
+	f, err := n.Float64()
+	if err == nil && f == math.Trunc(f) && math.Abs(f) < 1<<63 {
+		return int64(f), nil
+	}
+	return f, err
+}
+
+// sortedMapKeys returns the keys of m in order, for deterministic output.
+func sortedMapKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// decodedJSON marshals a decoded binary config back to JSON.
+func decodedJSON(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	e := json.NewEncoder(&buf)
+	e.SetEscapeHTML(false)
+	if err := e.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// MsgPackEncoder writes the chart config as MessagePack, which is smaller
+// than JSON for charts with many points. Integral numbers are written as
+// integers and others as float32 when that is exact. The config is encoded
+// from its JSON, so encoding takes longer than with JSONEncoder.
+type MsgPackEncoder struct{}
+
+// ContentType implements Encoder.
+func (MsgPackEncoder) ContentType() string { return "application/msgpack" }
+
+// Encode implements Encoder.
+func (MsgPackEncoder) Encode(w io.Writer, c Chart) error {
+	v, err := configValue(c)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := writeMsgPack(&buf, v); err != nil {
+		return err
+	}
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+func writeMsgPackHead(buf *bytes.Buffer, n int, fix, fixMax byte, b8, b16, b32 byte) {
+	switch {
+	case n < int(fixMax):
+		buf.WriteByte(fix | byte(n))
+	case b8 != 0 && n <= math.MaxUint8:
+		buf.Write([]byte{b8, byte(n)})
+	case n <= math.MaxUint16:
+		buf.WriteByte(b16)
+		binary.Write(buf, binary.BigEndian, uint16(n))
+	default:
+		buf.WriteByte(b32)
+		binary.Write(buf, binary.BigEndian, uint32(n))
+	}
+}
+
+func writeMsgPack(buf *bytes.Buffer, v interface{}) error {
+	switch v := v.(type) {
+	case nil:
+		buf.WriteByte(0xc0)
+	case bool:
+		if v {
+			buf.WriteByte(0xc3)
+		} else {
+			buf.WriteByte(0xc2)
+		}
+	case string:
+		writeMsgPackHead(buf, len(v), 0xa0, 32, 0xd9, 0xda, 0xdb)
+		buf.WriteString(v)
+	case json.Number:
+		n, err := number(v)
+		if err != nil {
+			return err
+		}
+		switch n := n.(type) {
+		case int64:
+			switch {
+			case n >= 0 && n < 128, n < 0 && n >= -32:
+				buf.WriteByte(byte(n))
+			case n >= math.MinInt8 && n <= math.MaxInt8:
+				buf.Write([]byte{0xd0, byte(n)})
+			case n >= math.MinInt16 && n <= math.MaxInt16:
+				buf.WriteByte(0xd1)
+				binary.Write(buf, binary.BigEndian, int16(n))
+			case n >= math.MinInt32 && n <= math.MaxInt32:
+				buf.WriteByte(0xd2)
+				binary.Write(buf, binary.BigEndian, int32(n))
+			default:
+				buf.WriteByte(0xd3)
+				binary.Write(buf, binary.BigEndian, n)
+			}
+		case float64:
+			if float64(float32(n)) == n {
+				buf.WriteByte(0xca)
+				binary.Write(buf, binary.BigEndian, float32(n))
+			} else {
+				buf.WriteByte(0xcb)
+				binary.Write(buf, binary.BigEndian, n)
+			}
+		}
+	case []interface{}:
+		writeMsgPackHead(buf, len(v), 0x90, 16, 0, 0xdc, 0xdd)
+		for _, e := range v {
+			if err := writeMsgPack(buf, e); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		writeMsgPackHead(buf, len(v), 0x80, 16, 0, 0xde, 0xdf)
+		for _, k := range sortedMapKeys(v) {
+			writeMsgPack(buf, k)
+			if err := writeMsgPack(buf, v[k]); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("chart: cannot encode %T as MessagePack", v)
+	}
+	return nil
+}
+
+// DecodeMsgPack returns the JSON chart config of a MessagePack one written by
+// MsgPackEncoder.
+func DecodeMsgPack(data []byte) ([]byte, error) {
+	r := bytes.NewReader(data)
+	v, err := readMsgPack(r, 0)
+	if err != nil {
+		return nil, err
+	}
+	if r.Len() > 0 {
+		return nil, fmt.Errorf("chart: %d trailing bytes after MessagePack config", r.Len())
+	}
+	return decodedJSON(v)
+}
+
+// maxDecodeDepth bounds the nesting of decoded arrays and maps, which chart
+// configs come nowhere near.
+const maxDecodeDepth = 64
+
+// readN reads n bytes, failing rather than allocating for lengths beyond the
+// end of the input.
+func readN(r *bytes.Reader, n uint64) ([]byte, error) {
+	if n > uint64(r.Len()) {
+		return nil, io.ErrUnexpectedEOF
+	}
+	b := make([]byte, n)
+	_, err := io.ReadFull(r, b)
+	return b, err
+}
+
+func readUint(r *bytes.Reader, size int) (uint64, error) {
+	b, err := readN(r, uint64(size))
+	if err != nil {
+		return 0, err
+	}
+	var n uint64
+	for _, c := range b {
+		n = n<<8 | uint64(c)
+	}
+	return n, nil
+}
+
+func readMsgPack(r *bytes.Reader, depth int) (interface{}, error) {
+	if depth > maxDecodeDepth {
+		return nil, fmt.Errorf("chart: MessagePack config nested deeper than %d", maxDecodeDepth)
+	}
+	t, err := r.ReadByte()
+	if err != nil {
+		return nil, io.ErrUnexpectedEOF
+	}
+	var n uint64
+	switch {
+	case t < 0x80:
+		return int64(t), nil
+	case t >= 0xe0:
+		return int64(int8(t)), nil
+	case t&0xf0 == 0x80:
+		return readMsgPackMap(r, uint64(t&0x0f), depth)
+	case t&0xf0 == 0x90:
+		return readMsgPackArray(r, uint64(t&0x0f), depth)
+	case t&0xe0 == 0xa0:
+		b, err := readN(r, uint64(t&0x1f))
+		return string(b), err
+	}
+	switch t {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xca:
+		n, err = readUint(r, 4)
+		return float64(math.Float32frombits(uint32(n))), err
+	case 0xcb:
+		n, err = readUint(r, 8)
+		return math.Float64frombits(n), err
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		n, err = readUint(r, 1<<(t-0xcc))
+		if n > math.MaxInt64 {
+			return n, err
+		}
+		return int64(n), err
+	case 0xd0:
+		n, err = readUint(r, 1)
+		return int64(int8(n)), err
+	case 0xd1:
+		n, err = readUint(r, 2)
+		return int64(int16(n)), err
+	case 0xd2:
+		n, err = readUint(r, 4)
+		return int64(int32(n)), err
+	case 0xd3:
+		n, err = readUint(r, 8)
+		return int64(n), err
+	case 0xd9, 0xda, 0xdb:
+		if n, err = readUint(r, 1<<(t-0xd9)); err != nil {
+			return nil, err
+		}
+		b, err := readN(r, n)
+		return string(b), err
+	case 0xdc, 0xdd:
+		if n, err = readUint(r, 2<<(t-0xdc)); err != nil {
+			return nil, err
+		}
+		return readMsgPackArray(r, n, depth)
+	case 0xde, 0xdf:
+		if n, err = readUint(r, 2<<(t-0xde)); err != nil {
+			return nil, err
+		}
+		return readMsgPackMap(r, n, depth)
+	}
+	return nil, fmt.Errorf("chart: unsupported MessagePack type 0x%02x", t)
+}
+
+func readMsgPackArray(r *bytes.Reader, n uint64, depth int) ([]interface{}, error) {
+	if n > uint64(r.Len()) {
+		return nil, io.ErrUnexpectedEOF
+	}
+	a := make([]interface{}, n)
+	for i := range a {
+		var err error
+		if a[i], err = readMsgPack(r, depth+1); err != nil {
+			return nil, err
+		}
+	}
+	return a, nil
+}
+
+func readMsgPackMap(r *bytes.Reader, n uint64, depth int) (map[string]interface{}, error) {
+	if n > uint64(r.Len()) {
+		return nil, io.ErrUnexpectedEOF
+	}
+	m := make(map[string]interface{}, n)
+	for i := uint64(0); i < n; i++ {
+		k, err := readMsgPack(r, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		key, ok := k.(string)
+		if !ok {
+			return nil, fmt.Errorf("chart: MessagePack map key %v is not a string", k)
+		}
+		if m[key], err = readMsgPack(r, depth+1); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
@@ -0,0 +1,104 @@
+package chartjs
+
+import (
+	"fmt"
+	"math"
+)
+
+type nanPolicy int
+
+const (
+	// NaNNull writes NaN and infinite values as null, which chart.js draws as
+	// gaps. This is the default.
+	NaNNull nanPolicy = iota
+	// NaNSkip drops the points with a NaN or infinite x, y or r. Series
+	// without x values write null instead, as dropping values would shift the
+	// later ones to other labels.
+	NaNSkip
+	// NaNError fails marshaling on NaN and infinite values.
+	NaNError
+	// NaNZeroFill writes NaN and infinite values as 0.
+	NaNZeroFill
+)
+
+func finite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
+
+// applyNaNPolicy returns v with the policy applied to its x, y and r values.
+func applyNaNPolicy(v Values, p nanPolicy, label string) (Values, error) {
+	xs, ys, rs := v.Xs(), v.Ys(), v.Rs()
+	if len(ys) == 0 && p == NaNSkip {
+		return v, nil
+	}
+	n := len(xs)
+	if len(ys) > n {
+		n = len(ys)
+	}
+	keep := make([]int, 0, n)
+	fix := func(vs []float64, i int) float64 {
+		if finite(vs[i]) {
+			return vs[i]
+		}
+		return 0
+	}
+	var out xyValues
+	for i := 0; i < n; i++ {
+		ok := true
+		for _, vs := range [][]float64{xs, ys, rs} {
+			if i < len(vs) && !finite(vs[i]) {
+				ok = false
+			}
+		}
+		if ok || p == NaNZeroFill {
+			keep = append(keep, i)
+		} else if p == NaNError {
+			return nil, fmt.Errorf("chart: dataset %q has a NaN or infinite value at %d", label, i)
+		}
+	}
+	if len(keep) == n && p != NaNZeroFill {
+		return v, nil
+	}
+	for _, i := range keep {
+		if i < len(xs) {
+			out.xs = append(out.xs, fix(xs, i))
+		}
+		if i < len(ys) {
+			out.ys = append(out.ys, fix(ys, i))
+		}
+		if i < len(rs) {
+			out.rs = append(out.rs, fix(rs, i))
+		}
+	}
+	// keep the per point data of the other kinds of Values.
+	switch v := v.(type) {
+	case TimeValues:
+		ts := v.Times()
+		s := TimeSeries{Value: out.ys}
+		for _, i := range keep {
+			if i < len(ts) {
+				s.Time = append(s.Time, ts[i])
+			}
+		}
+		return s, nil
+	case MetaValues:
+		meta := v.Meta()
+		m := metaValues{xyValues: out}
+		for _, i := range keep {
+			if i < len(meta) {
+				m.meta = append(m.meta, meta[i])
+			}
+		}
+		return m, nil
+	case LabeledValues:
+		labels := v.Labels()
+		l := mapValues{xyValues: out}
+		for _, i := range keep {
+			if i < len(labels) {
+				l.labels = append(l.labels, labels[i])
+			}
+		}
+		return l, nil
+	}
+	return out, nil
+}
@@ -0,0 +1,5 @@
+//go:build !race
+
+package chartjs
+
+const raceEnabled = false
@@ -0,0 +1,68 @@
+package chartjs
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// OGWidth and OGHeight are the size of the images served by OGImageHandler.
+var (
+	OGWidth  = 1200
+	OGHeight = 630
+)
+
+// OGImageHandler serves Open Graph preview images of charts at paths ending
+// in "/{id}/og.png", e.g. "/charts/cpu/og.png", so shared links show a
+// preview. lookup returns the chart for an id or nil if there is none. Images
+// are cached for maxAge and revalidated with an ETag of the chart config.
+// Set RenderOptions.OGImage to point pages at the image.
+func OGImageHandler(lookup func(id string) (*Chart, error), maxAge time.Duration) http.Handler {
+	return ogImageHandler(func(_ *http.Request, id string) (*Chart, error) { return lookup(id) }, maxAge, "public")
+}
+
+// ogImageHandler serves the images with the cache scope, which is "private"
+// for charts personalized by request.
+func ogImageHandler(lookup func(r *http.Request, id string) (*Chart, error), maxAge time.Duration, scope string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+		if len(parts) < 2 || parts[len(parts)-1] != "og.png" {
+			http.NotFound(w, r)
+			return
+		}
+		c, err := lookup(r, parts[len(parts)-2])
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if c == nil {
+			http.NotFound(w, r)
+			return
+		}
+		b, err := json.Marshal(c)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		sum := sha1.Sum(b)
+		etag := `"` + hex.EncodeToString(sum[:]) + `"`
+		w.Header().Set("Cache-Control", fmt.Sprintf("%s, max-age=%d", scope, int(maxAge.Seconds())))
+		w.Header().Set("ETag", etag)
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		var buf bytes.Buffer
+		if err := c.Thumbnail(&buf, OGWidth, OGHeight); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(buf.Bytes())
+	})
+}
@@ -0,0 +1,73 @@
+package chartjs
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"regexp"
+)
+
+// Page renders several charts into one HTML document, laid out on a CSS grid,
+// for quick dashboards.
+type Page struct {
+	// Columns is the number of charts per row, 2 by default.
+	Columns int
+	// Gap is the CSS length between the charts, e.g. "1em", "16px" by
+	// default.
+	Gap string
+	// Layout replaces the CSS of the grid container when set.
+	Layout template.CSS
+	// Options configure the document, as for SaveHTML.
+	Options RenderOptions
+
+	Charts []*Chart
+}
+
+// NewPage returns a Page titled title showing charts.
+func NewPage(title string, charts ...*Chart) *Page {
+	return &Page{Options: RenderOptions{Title: title}, Charts: charts}
+}
+
+// Add appends charts to the page.
+func (p *Page) Add(charts ...*Chart) {
+	p.Charts = append(p.Charts, charts...)
+}
+
+// cssLength matches the CSS lengths allowed as Page.Gap.
+var cssLength = regexp.MustCompile(`^(0|[0-9]*\.?[0-9]+(px|em|rem|ex|ch|vw|vh|vmin|vmax|pt|pc|cm|mm|in|%))$`)
+
+func (p *Page) layout() (template.CSS, error) {
+	if p.Layout != "" {
+		return p.Layout, nil
+	}
+	cols, gap := p.Columns, p.Gap
+	if cols <= 0 {
+		cols = 2
+	}
+	if gap == "" {
+		gap = "16px"
+	}
+	if !cssLength.MatchString(gap) {
+		return "", fmt.Errorf("chart: bad page gap %q", gap)
+	}
+	return template.CSS(fmt.Sprintf("display:grid;grid-template-columns:repeat(%d,minmax(0,1fr));gap:%s", cols, gap)), nil
+}
+
+// Render writes the page to w. The charts get the canvas IDs canvas0,
+// canvas1, … in the order they were added.
+func (p *Page) Render(w io.Writer) error {
+	charts := make([]Chart, 0, len(p.Charts))
+	for _, c := range p.Charts {
+		if c == nil {
+			return fmt.Errorf("chart: page has a nil chart at %d", len(charts))
+		}
+		charts = append(charts, *c)
+	}
+	grid, err := p.layout()
+	if err != nil {
+		return err
+	}
+	opts := p.Options
+	opts.Grid = grid
+	return SaveCharts(w, opts.tmap(), charts...)
+}
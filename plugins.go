@@ -0,0 +1,147 @@
+package chartjs
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Plugin is a chart.js plugin or adapter that a chart may require.
+type Plugin struct {
+	// Src is the script URL. It may be empty if the ChartJS bundle provides the plugin.
+	Src string
+	// Register is javascript run once the script is loaded, if the plugin
+	// doesn't register itself.
+	Register string
+}
+
+// Plugins maps plugin names to their scripts. The HTML renderer includes the
+// plugins that the charts require and fails if a name isn't found here. Use
+// RegisterPlugin to add plugins once charts may be rendered concurrently.
+var Plugins = map[string]Plugin{
+	"annotation": {Src: "https://cdn.jsdelivr.net/npm/chartjs-plugin-annotation@0.5.7/chartjs-plugin-annotation.min.js"},
+	"datalabels": {Src: "https://cdn.jsdelivr.net/npm/chartjs-plugin-datalabels@0.7.0/dist/chartjs-plugin-datalabels.min.js"},
+	"hammerjs":   {Src: "https://cdn.jsdelivr.net/npm/hammerjs@2.0.8/hammer.min.js"},
+	"zoom":       {Src: "https://cdn.jsdelivr.net/npm/chartjs-plugin-zoom@0.7.7/dist/chartjs-plugin-zoom.min.js"},
+	"dragdata":   {Src: "https://cdn.jsdelivr.net/npm/chartjs-plugin-dragdata@1.1.3/dist/chartjs-plugin-dragdata.min.js"},
+	"financial":  {Src: "https://cdn.jsdelivr.net/npm/chartjs-chart-financial@0.1.1/dist/chartjs-chart-financial.min.js"},
+	"boxplot":    {Src: "https://cdn.jsdelivr.net/npm/chartjs-chart-box-and-violin-plot@2.4.0/build/Chart.BoxPlot.min.js"},
+	"matrix":     {Src: "https://cdn.jsdelivr.net/npm/chartjs-chart-matrix@0.1.3/dist/chartjs-chart-matrix.min.js"},
+	"treemap":    {Src: "https://cdn.jsdelivr.net/npm/chartjs-chart-treemap@0.2.3/dist/chartjs-chart-treemap.min.js"},
+	// the default ChartJS bundle ships moment.js for time axes.
+	"date-adapter": {},
+	// time axes with a DateAdapter.Zone need luxon.
+	"luxon":              {Src: "https://cdn.jsdelivr.net/npm/luxon@1.28.1/build/global/luxon.min.js"},
+	"date-adapter-luxon": {Src: "https://cdn.jsdelivr.net/npm/chartjs-adapter-luxon@0.2.2/dist/chartjs-adapter-luxon.min.js"},
+}
+
+// chartTypePlugins maps chart types to the plugins that provide them.
+var chartTypePlugins = map[chartType]string{
+	Candlestick: "financial",
+	OHLC:        "financial",
+	Boxplot:     "boxplot",
+	Violin:      "boxplot",
+	Matrix:      "matrix",
+	Treemap:     "treemap",
+}
+
+// pluginDeps lists the plugins that must be loaded before a plugin.
+var pluginDeps = map[string][]string{
+	"zoom":               {"hammerjs"},
+	"date-adapter-luxon": {"luxon"},
+}
+
+// RegisterPlugin adds or replaces the plugin name, loaded after the plugins
+// deps.
+func RegisterPlugin(name string, p Plugin, deps ...string) {
+	customMu.Lock()
+	defer customMu.Unlock()
+	Plugins[name] = p
+	if len(deps) > 0 {
+		pluginDeps[name] = deps
+	} else {
+		delete(pluginDeps, name)
+	}
+}
+
+// RequiredPlugins returns the names of the plugins the chart needs: those in
+// Requires, those of Plugins configured in Options.Plugins, as opposed to the
+// options of built-in plugins such as "legend", and those implied by options
+// such as DragData, annotations, plugin chart types or a Time axis, with the
+// luxon date adapter when it has a time zone.
+func (c Chart) RequiredPlugins() []string {
+	// chart types may be registered concurrently.
+	customMu.RLock()
+	defer customMu.RUnlock()
+	seen := map[string]bool{}
+	var names []string
+	var add func(name string)
+	add = func(name string) {
+		if seen[name] {
+			return
+		}
+		seen[name] = true
+		for _, dep := range pluginDeps[name] {
+			add(dep)
+		}
+		names = append(names, name)
+	}
+	for _, name := range c.Requires {
+		add(name)
+	}
+	keys := make([]string, 0, len(c.Options.Plugins))
+	for name := range c.Options.Plugins {
+		keys = append(keys, name)
+	}
+	sort.Strings(keys)
+	for _, name := range keys {
+		if _, ok := Plugins[name]; ok {
+			add(name)
+		}
+	}
+	for _, t := range c.chartTypes() {
+		if name, ok := chartTypePlugins[t]; ok {
+			add(name)
+		}
+	}
+	if c.Options.Annotation != nil {
+		add("annotation")
+	}
+	if c.Options.DragData != nil && *c.Options.DragData {
+		add("dragdata")
+	}
+	for _, a := range c.Options.Scales {
+		switch {
+		case a.Type != Time:
+		case a.Adapters != nil && a.Adapters.Date.Zone != "":
+			add("date-adapter-luxon")
+		default:
+			add("date-adapter")
+		}
+	}
+	return names
+}
+
+// chartTypes returns the type of the chart followed by those of its datasets.
+func (c Chart) chartTypes() []chartType {
+	ts := []chartType{c.Type}
+	for _, d := range c.Data.Datasets {
+		ts = append(ts, d.Type)
+	}
+	return ts
+}
+
+// resolvePlugins looks up the plugins required by the chart.
+func (c Chart) resolvePlugins() ([]Plugin, error) {
+	names := c.RequiredPlugins()
+	customMu.RLock()
+	defer customMu.RUnlock()
+	var ps []Plugin
+	for _, name := range names {
+		p, ok := Plugins[name]
+		if !ok {
+			return nil, fmt.Errorf("chart: unknown plugin %q required by chart %q", name, c.Label)
+		}
+		ps = append(ps, p)
+	}
+	return ps, nil
+}
@@ -0,0 +1,42 @@
+package chartjs
+
+import "sync"
+
+// Reset clears the chart for reuse, keeping the memory of its datasets and
+// scales. A reset chart marshals like a new one, except that its datasets are
+// empty instead of null. The slice and the map it keeps must not be shared
+// with other charts. The labels are dropped rather than kept, as they are
+// often shared, e.g. with the LabeledValues of FromMap.
+func (c *Chart) Reset() {
+	datasets, scales := c.Data.Datasets, c.Options.Scales
+	// drop the references to the data of the datasets.
+	for i := range datasets {
+		datasets[i] = Dataset{}
+	}
+	for id := range scales {
+		delete(scales, id)
+	}
+	*c = Chart{}
+	c.Data.Datasets, c.Options.Scales = datasets[:0], scales
+}
+
+var chartPool = sync.Pool{New: func() interface{} { return new(Chart) }}
+
+// AcquireChart returns an empty chart from a pool, for services rendering
+// many charts per second, e.g.
+//
+//	c := chartjs.AcquireChart()
+//	defer chartjs.ReleaseChart(c)
+//	c.Type = chartjs.Bar
+//	c.AddDataset(...)
+//	err := c.SaveHTML(w, chartjs.RenderOptions{})
+func AcquireChart() *Chart {
+	return chartPool.Get().(*Chart)
+}
+
+// ReleaseChart resets c and returns it to the pool of AcquireChart. Neither
+// c nor its datasets and scales may be used afterwards.
+func ReleaseChart(c *Chart) {
+	c.Reset()
+	chartPool.Put(c)
+}
@@ -0,0 +1,151 @@
+package chartjs
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Publisher stores rendered artifacts under a key, e.g. in an S3-compatible
+// object store or on a static site host.
+type Publisher interface {
+	Put(ctx context.Context, key, contentType string, r io.Reader) error
+}
+
+// DirPublisher is a Publisher writing files into a directory.
+type DirPublisher string
+
+// Put implements Publisher. Keys resolving outside the directory are
+// refused.
+func (d DirPublisher) Put(ctx context.Context, key, contentType string, r io.Reader) error {
+	name := filepath.Clean(filepath.FromSlash(key))
+	if filepath.IsAbs(name) || filepath.VolumeName(name) != "" || name == "." || name == ".." ||
+		strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("chart: key %q outside of %s", key, string(d))
+	}
+	path := filepath.Join(string(d), name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// HTTPPublisher is a Publisher that PUTs each artifact to BaseURL + "/" + key,
+// with each path segment of the key escaped, as accepted by S3-compatible
+// stores behind a signing proxy, WebDAV and many static hosts.
+type HTTPPublisher struct {
+	BaseURL string
+	// Header is added to every request, e.g. for authorization.
+	Header http.Header
+	// Client defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+// Put implements Publisher.
+func (p HTTPPublisher) Put(ctx context.Context, key, contentType string, r io.Reader) error {
+	segments := strings.Split(key, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	req, err := http.NewRequestWithContext(ctx, "PUT", strings.TrimRight(p.BaseURL, "/")+"/"+strings.Join(segments, "/"), r)
+	if err != nil {
+		return err
+	}
+	for k, vs := range p.Header {
+		for _, v := range vs {
+			req.Header.Add(k, v)
+		}
+	}
+	req.Header.Set("Content-Type", contentType)
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("chart: publishing %s: %s", key, resp.Status)
+	}
+	return nil
+}
+
+// PublishHTML renders the charts with SaveCharts and puts the page at key.
+func PublishHTML(ctx context.Context, p Publisher, key string, tmap map[string]interface{}, charts ...Chart) error {
+	var buf bytes.Buffer
+	if err := SaveCharts(&buf, tmap, charts...); err != nil {
+		return err
+	}
+	return p.Put(ctx, key, "text/html; charset=utf-8", &buf)
+}
+
+// PublishPNG puts a PNG Thumbnail of the chart at key.
+func PublishPNG(ctx context.Context, p Publisher, key string, c Chart, width, height int) error {
+	var buf bytes.Buffer
+	if err := c.Thumbnail(&buf, width, height); err != nil {
+		return err
+	}
+	return p.Put(ctx, key, "image/png", &buf)
+}
+
+// PublishJSON puts the chart config at key.
+func PublishJSON(ctx context.Context, p Publisher, key string, c Chart) error {
+	return PublishEncoded(ctx, p, key, c, JSONEncoder{})
+}
+
+// PublishEncoded puts the chart config encoded by e at key.
+func PublishEncoded(ctx context.Context, p Publisher, key string, c Chart, e Encoder) error {
+	var buf bytes.Buffer
+	if err := e.Encode(&buf, c); err != nil {
+		return err
+	}
+	return p.Put(ctx, key, e.ContentType(), &buf)
+}
+
+// PublishReport puts the index and pages of a report under prefix, or at the
+// top level if prefix is empty.
+func PublishReport(ctx context.Context, p Publisher, prefix string, r Report, tmap map[string]interface{}) error {
+	dir, err := os.MkdirTemp("", "chartjs-report")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	if err := r.Save(dir, tmap); err != nil {
+		return err
+	}
+	names := []string{"index.html"}
+	for i := range r.Pages {
+		names = append(names, pageFile(i))
+	}
+	prefix = strings.TrimRight(prefix, "/")
+	for _, name := range names {
+		b, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return err
+		}
+		key := name
+		if prefix != "" {
+			key = prefix + "/" + name
+		}
+		if err := p.Put(ctx, key, "text/html; charset=utf-8", bytes.NewReader(b)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+// Package quickchart renders charts as images with QuickChart
+// (https://quickchart.io), so that static images can be produced without
+// any local rendering infrastructure.
+package quickchart
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	chartjs "github.com/iszk1215/go-chartjs"
+)
+
+// BaseURL is the QuickChart server, which can be self-hosted.
+var BaseURL = "https://quickchart.io"
+
+// Options configure the rendered image. Zero values use the defaults of
+// QuickChart.
+type Options struct {
+	Width, Height    int
+	DevicePixelRatio float64
+	// BackgroundColor is a CSS color, e.g. "white" or "#fff".
+	BackgroundColor string
+	// Format is "png", "webp", "svg" or "pdf".
+	Format string
+	// Version is the chart.js version to render with, e.g. "2" or "4".
+	Version string
+}
+
+// Config returns the chart as a compact javascript object literal, as sent to
+// QuickChart. Numbers are written in their shortest form, e.g. 1.50 as 1.5.
+func Config(c chartjs.Chart) (string, error) {
+	b, err := c.MarshalTagged()
+	if err != nil {
+		return "", err
+	}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	e := json.NewEncoder(&buf)
+	e.SetEscapeHTML(false)
+	if err := e.Encode(shorten(v)); err != nil {
+		return "", err
+	}
+	return string(chartjs.InlineJS(bytes.TrimSpace(buf.Bytes()))), nil
+}
+
+// shorten rewrites the numbers of a decoded JSON value in their shortest form.
+func shorten(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = shorten(e)
+		}
+	case []interface{}:
+		for i, e := range v {
+			v[i] = shorten(e)
+		}
+	case json.Number:
+		if f, err := v.Float64(); err == nil {
+			return json.Number(strconv.FormatFloat(f, 'g', -1, 64))
+		}
+	}
+	return v
+}
+
+// params returns the options as the query parameters of the render API.
+func (o Options) params() url.Values {
+	q := url.Values{}
+	if o.Width > 0 {
+		q.Set("w", strconv.Itoa(o.Width))
+	}
+	if o.Height > 0 {
+		q.Set("h", strconv.Itoa(o.Height))
+	}
+	if o.DevicePixelRatio > 0 {
+		q.Set("devicePixelRatio", strconv.FormatFloat(o.DevicePixelRatio, 'g', -1, 64))
+	}
+	if o.BackgroundColor != "" {
+		q.Set("bkg", o.BackgroundColor)
+	}
+	if o.Format != "" {
+		q.Set("f", o.Format)
+	}
+	if o.Version != "" {
+		q.Set("v", o.Version)
+	}
+	return q
+}
+
+// URL returns the address of an image of the chart. The whole config is in
+// the URL, which may get too long for large charts; see ShortURL.
+func URL(c chartjs.Chart, opts Options) (string, error) {
+	cfg, err := Config(c)
+	if err != nil {
+		return "", err
+	}
+	q := opts.params()
+	q.Set("c", cfg)
+	return BaseURL + "/chart?" + q.Encode(), nil
+}
+
+// ShortURL stores the chart on QuickChart and returns a short address of its
+// image. client defaults to http.DefaultClient.
+func ShortURL(ctx context.Context, client *http.Client, c chartjs.Chart, opts Options) (string, error) {
+	cfg, err := Config(c)
+	if err != nil {
+		return "", err
+	}
+	body, err := json.Marshal(struct {
+		Chart            string  `json:"chart"`
+		Width            int     `json:"width,omitempty"`
+		Height           int     `json:"height,omitempty"`
+		DevicePixelRatio float64 `json:"devicePixelRatio,omitempty"`
+		BackgroundColor  string  `json:"backgroundColor,omitempty"`
+		Format           string  `json:"format,omitempty"`
+		Version          string  `json:"version,omitempty"`
+	}{cfg, opts.Width, opts.Height, opts.DevicePixelRatio, opts.BackgroundColor, opts.Format, opts.Version})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", BaseURL+"/chart/create", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	var out struct {
+		Success bool   `json:"success"`
+		URL     string `json:"url"`
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("quickchart: short URL request failed: %s", resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", err
+	}
+	if !out.Success || out.URL == "" {
+		return "", fmt.Errorf("quickchart: short URL request was not successful")
+	}
+	return out.URL, nil
+}
@@ -0,0 +1,81 @@
+package quickchart
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	chartjs "github.com/iszk1215/go-chartjs"
+)
+
+type ys []float64
+
+func (v ys) Xs() []float64 { return nil }
+func (v ys) Ys() []float64 { return v }
+func (v ys) Rs() []float64 { return nil }
+
+func newChart() chartjs.Chart {
+	c := chartjs.Chart{Type: chartjs.Bar, Data: chartjs.Data{Labels: []string{"a", "b"}}}
+	c.AddDataset(chartjs.Dataset{Label: "<x>", Data: ys{1.5, 2}})
+	c.Options.OnClick = "function() {}"
+	return c
+}
+
+func TestURL(t *testing.T) {
+	u, err := URL(newChart(), Options{Width: 500, Height: 300, Format: "svg"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(u, "https://quickchart.io/chart?") {
+		t.Errorf("unexpected URL %s", u)
+	}
+	p, err := url.Parse(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := p.Query()
+	if q.Get("w") != "500" || q.Get("h") != "300" || q.Get("f") != "svg" {
+		t.Errorf("unexpected parameters %v", q)
+	}
+	cfg := q.Get("c")
+	for _, want := range []string{`"type":"bar"`, `"data":[1.5,2]`, `"label":"<x>"`, `"onClick":function() {}`} {
+		if !strings.Contains(cfg, want) {
+			t.Errorf("expected %s in %s", want, cfg)
+		}
+	}
+}
+
+func TestShortURL(t *testing.T) {
+	var got map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/chart/create" || r.Method != "POST" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte(`{"success":true,"url":"https://quickchart.io/chart/render/abc"}`))
+	}))
+	defer srv.Close()
+	defer func(u string) { BaseURL = u }(BaseURL)
+	BaseURL = srv.URL
+
+	u, err := ShortURL(context.Background(), srv.Client(), newChart(), Options{Width: 500})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u != "https://quickchart.io/chart/render/abc" {
+		t.Errorf("unexpected short URL %s", u)
+	}
+	if got["width"] != 500.0 || !strings.Contains(got["chart"].(string), `"type":"bar"`) {
+		t.Errorf("unexpected request %v", got)
+	}
+
+	BaseURL = srv.URL + "/missing"
+	if _, err := ShortURL(context.Background(), srv.Client(), newChart(), Options{}); err == nil {
+		t.Error("expected an error for a failed request")
+	}
+}
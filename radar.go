@@ -0,0 +1,96 @@
+package chartjs
+
+import (
+	"fmt"
+	"math"
+)
+
+type radarScaling int
+
+const (
+	// MinMaxScaling maps each axis to 0–1, from its smallest to its largest value.
+	MinMaxScaling radarScaling = iota
+	// ZScoreScaling maps each axis to standard scores.
+	ZScoreScaling
+)
+
+// NormalizeRadar rescales each axis of a Radar chart, i.e. each label, across
+// the datasets so that axes in different units can be compared. Tooltips
+// keep showing the original values. Axes where all values are equal are
+// placed at 0.5 for MinMaxScaling and at 0 for ZScoreScaling. NaN values are
+// ignored.
+func (c *Chart) NormalizeRadar(s radarScaling) error {
+	raw := make([][]float64, len(c.Data.Datasets))
+	for i, d := range c.Data.Datasets {
+		v, ok := d.Data.(Values)
+		if !ok {
+			return fmt.Errorf("chart: dataset %q does not hold Values", d.Label)
+		}
+		raw[i] = plotted(v)
+		if len(raw[i]) != len(c.Data.Labels) {
+			return fmt.Errorf("chart: dataset %q has %d values for %d labels", d.Label, len(raw[i]), len(c.Data.Labels))
+		}
+	}
+	norm := make([][]float64, len(raw))
+	for i := range raw {
+		norm[i] = make([]float64, len(c.Data.Labels))
+	}
+	for j := range c.Data.Labels {
+		var col []float64
+		for i := range raw {
+			if !math.IsNaN(raw[i][j]) {
+				col = append(col, raw[i][j])
+			}
+		}
+		for i := range raw {
+			norm[i][j] = scaleRadar(s, col, raw[i][j])
+		}
+	}
+	for i := range c.Data.Datasets {
+		c.Data.Datasets[i].Data = xyValues{xs: norm[i]}
+	}
+
+	r, ok := c.Options.Scales["r"]
+	if !ok {
+		r = Axis{ID: "r"}
+	}
+	if s == MinMaxScaling {
+		lo, hi := 0.0, 1.0
+		r.SuggestedMin, r.SuggestedMax = &lo, &hi
+	}
+	c.AddRAxis(r)
+
+	label, err := rawTooltipLabel(raw)
+	if err != nil {
+		return err
+	}
+	c.tooltipCallbacks().Label = label
+	return nil
+}
+
+func scaleRadar(s radarScaling, col []float64, v float64) float64 {
+	if math.IsNaN(v) {
+		return v
+	}
+	switch s {
+	case ZScoreScaling:
+		var sum float64
+		for _, x := range col {
+			sum += x
+		}
+		sd := stddev(col)
+		if sd == 0 {
+			return 0
+		}
+		return (v - sum/float64(len(col))) / sd
+	default:
+		lo, hi := col[0], col[0]
+		for _, x := range col {
+			lo, hi = math.Min(lo, x), math.Max(hi, x)
+		}
+		if hi == lo {
+			return 0.5
+		}
+		return (v - lo) / (hi - lo)
+	}
+}
@@ -0,0 +1,77 @@
+package chartjs
+
+import (
+	"fmt"
+	"math"
+)
+
+// Redactor rewrites the label of a series and each of its values, e.g. to
+// rename internal series or blur absolute values for external audiences.
+type Redactor func(label string, v float64) (string, float64)
+
+// metaValues is a plain MetaValues implementation.
+type metaValues struct {
+	xyValues
+	meta []map[string]string
+}
+
+func (v metaValues) Meta() []map[string]string { return v.meta }
+
+// redact returns the dataset with the redactor applied to its label and
+// plotted values. The label is the one returned for the first value.
+func (d Dataset) redact(r Redactor) (Dataset, error) {
+	// the cache holds the data before redaction.
+	d.Cache = nil
+	apply := func(vs []float64) []float64 {
+		out := make([]float64, len(vs))
+		for i, v := range vs {
+			var label string
+			label, out[i] = r(d.Label, v)
+			if i == 0 {
+				d.Label = label
+			}
+		}
+		if len(vs) == 0 {
+			d.Label, _ = r(d.Label, math.NaN())
+		}
+		return out
+	}
+	switch v := d.Data.(type) {
+	case nil:
+		d.Label, _ = r(d.Label, math.NaN())
+	case MatrixValues:
+		d.Data = matrixValues{xs: v.Xs(), ys: v.Ys(), vs: apply(v.Vs())}
+	case TimeValues:
+		d.Data = TimeSeries{Time: v.Times(), Value: apply(v.Ys())}
+	case Values:
+		xy := xyValues{xs: v.Xs(), ys: v.Ys(), rs: v.Rs()}
+		if len(xy.ys) > 0 {
+			xy.ys = apply(xy.ys)
+		} else {
+			xy.xs = apply(xy.xs)
+		}
+		d.Data = xy
+		if m, ok := v.(MetaValues); ok {
+			d.Data = metaValues{xy, m.Meta()}
+		} else if l, ok := v.(LabeledValues); ok {
+			d.Data = mapValues{xy, l.Labels()}
+		}
+	default:
+		return d, fmt.Errorf("chart: cannot redact dataset %q of %T", d.Label, d.Data)
+	}
+	return d, nil
+}
+
+// redact returns a copy of the data with the redactor applied to every
+// dataset.
+func (d Data) redact(r Redactor) (Data, error) {
+	datasets := make([]Dataset, len(d.Datasets))
+	for i, ds := range d.Datasets {
+		var err error
+		if datasets[i], err = ds.redact(r); err != nil {
+			return d, err
+		}
+	}
+	d.Datasets = datasets
+	return d, nil
+}
@@ -0,0 +1,177 @@
+package chartjs
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ChartProvider returns a chart, typically built from fresh data.
+type ChartProvider func() (*Chart, error)
+
+// Personalizer returns the chart as shown for a request, e.g. with the
+// datasets of other tenants filtered out or values in the units preferred
+// by the user, or nil to hide it. c is a copy of the served chart, whose
+// fields may be replaced, but its slices and maps are shared and must be
+// copied before they are modified.
+type Personalizer func(r *http.Request, c *Chart) *Chart
+
+// personalize returns the chart for the request, c itself if p is nil.
+func personalize(p Personalizer, r *http.Request, c *Chart) *Chart {
+	if p == nil || c == nil {
+		return c
+	}
+	cc := *c
+	return p(r, &cc)
+}
+
+// Registry holds charts by ID and serves them over HTTP. Mount it with its
+// prefix stripped, e.g.
+//
+//	mux.Handle("/charts/", http.StripPrefix("/charts", reg))
+//
+// to list the charts at /charts/, render one at /charts/{id} and serve its
+// preview image at /charts/{id}/og.png.
+type Registry struct {
+	// TMap is passed to SaveCharts when rendering a chart.
+	TMap map[string]interface{}
+	// Personalize, if set, adapts every chart served to the request.
+	// Preview images of personalized charts are only cached privately.
+	Personalize Personalizer
+
+	mu        sync.RWMutex
+	providers map[string]ChartProvider
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{providers: map[string]ChartProvider{}}
+}
+
+// Register adds a chart under id.
+func (r *Registry) Register(id string, c *Chart) error {
+	return r.RegisterFunc(id, func() (*Chart, error) { return c, nil })
+}
+
+// RegisterFunc adds a chart provider under id, called on every request.
+func (r *Registry) RegisterFunc(id string, fn ChartProvider) error {
+	if id == "" || strings.Contains(id, "/") {
+		return fmt.Errorf("chart: bad registry id %q", id)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.providers == nil {
+		r.providers = map[string]ChartProvider{}
+	}
+	if _, ok := r.providers[id]; ok {
+		return fmt.Errorf("chart: id %q already registered", id)
+	}
+	r.providers[id] = fn
+	return nil
+}
+
+// IDs returns the registered IDs in sorted order.
+func (r *Registry) IDs() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	ids := make([]string, 0, len(r.providers))
+	for id := range r.providers {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Lookup returns the chart registered under id, or nil if there is none.
+func (r *Registry) Lookup(id string) (*Chart, error) {
+	r.mu.RLock()
+	fn, ok := r.providers[id]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, nil
+	}
+	return fn()
+}
+
+// lookup returns the chart registered under id as shown for the request.
+func (r *Registry) lookup(req *http.Request, id string) (*Chart, error) {
+	c, err := r.Lookup(id)
+	if err != nil {
+		return nil, err
+	}
+	return personalize(r.Personalize, req, c), nil
+}
+
+var registryList = template.Must(template.New("list").Parse(`<!DOCTYPE html>
+<html>
+    <head><title>charts</title></head>
+    <body>
+	<ul>
+	{{ range . }}<li><a href="{{ . }}">{{ . }}</a></li>
+	{{ end }}
+	</ul>
+    </body>
+</html>`))
+
+// ServeHTTP implements http.Handler.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	path := strings.Trim(req.URL.Path, "/")
+	if path == "" {
+		if !strings.HasSuffix(req.URL.Path, "/") {
+			// the links of the list are relative to the directory.
+			u, err := url.ParseRequestURI(req.RequestURI)
+			if err != nil {
+				u = req.URL
+			}
+			target := u.Path + "/"
+			if u.RawQuery != "" {
+				target += "?" + u.RawQuery
+			}
+			http.Redirect(w, req, target, http.StatusMovedPermanently)
+			return
+		}
+		var buf bytes.Buffer
+		if err := registryList.Execute(&buf, r.IDs()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(buf.Bytes())
+		return
+	}
+	if strings.HasSuffix(path, "/og.png") {
+		scope := "public"
+		if r.Personalize != nil {
+			scope = "private"
+		}
+		ogImageHandler(r.lookup, time.Hour, scope).ServeHTTP(w, req)
+		return
+	}
+	c, err := r.lookup(req, path)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if c == nil {
+		http.NotFound(w, req)
+		return
+	}
+	tmap := make(map[string]interface{}, len(r.TMap))
+	for k, v := range r.TMap {
+		tmap[k] = v
+	}
+	// render to a buffer so that errors can still be reported.
+	var buf bytes.Buffer
+	if err := SaveCharts(&buf, tmap, *c); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(buf.Bytes())
+}
@@ -0,0 +1,110 @@
+package chartjs
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+)
+
+// ReportPage is one page of a Report.
+type ReportPage struct {
+	Title  string
+	Charts []Chart
+}
+
+// Report is a collection of chart pages with a table of contents, so large
+// collections of charts don't all load on one page.
+type Report struct {
+	Title string
+	Pages []ReportPage
+}
+
+const reportIndexTmpl = `<!DOCTYPE html>
+<html>
+    <head>
+		<title>{{ .Title }}</title>
+    </head>
+    <body>
+	<h1>{{ .Title }}</h1>
+	<ol class="chartjs-toc">
+	{{ range $i, $p := .Pages }}
+		<li><a href="{{ index $.Files $i }}">{{ $p.Title }}</a>
+		<ul>{{ range $p.Charts }}{{ if .Label }}<li>{{ .Label }}</li>{{ end }}{{ end }}</ul>
+		</li>
+	{{ end }}
+	</ol>
+    </body>
+</html>`
+
+const reportNavTmpl = `<nav class="chartjs-nav">
+	{{ if .Prev }}<a href="{{ .Prev }}">&larr; previous</a> |{{ end }}
+	<a href="index.html">contents</a>
+	{{ if .Next }}| <a href="{{ .Next }}">next &rarr;</a>{{ end }}
+	<h2>{{ .Title }}</h2>
+</nav>`
+
+var (
+	reportIndex = template.Must(template.New("index").Parse(reportIndexTmpl))
+	reportNav   = template.Must(template.New("nav").Parse(reportNavTmpl))
+)
+
+// pageFile is the file name of the i'th page.
+func pageFile(i int) string {
+	return fmt.Sprintf("page-%d.html", i+1)
+}
+
+// Save writes index.html with the table of contents and one HTML file per page
+// to dir. tmap is passed to SaveCharts for every page.
+func (r Report) Save(dir string, tmap map[string]interface{}) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	files := make([]string, len(r.Pages))
+	for i := range r.Pages {
+		files[i] = pageFile(i)
+	}
+	if err := writeFile(filepath.Join(dir, "index.html"), func(f *os.File) error {
+		return reportIndex.Execute(f, map[string]interface{}{"Title": r.Title, "Pages": r.Pages, "Files": files})
+	}); err != nil {
+		return err
+	}
+
+	for i, p := range r.Pages {
+		nav := map[string]string{"Title": p.Title}
+		if i > 0 {
+			nav["Prev"] = files[i-1]
+		}
+		if i < len(files)-1 {
+			nav["Next"] = files[i+1]
+		}
+		var buf bytes.Buffer
+		if err := reportNav.Execute(&buf, nav); err != nil {
+			return err
+		}
+		m := make(map[string]interface{}, len(tmap)+1)
+		for k, v := range tmap {
+			m[k] = v
+		}
+		m["header"] = template.HTML(buf.String())
+		if err := writeFile(filepath.Join(dir, files[i]), func(f *os.File) error {
+			return SaveCharts(f, m, p.Charts...)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeFile(path string, fn func(f *os.File) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := fn(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
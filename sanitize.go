@@ -0,0 +1,67 @@
+package chartjs
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Labels and titles are always escaped for the context they are rendered in:
+// encoding/json escapes quotes and <, > and & in the chart JSON, and
+// html/template escapes the surrounding page. The sanitizer below is an
+// additional, opt-in step for labels from untrusted sources, e.g. when they
+// are later shown in HTML legends or tooltips.
+
+var tagRe = regexp.MustCompile(`<[^>]*>?`)
+
+// SanitizeLabel removes HTML tags and control characters from a label and
+// collapses runs of whitespace.
+func SanitizeLabel(s string) string {
+	s = tagRe.ReplaceAllString(s, "")
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == unicode.ReplacementChar {
+			return ' '
+		}
+		return r
+	}, s)
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func sanitizeAll(ss []string) {
+	for i, s := range ss {
+		ss[i] = SanitizeLabel(s)
+	}
+}
+
+// SanitizeLabels applies SanitizeLabel to the labels, dataset labels and
+// titles of the chart.
+func (c *Chart) SanitizeLabels() {
+	sanitizeAll(c.Data.Labels)
+	sanitizeAll(c.Data.FullLabels)
+	for _, lines := range c.Data.LabelLines {
+		sanitizeAll(lines)
+	}
+	for i := range c.Data.Datasets {
+		c.Data.Datasets[i].Label = SanitizeLabel(c.Data.Datasets[i].Label)
+	}
+	c.Label = SanitizeLabel(c.Label)
+	c.EmptyText = SanitizeLabel(c.EmptyText)
+	if t := c.Options.Title; t != nil {
+		t.Text = SanitizeLabel(t.Text)
+		sanitizeAll(t.TextLines)
+	}
+	for id, a := range c.Options.Scales {
+		a.Title.Text = SanitizeLabel(a.Title.Text)
+		sanitizeAll(a.Title.TextLines)
+		if a.ScaleLabel != nil {
+			l := *a.ScaleLabel
+			l.LabelString = SanitizeLabel(l.LabelString)
+			sanitizeAll(l.LabelLines)
+			a.ScaleLabel = &l
+		}
+		for _, lines := range a.Labels {
+			sanitizeAll(lines)
+		}
+		c.Options.Scales[id] = a
+	}
+}
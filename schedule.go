@@ -0,0 +1,80 @@
+package chartjs
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Snapshot is a chart rendered by a Scheduler.
+type Snapshot struct {
+	// Name is the base name of the written files.
+	Name string
+	// Chart returns the chart to render, so that it reflects fresh data. A nil
+	// chart is an error.
+	Chart func() (*Chart, error)
+	// PNG and HTML select the formats to write.
+	PNG, HTML bool
+}
+
+// Scheduler renders snapshots of charts whenever its cron schedule fires,
+// e.g. for nightly reports from long-running services.
+type Scheduler struct {
+	Schedule  *Cron
+	Publisher Publisher
+	Snapshots []Snapshot
+	// Width and Height size the PNG images. Defaults to 800x400.
+	Width, Height int
+	// OnError is called with errors from scheduled runs, which don't stop the scheduler.
+	OnError func(error)
+}
+
+// Run renders the snapshots on schedule until ctx is done.
+func (s *Scheduler) Run(ctx context.Context) error {
+	for {
+		next := s.Schedule.Next(time.Now())
+		if next.IsZero() {
+			return nil
+		}
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		if err := s.RunOnce(ctx, next); err != nil && s.OnError != nil {
+			s.OnError(err)
+		}
+	}
+}
+
+// RunOnce renders every snapshot now, naming the files
+// "{name}-{yyyymmddThhmm}.{png,html}" after t.
+func (s *Scheduler) RunOnce(ctx context.Context, t time.Time) error {
+	width, height := s.Width, s.Height
+	if width == 0 || height == 0 {
+		width, height = 800, 400
+	}
+	stamp := t.Format("20060102T1504")
+	for _, snap := range s.Snapshots {
+		c, err := snap.Chart()
+		if err != nil {
+			return err
+		}
+		if c == nil {
+			return fmt.Errorf("chart: no chart for snapshot %q", snap.Name)
+		}
+		if snap.PNG {
+			if err := PublishPNG(ctx, s.Publisher, snap.Name+"-"+stamp+".png", *c, width, height); err != nil {
+				return err
+			}
+		}
+		if snap.HTML {
+			if err := PublishHTML(ctx, s.Publisher, snap.Name+"-"+stamp+".html", nil, *c); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package chartjs
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// ConfigError is posted by a page rendered with "errorURL" in tmap when a
+// chart can't be built in the browser.
+type ConfigError struct {
+	// Chart is the Label of the chart and Index its position on the page.
+	Chart string `json:"chart"`
+	Index int    `json:"index"`
+	// Path is the offending config entry, e.g. "options.scales.x.type", if known.
+	Path    string `json:"path,omitempty"`
+	Message string `json:"message"`
+	// Page is the URL of the page that failed.
+	Page string `json:"page"`
+}
+
+// ErrorReportHandler receives the ConfigErrors posted by pages.
+func ErrorReportHandler(fn func(ConfigError)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			http.Error(w, "chart: errors must be posted", http.StatusMethodNotAllowed)
+			return
+		}
+		var e ConfigError
+		if err := json.NewDecoder(r.Body).Decode(&e); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		fn(e)
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// selfCheckJS checks the chart and scale types of a config before building the
+// chart, and posts any failure to url. It returns the chart or null.
+const selfCheckJS = `function chartjsSelfCheck(url, i, cfg, build) {
+	var path = "", chart = null;
+	function known(type) { return !type || Chart.controllers[type] !== undefined; }
+	function scale(type) { return !type || !Chart.scaleService || Chart.scaleService.getScaleConstructor(type) !== undefined; }
+	try {
+		if (!known(cfg.type)) { path = "type"; throw new Error("unknown chart type " + cfg.type); }
+		((cfg.data || {}).datasets || []).forEach(function(d, k) {
+			if (!known(d.type)) { path = "data.datasets." + k + ".type"; throw new Error("unknown dataset type " + d.type); }
+		});
+		var scales = (cfg.options || {}).scales || {};
+		for (var id in scales) {
+			if (scales[id] && !scale(scales[id].type)) { path = "options.scales." + id + ".type"; throw new Error("unknown scale type " + scales[id].type); }
+		}
+		chart = build();
+	} catch (e) {
+		fetch(url, {method: "POST", headers: {"Content-Type": "application/json"}, body: JSON.stringify({
+			chart: cfg.label || "", index: i, path: path, message: String(e && e.message || e), page: location.href})});
+	}
+	return chart;
+}
+`
@@ -0,0 +1,47 @@
+package chartjs
+
+// Number is the constraint of the numeric types that Series can hold.
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64
+}
+
+func toFloats[T Number](vs []T) []float64 {
+	if vs == nil {
+		return nil
+	}
+	out := make([]float64, len(vs))
+	for i, v := range vs {
+		out[i] = float64(v)
+	}
+	return out
+}
+
+// Series are Values of a single series of any numeric type, e.g.
+// Series[int64](counts), as Floats is for []float64.
+type Series[T Number] []T
+
+// Xs returns the values as float64.
+func (s Series[T]) Xs() []float64 { return toFloats(s) }
+
+// Ys returns nil.
+func (s Series[T]) Ys() []float64 { return nil }
+
+// Rs returns nil.
+func (s Series[T]) Rs() []float64 { return nil }
+
+// Points are Values of the points (X[i], Y[i]) of any numeric type, with
+// optional radii R for Bubble charts.
+type Points[T Number] struct {
+	X, Y, R []T
+}
+
+// Xs returns X as float64.
+func (p Points[T]) Xs() []float64 { return toFloats(p.X) }
+
+// Ys returns Y as float64.
+func (p Points[T]) Ys() []float64 { return toFloats(p.Y) }
+
+// Rs returns R as float64.
+func (p Points[T]) Rs() []float64 { return toFloats(p.R) }
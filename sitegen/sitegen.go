@@ -0,0 +1,147 @@
+// Package sitegen writes collections of charts as a static site that can be
+// deployed behind any web server, without a live Go service.
+package sitegen
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"regexp"
+	"strings"
+
+	chartjs "github.com/iszk1215/go-chartjs"
+)
+
+// ThumbWidth and ThumbHeight are the size of the previews on the index page.
+var (
+	ThumbWidth  = 240
+	ThumbHeight = 120
+)
+
+const style = `body { font-family: sans-serif; margin: 2em; }
+.sitegen-index { display: flex; flex-wrap: wrap; gap: 1em; list-style: none; padding: 0; }
+.sitegen-index li { border: 1px solid #ddd; padding: .5em; }
+.sitegen-index img { display: block; }
+`
+
+const indexTmpl = `<!DOCTYPE html>
+<html>
+    <head>
+		<title>{{ .Title }}</title>
+		<link rel="stylesheet" href="assets/style.css">
+    </head>
+    <body>
+	<h1>{{ .Title }}</h1>
+	<ul class="sitegen-index">
+	{{ range .Pages }}
+		<li><a href="{{ .ID }}/index.html"><img src="{{ .ID }}/thumb.png" alt="{{ .Title }}">{{ .Title }}</a></li>
+	{{ end }}
+	</ul>
+    </body>
+</html>`
+
+var index = template.Must(template.New("index").Parse(indexTmpl))
+
+// Page is a chart or a dashboard of charts on the site.
+type Page struct {
+	ID     string
+	Title  string
+	Charts []chartjs.Chart
+}
+
+// Site is a collection of pages.
+type Site struct {
+	Title string
+	Pages []Page
+	// TMap is passed to chartjs.SaveCharts for every page.
+	TMap map[string]interface{}
+}
+
+// AddChart adds a page with a single chart, titled with its Label.
+func (s *Site) AddChart(id string, c chartjs.Chart) {
+	s.Pages = append(s.Pages, Page{ID: id, Title: c.Label, Charts: []chartjs.Chart{c}})
+}
+
+// AddDashboard adds a page with several charts.
+func (s *Site) AddDashboard(id, title string, charts ...chartjs.Chart) {
+	s.Pages = append(s.Pages, Page{ID: id, Title: title, Charts: charts})
+}
+
+// AddRegistry adds a page for every chart of the registry, in the order of
+// its IDs. The charts are looked up once, without personalization.
+func (s *Site) AddRegistry(r *chartjs.Registry) error {
+	for _, id := range r.IDs() {
+		c, err := r.Lookup(id)
+		if err != nil {
+			return fmt.Errorf("sitegen: chart %q: %v", id, err)
+		}
+		if c != nil {
+			s.AddChart(id, *c)
+		}
+	}
+	return nil
+}
+
+// pageID matches the page ids usable as directory names.
+var pageID = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Generate writes the site: index.html, assets/style.css and for every page
+// {id}/index.html, {id}/thumb.png and {id}/charts.json.
+func (s *Site) Generate(ctx context.Context, p chartjs.Publisher) error {
+	// pages are defaulted on a copy, leaving the site unchanged.
+	pages := make([]Page, len(s.Pages))
+	seen := map[string]bool{}
+	for i, page := range s.Pages {
+		if !pageID.MatchString(page.ID) || page.ID == "assets" || seen[page.ID] {
+			return fmt.Errorf("sitegen: bad or duplicate page id %q", page.ID)
+		}
+		seen[page.ID] = true
+		if page.Title == "" {
+			page.Title = page.ID
+		}
+		pages[i] = page
+	}
+
+	var buf bytes.Buffer
+	if err := index.Execute(&buf, Site{Title: s.Title, Pages: pages}); err != nil {
+		return err
+	}
+	if err := p.Put(ctx, "index.html", "text/html; charset=utf-8", &buf); err != nil {
+		return err
+	}
+	if err := p.Put(ctx, "assets/style.css", "text/css", strings.NewReader(style)); err != nil {
+		return err
+	}
+
+	for _, page := range pages {
+		tmap := make(map[string]interface{}, len(s.TMap)+1)
+		for k, v := range s.TMap {
+			tmap[k] = v
+		}
+		tmap["header"] = template.HTML(`<link rel="stylesheet" href="../assets/style.css"><a href="../index.html">&larr; ` +
+			template.HTMLEscapeString(s.Title) + `</a><h1>` + template.HTMLEscapeString(page.Title) + `</h1>`)
+		if err := chartjs.PublishHTML(ctx, p, page.ID+"/index.html", tmap, page.Charts...); err != nil {
+			return err
+		}
+		if len(page.Charts) > 0 {
+			if err := chartjs.PublishPNG(ctx, p, page.ID+"/thumb.png", page.Charts[0], ThumbWidth, ThumbHeight); err != nil {
+				return err
+			}
+		}
+		if err := publishConfigs(ctx, p, page.ID+"/charts.json", page.Charts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// publishConfigs puts the configs of the charts as a JSON array.
+func publishConfigs(ctx context.Context, p chartjs.Publisher, key string, charts []chartjs.Chart) error {
+	b, err := json.Marshal(charts)
+	if err != nil {
+		return err
+	}
+	return p.Put(ctx, key, "application/json", bytes.NewReader(b))
+}
@@ -0,0 +1,84 @@
+package sitegen
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	chartjs "github.com/iszk1215/go-chartjs"
+)
+
+type ys []float64
+
+func (v ys) Xs() []float64 { return nil }
+func (v ys) Ys() []float64 { return v }
+func (v ys) Rs() []float64 { return nil }
+
+func TestGenerate(t *testing.T) {
+	dir, err := os.MkdirTemp("", "sitegen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c := chartjs.Chart{Type: chartjs.Bar, Label: "requests", Data: chartjs.Data{Labels: []string{"a", "b"}}}
+	c.AddDataset(chartjs.Dataset{Data: ys{1, 2}})
+	s := Site{Title: "ops"}
+	s.AddChart("requests", c)
+	s.AddDashboard("overview", "Overview", c, c)
+	if err := s.Generate(context.Background(), chartjs.DirPublisher(dir)); err != nil {
+		t.Fatalf("error generating site: %+v", err)
+	}
+
+	for _, name := range []string{"index.html", "assets/style.css", "requests/index.html", "requests/thumb.png", "overview/charts.json"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("expected %s: %v", name, err)
+		}
+	}
+	index, _ := os.ReadFile(filepath.Join(dir, "index.html"))
+	if !strings.Contains(string(index), `<a href="overview/index.html">`) {
+		t.Errorf("expected index to link pages: %s", index)
+	}
+
+	untitled := Site{}
+	untitled.AddDashboard("empty", "")
+	if err := untitled.Generate(context.Background(), chartjs.DirPublisher(dir)); err != nil {
+		t.Fatal(err)
+	}
+	if untitled.Pages[0].Title != "" {
+		t.Errorf("expected the site to be unchanged, got title %q", untitled.Pages[0].Title)
+	}
+
+	s.AddChart("requests", c)
+	if err := s.Generate(context.Background(), chartjs.DirPublisher(dir)); err == nil {
+		t.Errorf("expected error for duplicate id")
+	}
+	for _, id := range []string{".", "..", "a b", "a/b"} {
+		s := Site{}
+		s.AddChart(id, c)
+		if err := s.Generate(context.Background(), chartjs.DirPublisher(dir)); err == nil {
+			t.Errorf("expected error for page id %q", id)
+		}
+	}
+}
+
+func TestAddRegistry(t *testing.T) {
+	c := chartjs.Chart{Type: chartjs.Line, Label: "cpu"}
+	c.AddDataset(chartjs.Dataset{Data: ys{1, 2}})
+	reg := chartjs.NewRegistry()
+	if err := reg.Register("cpu", &c); err != nil {
+		t.Fatal(err)
+	}
+	if err := reg.RegisterFunc("gone", func() (*chartjs.Chart, error) { return nil, nil }); err != nil {
+		t.Fatal(err)
+	}
+	var s Site
+	if err := s.AddRegistry(reg); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Pages) != 1 || s.Pages[0].ID != "cpu" || s.Pages[0].Title != "cpu" {
+		t.Errorf("unexpected pages %+v", s.Pages)
+	}
+}
@@ -0,0 +1,123 @@
+package chartjs
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/iszk1215/go-chartjs/types"
+)
+
+// BurnRateThreshold is a burn rate at which an SLO alert fires.
+type BurnRateThreshold struct {
+	BurnRate float64
+	Label    string
+	Color    types.RGBA
+}
+
+// DefaultBurnRateThresholds are the page and ticket thresholds for the 1h
+// and 6h windows recommended by the Google SRE workbook.
+var DefaultBurnRateThresholds = []BurnRateThreshold{
+	{BurnRate: 14.4, Label: "page", Color: types.RGBA{R: 214, G: 39, B: 40, A: 48}},
+	{BurnRate: 6, Label: "ticket", Color: types.RGBA{R: 255, G: 127, B: 14, A: 48}},
+}
+
+// SLO is a service level objective, e.g. 99.9% of requests succeed.
+type SLO struct {
+	// Objective is the target ratio of good events, e.g. 0.999.
+	Objective float64
+	// Windows are the lookback windows of the burn rates, 1h and 6h by default.
+	Windows []time.Duration
+	// Thresholds are shaded on the chart, DefaultBurnRateThresholds by default.
+	Thresholds []BurnRateThreshold
+}
+
+// BurnRate returns the rate at which the error budget is spent over the
+// window ending at each time: 1 spends the budget exactly over the SLO
+// period. good and total are cumulative counters sampled at ts. Points
+// without a full window, or where total does not increase, are NaN.
+func (s SLO) BurnRate(ts []time.Time, good, total []float64, window time.Duration) ([]float64, error) {
+	if len(good) != len(ts) || len(total) != len(ts) {
+		return nil, fmt.Errorf("chart: SLO counters must have one value per time")
+	}
+	if s.Objective <= 0 || s.Objective >= 1 {
+		return nil, fmt.Errorf("chart: bad SLO objective %v", s.Objective)
+	}
+	rates := make([]float64, len(ts))
+	for i, t := range ts {
+		rates[i] = math.NaN()
+		if t.Sub(ts[0]) < window {
+			continue
+		}
+		start := t.Add(-window)
+		j := sort.Search(i, func(k int) bool { return !ts[k].Before(start) })
+		dt := total[i] - total[j]
+		if j == i || dt <= 0 {
+			continue
+		}
+		bad := dt - (good[i] - good[j])
+		rates[i] = bad / dt / (1 - s.Objective)
+	}
+	return rates, nil
+}
+
+// Chart returns a line chart of the burn rate for each window, with the
+// thresholds shaded as annotation bands.
+func (s SLO) Chart(ts []time.Time, good, total []float64) (*Chart, error) {
+	windows := s.Windows
+	if windows == nil {
+		windows = []time.Duration{time.Hour, 6 * time.Hour}
+	}
+	thresholds := s.Thresholds
+	if thresholds == nil {
+		thresholds = DefaultBurnRateThresholds
+	}
+	c := &Chart{Type: Line}
+	if _, err := c.AddXAxis(Axis{Type: Time, Position: Bottom}); err != nil {
+		return nil, err
+	}
+	yID, err := c.AddYAxis(Axis{Type: Linear, Position: Left, Title: AxisTitle{Display: true, Text: "burn rate"}})
+	if err != nil {
+		return nil, err
+	}
+	xs := make([]float64, len(ts))
+	for i, t := range ts {
+		xs[i] = float64(t.UnixNano() / int64(time.Millisecond))
+	}
+	for i, w := range windows {
+		rates, err := s.BurnRate(ts, good, total, w)
+		if err != nil {
+			return nil, err
+		}
+		color := DefaultPalette[i%len(DefaultPalette)]
+		c.AddDataset(Dataset{
+			Label:        fmt.Sprintf("%v burn rate", w),
+			Data:         xyValues{xs: xs, ys: rates},
+			BorderColor:  &color,
+			Fill:         False,
+			XFloatFormat: "%.0f",
+			YAxisID:      yID,
+		})
+	}
+	for _, th := range thresholds {
+		rate, color := th.BurnRate, th.Color
+		border := color.WithAlpha(1)
+		c.AddAnnotation(Annotation{
+			Type:            BoxAnnotation,
+			YScaleID:        yID,
+			YMin:            &rate,
+			BackgroundColor: &color,
+		})
+		c.AddAnnotation(Annotation{
+			Type:        LineAnnotation,
+			Mode:        "horizontal",
+			ScaleID:     yID,
+			Value:       &rate,
+			BorderColor: &border,
+			BorderWidth: 1,
+			Label:       &AnnotationLabel{Enabled: th.Label != "", Content: th.Label},
+		})
+	}
+	return c, nil
+}
@@ -0,0 +1,134 @@
+package chartjs
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/iszk1215/go-chartjs/types"
+)
+
+// StateChange records a service entering a state, e.g. "up" or "down".
+type StateChange struct {
+	Service string
+	Time    time.Time
+	State   string
+}
+
+// StatusState is a state shown on a StatusStrip.
+type StatusState struct {
+	Name  string
+	Color types.RGBA
+}
+
+// DefaultStatusStates are the states used when StatusStrip.States is not set.
+var DefaultStatusStates = []StatusState{
+	{Name: "up", Color: types.RGBA{R: 44, G: 160, B: 44, A: 255}},
+	{Name: "degraded", Color: types.RGBA{R: 255, G: 127, B: 14, A: 255}},
+	{Name: "down", Color: types.RGBA{R: 214, G: 39, B: 40, A: 255}},
+}
+
+// unknownStatus colors buckets before the first change of a service, and
+// states that are not listed.
+var unknownStatus = types.RGBA{R: 199, G: 199, B: 199, A: 255}
+
+// StatusStrip draws one row per service with a segment per time bucket,
+// colored by the worst state of the service during the bucket.
+type StatusStrip struct {
+	// States are ordered from best to worst.
+	States []StatusState
+	// Bucket is the duration of a segment.
+	Bucket time.Duration
+	// Unit replaces Bucket with calendar segments starting at local
+	// midnight in Location, e.g. UnitDay, which stay aligned across DST
+	// transitions. The first segment starts at from.
+	Unit     timeUnit
+	Location *time.Location
+	// Layout formats bucket times in tooltips.
+	Layout string
+}
+
+// Chart returns the status strip of the changes from from to to, as stacked
+// horizontal bars. Services are shown in order of first appearance.
+func (s StatusStrip) Chart(changes []StateChange, from, to time.Time) (*Chart, error) {
+	next := func(t time.Time) time.Time { return t.Add(s.Bucket) }
+	if s.Unit != 0 {
+		next = TimeBuckets{Unit: s.Unit, Location: s.Location}.Next
+	} else if s.Bucket <= 0 {
+		return nil, fmt.Errorf("chart: bad status strip range %v to %v by %v", from, to, s.Bucket)
+	}
+	if !from.Before(to) {
+		return nil, fmt.Errorf("chart: bad status strip range %v to %v by %v", from, to, s.Bucket)
+	}
+	states := s.States
+	if states == nil {
+		states = DefaultStatusStates
+	}
+	rank := make(map[string]int, len(states))
+	for i, st := range states {
+		rank[st.Name] = i
+	}
+	// worst returns the worse of two states. Unlisted states are the worst.
+	worst := func(a, b string) string {
+		if a == "" {
+			return b
+		}
+		ra, ok := rank[a]
+		if !ok {
+			return a
+		}
+		if rb, ok := rank[b]; !ok || rb > ra {
+			return b
+		}
+		return a
+	}
+
+	byService := map[string][]StateChange{}
+	var services []string
+	for _, c := range changes {
+		if _, ok := byService[c.Service]; !ok {
+			services = append(services, c.Service)
+		}
+		byService[c.Service] = append(byService[c.Service], c)
+	}
+	for _, cs := range byService {
+		sort.SliceStable(cs, func(a, b int) bool { return cs[a].Time.Before(cs[b].Time) })
+	}
+
+	c := &Chart{Type: Bar, Data: Data{Labels: services}}
+	c.Options.IndexAxis = "y"
+	c.Options.Legend = &Legend{Display: False}
+	c.AddAxis(Axis{ID: "x", Type: Linear, Stacked: True, Display: False})
+	c.AddAxis(Axis{ID: "y", Type: Category, Stacked: True})
+
+	ones := make([]float64, len(services))
+	for i := range ones {
+		ones[i] = 1
+	}
+	for t, end := from, next(from); t.Before(to); t, end = end, next(end) {
+		label := t
+		if s.Location != nil {
+			label = t.In(s.Location)
+		}
+		d := Dataset{Label: label.Format(s.Layout), Data: xyValues{xs: ones}, BackgroundColors: make([]types.RGBA, len(services))}
+		for i, name := range services {
+			state := ""
+			for _, ch := range byService[name] {
+				if !ch.Time.Before(end) {
+					break
+				}
+				if ch.Time.After(t) {
+					state = worst(state, ch.State)
+				} else {
+					state = ch.State
+				}
+			}
+			d.BackgroundColors[i] = unknownStatus
+			if r, ok := rank[state]; ok {
+				d.BackgroundColors[i] = states[r].Color
+			}
+		}
+		c.AddDataset(d)
+	}
+	return c, nil
+}
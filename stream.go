@@ -0,0 +1,108 @@
+package chartjs
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Extractor returns the time, series name and value carried by an event.
+type Extractor func(msg interface{}) (t time.Time, series string, v float64)
+
+// Stream keeps rolling per-series data built from a stream of events, e.g.
+// messages from a Kafka or NATS consumer. It is safe for concurrent use.
+type Stream struct {
+	// Window is how far back from the newest point of a series points are
+	// kept. Zero keeps them all.
+	Window time.Duration
+	// MaxPoints caps the number of points kept per series. Zero means no cap.
+	MaxPoints int
+
+	mu     sync.Mutex
+	series map[string]*rolling
+	order  []string
+}
+
+// rolling holds the points of one series, oldest first.
+type rolling struct {
+	ts []time.Time
+	vs []float64
+}
+
+// NewStream returns a Stream keeping window worth of points, at most
+// maxPoints per series.
+func NewStream(window time.Duration, maxPoints int) *Stream {
+	return &Stream{Window: window, MaxPoints: maxPoints}
+}
+
+// Add appends a point to a series. Points are expected in time order.
+func (s *Stream) Add(t time.Time, series string, v float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.series == nil {
+		s.series = map[string]*rolling{}
+	}
+	r, ok := s.series[series]
+	if !ok {
+		r = &rolling{}
+		s.series[series] = r
+		s.order = append(s.order, series)
+	}
+	r.ts = append(r.ts, t)
+	r.vs = append(r.vs, v)
+
+	drop := 0
+	if s.Window > 0 {
+		cutoff := t.Add(-s.Window)
+		for drop < len(r.ts) && r.ts[drop].Before(cutoff) {
+			drop++
+		}
+	}
+	if s.MaxPoints > 0 && len(r.ts)-drop > s.MaxPoints {
+		drop = len(r.ts) - s.MaxPoints
+	}
+	if drop > 0 {
+		r.ts = append(r.ts[:0], r.ts[drop:]...)
+		r.vs = append(r.vs[:0], r.vs[drop:]...)
+	}
+}
+
+// Consume adds the point extracted from each event until events is closed or
+// ctx is done.
+func (s *Stream) Consume(ctx context.Context, events <-chan interface{}, extract Extractor) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case msg, ok := <-events:
+			if !ok {
+				return nil
+			}
+			s.Add(extract(msg))
+		}
+	}
+}
+
+// Series returns the names of the series in the order they were first seen.
+func (s *Stream) Series() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.order...)
+}
+
+// Datasets returns a snapshot of each series as a Dataset labeled with the
+// series name. X values are milliseconds since the epoch, for a Time axis.
+func (s *Stream) Datasets() []Dataset {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ds := make([]Dataset, 0, len(s.order))
+	for _, name := range s.order {
+		r := s.series[name]
+		v := xyValues{xs: make([]float64, len(r.ts)), ys: append([]float64(nil), r.vs...)}
+		for i, t := range r.ts {
+			v.xs[i] = float64(t.UnixNano() / int64(time.Millisecond))
+		}
+		ds = append(ds, Dataset{Label: name, Data: v, XFloatFormat: "%.0f"})
+	}
+	return ds
+}
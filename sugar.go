@@ -1,9 +1,12 @@
 package chartjs
 
 import (
-	"encoding/json"
+	"bytes"
 	"html/template"
 	"io"
+	"strings"
+
+	"github.com/iszk1215/go-chartjs/types"
 )
 
 // this file implements some syntactic sugar for creating charts
@@ -17,35 +20,125 @@ var ChartJS = "https://cdnjs.cloudflare.com/ajax/libs/Chart.js/2.6.0/Chart.bundl
 const tmpl = `<!DOCTYPE html>
 <html>
     <head>
+		{{ with index . "title" }}<title>{{ . }}</title>{{ end }}
+		{{ with index . "ogImage" }}<meta property="og:image" content="{{ . }}">{{ end }}
+		<style>{{ index . "fontCSS" }}</style>
+		{{ block "head" . }}{{ end }}
 		<script src="{{ index . "JQuery" }}"></script>
 		<script src="{{ index . "ChartJS" }}"></script>
+		{{ range index . "scripts" }}
+		<script src="{{ . }}"></script>
+		{{ end }}
+		{{ block "scripts" . }}{{ end }}
 		<script>
 		{{ index . "extra"}}
 		</script>
     </head>
     <body>
-	{{ $height := index . "height" }}
-	{{ $width := index . "width" }}
-	{{ range $i, $json := index . "charts" }}
-	<canvas id="canvas{{ $i }}" style="height:{{ $height }}px;width:{{ $width }}px"></canvas>
-		<hr>
+	{{ index . "header" }}
+	{{ $grid := index . "grid" }}
+	<div class="chartjs-charts"{{ with $grid }} style="{{ . }}"{{ end }}>
+	{{ range index . "canvases" }}
+	{{ block "canvas" . }}{{ template "chartjs-canvas" . }}{{ end }}
+	{{ if not $grid }}<hr>{{ end }}
 	{{ end }}
+	</div>
 	{{ index . "customHTML" }}
     </body>
     <script>
-	Chart.defaults.line.cubicInterpolationMode = 'monotone';
-	Chart.defaults.global.animation.duration = 0;
+	{{ index . "helpers" }}
+	</script>
+    <script>
+	{{ index . "defaults" }}
 	var charts = []
-	{{ range $i, $json := index . "charts" }}
-		var ctx = document.getElementById("canvas{{ $i }}").getContext("2d");
-		var chart = new Chart(ctx, {{ $json }});
-		charts.push(chart)
+	{{ $lazy := index . "lazy" }}
+	{{ $errorURL := index . "errorURL" }}
+	{{ range $i, $c := index . "canvases" }}
+		charts.push(null)
+	{{ if not $c.Empty }}
+		{{ if $lazy }}chartjsLazy({{ $i }}, {{ else }}({{ end }}function() {
+		{{ if $c.Drill }}
+			chartjsDrillDown({{ $i }}, function() { return {{ $c.JSON }}; }, {{ $c.Drill.URL }}, {{ $c.Drill.RootLabel }});
+		{{ else }}
+			var ctx = document.getElementById("canvas{{ $i }}").getContext("2d");
+			{{ if $c.DataURL }}chartjsWorkerData({{ $c.DataURL }}, function(data) {
+				var cfg = {{ $c.JSON }};
+				cfg.data = data;{{ else }}(function() {
+				var cfg = {{ $c.JSON }};{{ end }}
+				var chart = {{ if $errorURL }}chartjsSelfCheck({{ $errorURL }}, {{ $i }}, cfg, function() { return new Chart(ctx, cfg); }){{ else }}new Chart(ctx, cfg){{ end }};
+				if (!chart) { return; }
+				charts[{{ $i }}] = chart
+				{{ if $c.Brush }}chartjsBrush(chart, {{ $c.Brush.URL }});{{ end }}
+				{{ if $c.HTMLLegend }}chartjsHTMLLegend(chart, {{ $i }});{{ end }}
+			}{{ if $c.DataURL }}){{ else }})(){{ end }};
+		{{ end }}
+		}){{ if not $lazy }}(){{ end }};
+	{{ end }}
 	{{ end }}
+	{{ index . "fontsReady" }}
 	{{ index . "custom" }}
+	{{ block "init" . }}{{ end }}
     </script>
-</html>`
+</html>
+{{ define "chartjs-canvas" }}
+	{{ if .Drill }}
+	<div id="crumbs{{ .Index }}" class="chartjs-breadcrumbs"></div>
+	{{ end }}
+	{{ if .Empty }}
+	<div id="canvas{{ .Index }}" class="chartjs-empty" style="height:{{ .Height }}px;width:{{ .Width }}px;display:flex;align-items:center;justify-content:center;color:#888;{{ .Style }}">{{ .Empty }}</div>
+	{{ else }}
+	<canvas id="canvas{{ .Index }}" style="height:{{ .Height }}px;width:{{ .Width }}px;{{ .Style }}"></canvas>
+	{{ if .HTMLLegend }}
+	<ul id="legend{{ .Index }}" class="chartjs-legend" aria-label="Legend" style="list-style:none;margin:0;padding:0;max-height:{{ .Height }}px;overflow-y:auto"></ul>
+	{{ end }}
+	{{ end }}
+{{ end }}`
+
+// pageTemplate is the parsed tmpl. It is only cloned, never executed, so
+// that clones can still be changed.
+var pageTemplate = template.Must(template.New("chartjs").Parse(tmpl))
+
+// HTMLTemplate returns a copy of the page template used by SaveCharts, to be
+// customized by redefining its blocks and passed back as
+// RenderOptions.HTMLTemplate:
+//
+//	"head"    extra markup in the head, e.g. style sheets
+//	"scripts" extra script tags, after chart.js and its plugins
+//	"canvas"  the markup of each chart, with the canvas as dot. Wrap the
+//	          default with {{ template "chartjs-canvas" . }}
+//	"init"    javascript run after the charts are built
+//
+// For example
+//
+//	t := chartjs.HTMLTemplate()
+//	template.Must(t.Parse(`{{ define "canvas" }}<div class="card">{{ template "chartjs-canvas" . }}</div>{{ end }}`))
+//
+// The blocks are empty by default, except "canvas".
+func HTMLTemplate() *template.Template {
+	return template.Must(pageTemplate.Clone())
+}
 
-// SaveCharts writes the charts and the required HTML to an io.Writer
+// canvas holds the per-chart values used by the template.
+type canvas struct {
+	JSON  template.JS
+	Style template.CSS
+	// Empty is the placeholder text for charts without data.
+	Empty string
+	Drill *DrillDown
+	Brush *Brush
+	// DataURL is where the data is fetched from, if not embedded.
+	DataURL string
+	// HTMLLegend adds the list of the legend, see Legend.HTML.
+	HTMLLegend bool
+	// Index is the position of the chart on the page, used in element IDs.
+	Index int
+	// Width and Height are the size of the canvas in pixels.
+	Width, Height interface{}
+}
+
+// SaveCharts writes the charts and the required HTML to an io.Writer. tmap
+// holds the template values of the page, as set by the fields of
+// RenderOptions; use RenderOptions.TMap for values of a custom template.
 func SaveCharts(w io.Writer, tmap map[string]interface{}, charts ...Chart) error {
 	if tmap == nil {
 		tmap = make(map[string]interface{})
@@ -58,24 +151,157 @@ func SaveCharts(w io.Writer, tmap map[string]interface{}, charts ...Chart) error
 		tmap["width"] = 400
 	}
 	jscharts := make([]template.JS, 0, len(charts))
+	canvases := make([]canvas, 0, len(charts))
+	// helpers holds the javascript needed by the features in use.
+	var helpers []string
+	addHelper := func(js string) {
+		for _, h := range helpers {
+			if h == js {
+				return
+			}
+		}
+		helpers = append(helpers, js)
+	}
+	// scripts holds the plugins needed by the charts.
+	var scripts []string
+	addScript := func(src string) {
+		for _, s := range scripts {
+			if s == src {
+				return
+			}
+		}
+		scripts = append(scripts, src)
+	}
+	if lazy, _ := tmap["lazy"].(bool); lazy {
+		addHelper(lazyJS)
+	}
+	if _, ok := tmap["errorURL"]; ok {
+		addHelper(selfCheckJS)
+	}
+	crossFilter, _ := tmap["crossFilter"].(bool)
+	if crossFilter {
+		addHelper(crossFilterJS)
+	}
 	for _, c := range charts {
-		cjson, err := json.Marshal(c)
+		c, err := c.applyMiddlewares()
 		if err != nil {
 			return err
 		}
-		jscharts = append(jscharts, template.JS(cjson))
+		c, style := c.applyAlerts()
+		empty := c.IsEmpty()
+		if c.DataURL != "" {
+			// the data is fetched by the page.
+			empty = false
+			addHelper(workerDataJS)
+		}
+		if crossFilter && c.Options.OnClick == "" {
+			c.Options.OnClick = "chartjsCrossFilter"
+		}
+		htmlLegend := c.Options.Legend != nil && c.Options.Legend.HTML
+		if htmlLegend {
+			// the list replaces the legend on the canvas.
+			l := *c.Options.Legend
+			l.Display = types.False
+			c.Options.Legend = &l
+			addHelper(htmlLegendJS)
+		}
+		var cjs bytes.Buffer
+		if c.DataURL != "" {
+			// prepare the full chart, as DataHandler does, and strip the data
+			// only after.
+			p, err := c.prepare()
+			if err != nil {
+				return err
+			}
+			p.Data = Data{}
+			b, err := p.marshal()
+			if err != nil {
+				return err
+			}
+			cjs.Write(inlineJS(b))
+		} else if err := (JSEncoder{}).Encode(&cjs, c); err != nil {
+			return err
+		}
+		jscharts = append(jscharts, template.JS(cjs.String()))
+		cv := canvas{
+			JSON:       jscharts[len(jscharts)-1],
+			Style:      template.CSS(style),
+			DataURL:    c.DataURL,
+			HTMLLegend: htmlLegend,
+			Index:      len(canvases),
+			Width:      tmap["width"],
+			Height:     tmap["height"],
+		}
+		if empty {
+			cv.Empty = c.emptyText()
+		}
+		if c.DrillDown != nil {
+			d := *c.DrillDown
+			if d.RootLabel == "" {
+				d.RootLabel = "All"
+			}
+			cv.Drill = &d
+			addHelper(drillDownJS)
+		}
+		plugins, err := c.resolvePlugins()
+		if err != nil {
+			return err
+		}
+		for _, p := range plugins {
+			if p.Src != "" {
+				addScript(p.Src)
+			}
+			if p.Register != "" {
+				addHelper(p.Register)
+			}
+		}
+		if c.Brush != nil {
+			cv.Brush = c.Brush
+			addHelper(brushJS)
+		}
+		canvases = append(canvases, cv)
 	}
 	for k, v := range tmap {
 		if chart, ok := v.(Chart); ok {
-			cjson, err := json.Marshal(chart)
-			if err != nil {
+			var cjs bytes.Buffer
+			if err := (JSEncoder{}).Encode(&cjs, chart); err != nil {
 				return err
 			}
-			tmap[k] = template.JS(cjson)
+			tmap[k] = template.JS(cjs.String())
 		}
 	}
 
+	defaults, ok := tmap["defaults"].(GlobalDefaults)
+	if !ok {
+		defaults = Defaults
+	}
+	fonts, _ := tmap["fonts"].([]WebFont)
+	css, err := fontCSS(fonts)
+	if err != nil {
+		return err
+	}
+	tmap["fontCSS"] = template.CSS(css)
+	tmap["fontsReady"] = template.JS("")
+	if len(fonts) > 0 {
+		tmap["fontsReady"] = template.JS(fontsReadyJS)
+		if defaults.FontFamily == "" {
+			defaults.FontFamily = fonts[0].Family
+		}
+	}
+	version, err := pageVersion(charts)
+	if err != nil {
+		return err
+	}
+	djs, err := defaults.js(version)
+	if err != nil {
+		return err
+	}
+	tmap["defaults"] = template.JS(djs)
+
 	tmap["charts"] = jscharts
+	tmap["canvases"] = canvases
+	tmap["scripts"] = scripts
+	tmap["helpers"] = template.JS(strings.Join(helpers, "\n"))
 	if _, ok := tmap["JQuery"]; !ok {
 		tmap["JQuery"] = JQuery
 	}
@@ -88,17 +314,102 @@ func SaveCharts(w io.Writer, tmap map[string]interface{}, charts ...Chart) error
 	if _, ok := tmap["customHTML"]; !ok {
 		tmap["customHTML"] = ""
 	}
-	if _, ok := tmap["template"]; !ok {
-		tmap["template"] = tmpl
+	if _, ok := tmap["header"]; !ok {
+		tmap["header"] = ""
 	}
-	t, err := template.New("chartjs").Parse(tmap["template"].(string))
-	if err != nil {
-		return err
+	t, ok := tmap["htmlTemplate"].(*template.Template)
+	if !ok {
+		if s, ok := tmap["template"].(string); ok {
+			if t, err = template.New("chartjs").Parse(s); err != nil {
+				return err
+			}
+		} else {
+			t = HTMLTemplate()
+		}
 	}
 	return t.Execute(w, tmap)
 }
 
-// SaveHTML writes the chart and minimal HTML to an io.Writer.
-func (c Chart) SaveHTML(w io.Writer, tmap map[string]interface{}) error {
-	return SaveCharts(w, tmap, c)
+// RenderOptions configures the page written by SaveHTML. Zero values use the
+// defaults of SaveCharts.
+type RenderOptions struct {
+	// Title is the title of the page.
+	Title string
+	// Width and Height size the canvas in pixels, 400 by default.
+	Width, Height int
+	// ChartJS and JQuery override the script URLs of the package variables.
+	ChartJS, JQuery string
+	// Header is shown above the chart.
+	Header template.HTML
+	// Extra is javascript run before the chart is built and Custom after.
+	Extra, Custom template.JS
+	// CustomHTML is added after the chart.
+	CustomHTML template.HTML
+	// Template replaces the page template.
+	Template string
+	// HTMLTemplate replaces the page template with a customized copy of
+	// HTMLTemplate, and takes precedence over Template.
+	HTMLTemplate *template.Template
+
+	// Lazy defers building each chart until its canvas scrolls into view,
+	// so entries of the javascript charts array stay null until then.
+	Lazy bool
+	// ErrorURL makes the page post charts that fail to build to an
+	// ErrorReportHandler at that URL.
+	ErrorURL string
+	// OGImage is the Open Graph preview image of the page, see
+	// OGImageHandler.
+	OGImage string
+	// Defaults overrides the package Defaults.
+	Defaults *GlobalDefaults
+	// Fonts are the web fonts loaded before the charts are built.
+	Fonts []WebFont
+	// CrossFilter makes clicking a category in one chart filter the
+	// datasets of the other charts to that category.
+	CrossFilter bool
+	// Grid lays the charts out with the CSS of their container instead of
+	// one below the other, see Page.
+	Grid template.CSS
+
+	// TMap holds further template values, as passed to SaveCharts.
+	TMap map[string]interface{}
+}
+
+// tmap returns the options as the template values of SaveCharts.
+func (o RenderOptions) tmap() map[string]interface{} {
+	m := make(map[string]interface{}, len(o.TMap)+16)
+	for k, v := range o.TMap {
+		m[k] = v
+	}
+	set := func(k string, v interface{}, ok bool) {
+		if ok {
+			m[k] = v
+		}
+	}
+	set("title", o.Title, o.Title != "")
+	set("width", o.Width, o.Width > 0)
+	set("height", o.Height, o.Height > 0)
+	set("ChartJS", o.ChartJS, o.ChartJS != "")
+	set("JQuery", o.JQuery, o.JQuery != "")
+	set("header", o.Header, o.Header != "")
+	set("extra", o.Extra, o.Extra != "")
+	set("custom", o.Custom, o.Custom != "")
+	set("customHTML", o.CustomHTML, o.CustomHTML != "")
+	set("template", o.Template, o.Template != "")
+	set("htmlTemplate", o.HTMLTemplate, o.HTMLTemplate != nil)
+	set("lazy", o.Lazy, o.Lazy)
+	set("errorURL", o.ErrorURL, o.ErrorURL != "")
+	set("ogImage", o.OGImage, o.OGImage != "")
+	if o.Defaults != nil {
+		m["defaults"] = *o.Defaults
+	}
+	set("fonts", o.Fonts, o.Fonts != nil)
+	set("crossFilter", o.CrossFilter, o.CrossFilter)
+	set("grid", o.Grid, o.Grid != "")
+	return m
+}
+
+// SaveHTML writes a standalone HTML page showing the chart to an io.Writer.
+func (c Chart) SaveHTML(w io.Writer, opts RenderOptions) error {
+	return SaveCharts(w, opts.tmap(), c)
 }
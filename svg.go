@@ -0,0 +1,222 @@
+package chartjs
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"strconv"
+)
+
+// SVGWidth and SVGHeight are the size in pixels of the images written by
+// RenderSVG.
+var (
+	SVGWidth  = 640
+	SVGHeight = 400
+)
+
+// svgPlot maps data coordinates into the plot area of an SVG image.
+type svgPlot struct {
+	left, top, right, bottom float64
+	xmin, xmax, ymin, ymax   float64
+}
+
+func (p svgPlot) px(x, y float64) (float64, float64) {
+	return p.left + (p.right-p.left)*(x-p.xmin)/(p.xmax-p.xmin),
+		p.bottom - (p.bottom-p.top)*(y-p.ymin)/(p.ymax-p.ymin)
+}
+
+// maxTicks bounds the ticks of an axis, whose step can be lost in rounding
+// at large magnitudes.
+const maxTicks = 100
+
+// niceTicks returns about n round values spanning lo to hi, stepping by 1, 2
+// or 5 times a power of ten.
+func niceTicks(lo, hi float64, n int) []float64 {
+	raw := (hi - lo) / float64(n)
+	if !(raw > 0) || math.IsInf(raw, 0) {
+		return nil
+	}
+	mag := math.Pow(10, math.Floor(math.Log10(raw)))
+	step := mag * 10
+	for _, m := range []float64{1, 2, 5} {
+		if m*mag >= raw {
+			step = m * mag
+			break
+		}
+	}
+	first, last := math.Ceil(lo/step), math.Floor(hi/step+1e-9)
+	if !(last-first < maxTicks) {
+		return nil
+	}
+	var ticks []float64
+	for k := 0; k <= int(last-first); k++ {
+		// avoid printing -0.
+		ticks = append(ticks, (first+float64(k))*step+0)
+	}
+	return ticks
+}
+
+func formatTick(v float64) string {
+	return strconv.FormatFloat(v, 'g', 6, 64)
+}
+
+// RenderSVG writes the chart as an SVG image of SVGWidth by SVGHeight, with
+// axes, the title and a legend, so that it can be embedded in documents and
+// emails without javascript. Only Line, Bar and Scatter charts of Values are
+// supported.
+func (c Chart) RenderSVG(w io.Writer) error {
+	switch c.Type.or(Line) {
+	case Line, Bar, Scatter:
+	default:
+		return fmt.Errorf("chart: RenderSVG does not support %s charts", c.Type)
+	}
+	if c.Redactor != nil {
+		data, err := c.Data.redact(c.Redactor)
+		if err != nil {
+			return err
+		}
+		c.Data = data
+	}
+	ss := c.series()
+	width, height := float64(SVGWidth), float64(SVGHeight)
+	p := svgPlot{left: 56, top: 16, right: width - 16, bottom: height - 32}
+	var title string
+	if t := c.Options.Title; t != nil && (t.Display == nil || *t.Display) {
+		title = t.Text
+		p.top += 24
+	}
+	legend := false
+	for _, s := range ss {
+		if s.label != "" {
+			legend = c.Options.Legend == nil || c.Options.Legend.Display == nil || *c.Options.Legend.Display
+		}
+	}
+	if legend {
+		p.top += 20
+	}
+
+	p.xmin, p.xmax, p.ymin, p.ymax = bounds(ss)
+	if math.IsInf(p.xmin, 0) {
+		// no points.
+		p.xmin, p.xmax, p.ymin, p.ymax = 0, 1, 0, 1
+	}
+	nbars := 0
+	for _, s := range ss {
+		if s.bar {
+			nbars++
+		}
+	}
+	// categories are centered in bands of width 1.
+	categories := len(c.Data.Labels) > 0 && c.Type != Scatter
+	if categories {
+		p.xmin, p.xmax = -0.5, float64(len(c.Data.Labels))-0.5
+	} else if nbars > 0 {
+		pad := (p.xmax - p.xmin) / 20
+		p.xmin, p.xmax = p.xmin-pad, p.xmax+pad
+	}
+	yticks := niceTicks(p.ymin, p.ymax, 5)
+	if len(yticks) > 0 {
+		step := (p.ymax - p.ymin) / 5
+		if len(yticks) > 1 {
+			step = yticks[1] - yticks[0]
+		}
+		p.ymin = math.Min(p.ymin, yticks[0])
+		p.ymax = math.Max(p.ymax, yticks[len(yticks)-1])
+		if p.ymax > yticks[len(yticks)-1] {
+			p.ymax = yticks[len(yticks)-1] + step
+			yticks = append(yticks, p.ymax)
+		}
+		if p.ymin < yticks[0] {
+			p.ymin = yticks[0] - step
+			yticks = append([]float64{p.ymin}, yticks...)
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%g" height="%g" viewBox="0 0 %g %g" font-family="sans-serif" font-size="12">`, width, height, width, height)
+	fmt.Fprintf(bw, `<rect width="100%%" height="100%%" fill="white"/>`)
+	if title != "" {
+		fmt.Fprintf(bw, `<text x="%g" y="24" text-anchor="middle" font-size="16" font-weight="bold">%s</text>`, width/2, html.EscapeString(title))
+	}
+	if legend {
+		x, y := p.left, p.top-12
+		for _, s := range ss {
+			if s.label == "" {
+				continue
+			}
+			fmt.Fprintf(bw, `<rect x="%.1f" y="%.1f" width="12" height="12" fill="%s"/>`, x, y-10, svgColor(s))
+			fmt.Fprintf(bw, `<text x="%.1f" y="%.1f">%s</text>`, x+16, y, html.EscapeString(s.label))
+			x += 32 + 7*float64(len([]rune(s.label)))
+		}
+	}
+
+	// grid lines and ticks.
+	for _, t := range yticks {
+		_, y := p.px(0, t)
+		fmt.Fprintf(bw, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="#e5e5e5"/>`, p.left, y, p.right, y)
+		fmt.Fprintf(bw, `<text x="%.1f" y="%.1f" text-anchor="end" fill="#666">%s</text>`, p.left-6, y+4, formatTick(t))
+	}
+	if categories {
+		for i, l := range c.Data.Labels {
+			x, _ := p.px(float64(i), 0)
+			fmt.Fprintf(bw, `<text x="%.1f" y="%.1f" text-anchor="middle" fill="#666">%s</text>`, x, p.bottom+16, html.EscapeString(l))
+		}
+	} else {
+		for _, t := range niceTicks(p.xmin, p.xmax, 8) {
+			x, _ := p.px(t, 0)
+			fmt.Fprintf(bw, `<text x="%.1f" y="%.1f" text-anchor="middle" fill="#666">%s</text>`, x, p.bottom+16, formatTick(t))
+		}
+	}
+	fmt.Fprintf(bw, `<polyline fill="none" stroke="#999" points="%.1f,%.1f %.1f,%.1f %.1f,%.1f"/>`, p.left, p.top, p.left, p.bottom, p.right, p.bottom)
+
+	// bars are grouped side by side within a band.
+	band := (p.right - p.left) / (p.xmax - p.xmin) * 0.8
+	if !categories {
+		band = (p.right - p.left) / float64(maxLen(ss)+1) * 0.8
+	}
+	bar := 0
+	for _, s := range ss {
+		col := svgColor(s)
+		switch {
+		case s.bar:
+			_, y0 := p.px(0, math.Max(p.ymin, math.Min(0, p.ymax)))
+			width := band / float64(nbars)
+			for i := range s.xs {
+				x, y := p.px(s.xs[i], s.ys[i])
+				x += -band/2 + float64(bar)*width
+				fmt.Fprintf(bw, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"/>`, x, math.Min(y, y0), width, math.Abs(y-y0), col)
+			}
+			bar++
+		case s.dots:
+			for i := range s.xs {
+				x, y := p.px(s.xs[i], s.ys[i])
+				fmt.Fprintf(bw, `<circle cx="%.1f" cy="%.1f" r="3" fill="%s"/>`, x, y, col)
+			}
+		default:
+			fmt.Fprintf(bw, `<polyline fill="none" stroke="%s" stroke-width="2" points="`, col)
+			for i := range s.xs {
+				x, y := p.px(s.xs[i], s.ys[i])
+				fmt.Fprintf(bw, "%.1f,%.1f ", x, y)
+			}
+			io.WriteString(bw, `"/>`)
+		}
+	}
+	io.WriteString(bw, "</svg>")
+	return bw.Flush()
+}
+
+func svgColor(s series) string {
+	return fmt.Sprintf("rgb(%d,%d,%d)", s.color.R, s.color.G, s.color.B)
+}
+
+func maxLen(ss []series) int {
+	n := 0
+	for _, s := range ss {
+		if len(s.xs) > n {
+			n = len(s.xs)
+		}
+	}
+	return n
+}
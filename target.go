@@ -0,0 +1,90 @@
+package chartjs
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/iszk1215/go-chartjs/types"
+)
+
+// Target is a goal or budget for the actual values of a chart, per category.
+type Target struct {
+	// Label names the target in the legend, "target" by default.
+	Label string
+	// Values holds the target of each label, or a single value for all.
+	Values []float64
+	// Color is the color of the target line, dark gray by default.
+	Color *types.RGBA
+	// Over and Under color the actual values above and at or below their
+	// target, red and green by default as for spending against a budget.
+	// Swap them for goals such as revenue.
+	Over, Under *types.RGBA
+}
+
+// AddTarget overlays the target on the chart as a dashed, stepped line and
+// colors each value of the existing datasets by whether it is over or under
+// its target. Values without a target keep their color.
+func (c *Chart) AddTarget(t Target) error {
+	n := len(c.Data.Labels)
+	targets := t.Values
+	switch {
+	case len(targets) == 1 && n > 1:
+		targets = make([]float64, n)
+		for i := range targets {
+			targets[i] = t.Values[0]
+		}
+	case len(targets) != n:
+		return fmt.Errorf("chart: got %d targets for %d labels", len(targets), n)
+	}
+	over, under := t.Over, t.Under
+	if over == nil {
+		over = &DefaultPalette[2]
+	}
+	if under == nil {
+		under = &DefaultPalette[4]
+	}
+
+	for i, d := range c.Data.Datasets {
+		v, ok := d.Data.(Values)
+		if !ok {
+			return fmt.Errorf("chart: dataset %q does not hold Values", d.Label)
+		}
+		base := DefaultPalette[i%len(DefaultPalette)]
+		if d.BackgroundColor != nil {
+			base = *d.BackgroundColor
+		}
+		colors := make([]types.RGBA, len(plotted(v)))
+		for j, y := range plotted(v) {
+			switch {
+			case j >= n || math.IsNaN(y) || math.IsNaN(targets[j]):
+				colors[j] = base
+			case y > targets[j]:
+				colors[j] = *over
+			default:
+				colors[j] = *under
+			}
+		}
+		c.Data.Datasets[i].BackgroundColors = colors
+	}
+
+	label := t.Label
+	if label == "" {
+		label = "target"
+	}
+	color := t.Color
+	if color == nil {
+		color = &types.RGBA{R: 64, G: 64, B: 64, A: 255}
+	}
+	c.AddDataset(Dataset{
+		Type:        Line,
+		SteppedLine: True,
+		Label:       label,
+		Data:        xyValues{xs: targets},
+		BorderColor: color,
+		BorderWidth: 2,
+		BorderDash:  []float64{6, 4},
+		Fill:        False,
+		Order:       -1,
+	})
+	return nil
+}
@@ -0,0 +1,287 @@
+package chartjs
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+
+	"github.com/iszk1215/go-chartjs/types"
+)
+
+// series is a dataset flattened to points for drawing in Go.
+type series struct {
+	xs, ys []float64
+	color  types.RGBA
+	bar    bool
+	label  string
+	// dots is set for scatter series drawn without lines.
+	dots bool
+}
+
+// series returns the Values datasets of the chart, with x set to the index
+// for datasets without x values. NaN and infinite points are dropped.
+func (c Chart) series() []series {
+	var out []series
+	for i, d := range c.Data.Datasets {
+		v, ok := d.Data.(Values)
+		if !ok {
+			continue
+		}
+		t := d.Type.or(c.Type)
+		s := series{color: DefaultPalette[i%len(DefaultPalette)], bar: t == Bar, label: d.Label}
+		s.dots = t == Scatter && (d.ShowLine == nil || !*d.ShowLine)
+		if d.BorderColor != nil {
+			s.color = *d.BorderColor
+		} else if d.BackgroundColor != nil {
+			s.color = *d.BackgroundColor
+		}
+		xs, ys := v.Xs(), v.Ys()
+		if len(ys) == 0 {
+			xs, ys = nil, plotted(v)
+		}
+		for j, y := range ys {
+			x := float64(j)
+			if j < len(xs) {
+				x = xs[j]
+			}
+			if !finite(x) || !finite(y) {
+				continue
+			}
+			s.xs = append(s.xs, x)
+			s.ys = append(s.ys, y)
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+// decimate keeps the min and max of each of n buckets along x, which
+// preserves the shape of a line drawn n pixels wide.
+func decimate(xs, ys []float64, n int) ([]float64, []float64) {
+	if n <= 0 || len(xs) <= 2*n {
+		return xs, ys
+	}
+	lo, hi := xs[0], xs[len(xs)-1]
+	if hi <= lo {
+		return xs, ys
+	}
+	var dx, dy []float64
+	for start := 0; start < len(xs); {
+		b := int(float64(n-1) * (xs[start] - lo) / (hi - lo))
+		end, imin, imax := start, start, start
+		for ; end < len(xs) && int(float64(n-1)*(xs[end]-lo)/(hi-lo)) == b; end++ {
+			if ys[end] < ys[imin] {
+				imin = end
+			}
+			if ys[end] > ys[imax] {
+				imax = end
+			}
+		}
+		if end == start {
+			end++
+		}
+		if imin > imax {
+			imin, imax = imax, imin
+		}
+		dx, dy = append(dx, xs[imin]), append(dy, ys[imin])
+		if imax != imin {
+			dx, dy = append(dx, xs[imax]), append(dy, ys[imax])
+		}
+		start = end
+	}
+	return dx, dy
+}
+
+// bounds returns the data range of all series, including 0 on y for bars.
+// Points not finite are skipped.
+func bounds(ss []series) (xmin, xmax, ymin, ymax float64) {
+	xmin, ymin = math.Inf(1), math.Inf(1)
+	xmax, ymax = math.Inf(-1), math.Inf(-1)
+	for _, s := range ss {
+		for i := range s.xs {
+			if !finite(s.xs[i]) || !finite(s.ys[i]) {
+				continue
+			}
+			xmin, xmax = math.Min(xmin, s.xs[i]), math.Max(xmax, s.xs[i])
+			ymin, ymax = math.Min(ymin, s.ys[i]), math.Max(ymax, s.ys[i])
+		}
+		if s.bar {
+			ymin, ymax = math.Min(ymin, 0), math.Max(ymax, 0)
+		}
+	}
+	if xmin == xmax {
+		xmin, xmax = xmin-1, xmax+1
+	}
+	if ymin == ymax {
+		ymin, ymax = ymin-1, ymax+1
+	}
+	return
+}
+
+// thumb holds the decimated series of a chart scaled to a width x height box.
+type thumb struct {
+	ss            []series
+	width, height int
+	xmin, xmax    float64
+	ymin, ymax    float64
+}
+
+// thumb returns the thumbnail of the chart with its middlewares and redactor
+// applied, as the chart is marshaled.
+func (c Chart) thumb(width, height int) (*thumb, error) {
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("chart: bad thumbnail size %dx%d", width, height)
+	}
+	c, err := c.applyMiddlewares()
+	if err != nil {
+		return nil, err
+	}
+	if c.Redactor != nil {
+		data, err := c.Data.redact(c.Redactor)
+		if err != nil {
+			return nil, err
+		}
+		c.Data = data
+	}
+	ss := c.series()
+	for i, s := range ss {
+		// decimate needs x sorted, which points may not be.
+		if !s.bar && !s.dots {
+			ss[i].xs, ss[i].ys = decimate(s.xs, s.ys, width)
+		}
+	}
+	t := &thumb{ss: ss, width: width, height: height}
+	t.xmin, t.xmax, t.ymin, t.ymax = bounds(ss)
+	return t, nil
+}
+
+// px maps data coordinates into the box, with a 1 pixel margin.
+func (t *thumb) px(x, y float64) (float64, float64) {
+	w, h := float64(t.width-3), float64(t.height-3)
+	return 1 + w*(x-t.xmin)/(t.xmax-t.xmin), 1 + h*(1-(y-t.ymin)/(t.ymax-t.ymin))
+}
+
+// barWidth is the width in pixels of each bar of s.
+func (t *thumb) barWidth(s series) float64 {
+	return math.Max(1, 0.8*float64(t.width-2)/float64(len(s.xs)+1))
+}
+
+// Thumbnail writes a small PNG preview of the chart, without axes or labels,
+// for chart listings and link previews.
+func (c Chart) Thumbnail(w io.Writer, width, height int) error {
+	t, err := c.thumb(width, height)
+	if err != nil {
+		return err
+	}
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for i := range img.Pix {
+		img.Pix[i] = 255
+	}
+	for _, s := range t.ss {
+		col := color.NRGBA{R: s.color.R, G: s.color.G, B: s.color.B, A: 255}
+		if s.bar {
+			_, y0 := t.px(0, math.Max(t.ymin, math.Min(0, t.ymax)))
+			bw := t.barWidth(s)
+			for i := range s.xs {
+				x, y := t.px(s.xs[i], s.ys[i])
+				fillRect(img, int(x-bw/2), int(math.Min(y, y0)), int(x+bw/2), int(math.Max(y, y0)), col)
+			}
+			continue
+		}
+		if s.dots {
+			for i := range s.xs {
+				x, y := t.px(s.xs[i], s.ys[i])
+				fillRect(img, int(x+0.5)-1, int(y+0.5)-1, int(x+0.5)+1, int(y+0.5)+1, col)
+			}
+			continue
+		}
+		for i := 1; i < len(s.xs); i++ {
+			x0, y0 := t.px(s.xs[i-1], s.ys[i-1])
+			x1, y1 := t.px(s.xs[i], s.ys[i])
+			drawLine(img, int(x0+0.5), int(y0+0.5), int(x1+0.5), int(y1+0.5), col)
+		}
+	}
+	return png.Encode(w, img)
+}
+
+// ThumbnailSVG writes the same preview as Thumbnail as SVG.
+func (c Chart) ThumbnailSVG(w io.Writer, width, height int) error {
+	t, err := c.thumb(width, height)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`, width, height, width, height); err != nil {
+		return err
+	}
+	for _, s := range t.ss {
+		col := fmt.Sprintf("rgb(%d,%d,%d)", s.color.R, s.color.G, s.color.B)
+		if s.bar {
+			_, y0 := t.px(0, math.Max(t.ymin, math.Min(0, t.ymax)))
+			bw := t.barWidth(s)
+			for i := range s.xs {
+				x, y := t.px(s.xs[i], s.ys[i])
+				fmt.Fprintf(w, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"/>`, x-bw/2, math.Min(y, y0), bw, math.Abs(y-y0), col)
+			}
+			continue
+		}
+		if s.dots {
+			for i := range s.xs {
+				x, y := t.px(s.xs[i], s.ys[i])
+				fmt.Fprintf(w, `<circle cx="%.1f" cy="%.1f" r="1.5" fill="%s"/>`, x, y, col)
+			}
+			continue
+		}
+		fmt.Fprintf(w, `<polyline fill="none" stroke="%s" points="`, col)
+		for i := range s.xs {
+			x, y := t.px(s.xs[i], s.ys[i])
+			fmt.Fprintf(w, "%.1f,%.1f ", x, y)
+		}
+		io.WriteString(w, `"/>`)
+	}
+	_, err = io.WriteString(w, "</svg>")
+	return err
+}
+
+func fillRect(img *image.RGBA, x0, y0, x1, y1 int, c color.Color) {
+	for y := y0; y <= y1; y++ {
+		for x := x0; x <= x1; x++ {
+			img.Set(x, y, c)
+		}
+	}
+}
+
+// drawLine draws a 1 pixel line with Bresenham's algorithm.
+func drawLine(img *image.RGBA, x0, y0, x1, y1 int, c color.Color) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	e := dx + dy
+	for {
+		img.Set(x0, y0, c)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		if e2 := 2 * e; e2 >= dy {
+			e += dy
+			x0 += sx
+		} else {
+			e += dx
+			y0 += sy
+		}
+	}
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
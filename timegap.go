@@ -0,0 +1,85 @@
+package chartjs
+
+import (
+	"fmt"
+	"time"
+)
+
+// TimeRange is a half-open range of time [Start, End).
+type TimeRange struct {
+	Start, End time.Time
+}
+
+// Contains reports whether t falls in the range.
+func (r TimeRange) Contains(t time.Time) bool {
+	return !t.Before(r.Start) && t.Before(r.End)
+}
+
+// TimeGaps describes time ranges to leave out of an x axis, e.g. nights and
+// weekends for market data, so they don't show up as long flat lines.
+type TimeGaps struct {
+	// Ranges are fixed ranges to skip.
+	Ranges []TimeRange
+	// Skip reports recurring gaps, e.g. anything outside business hours.
+	Skip func(t time.Time) bool
+	// Calendar skips the days off, e.g. weekends and holidays.
+	Calendar Calendar
+	// Layout formats the tick labels. Defaults to "2006-01-02 15:04".
+	Layout string
+}
+
+// Contains reports whether t falls in any of the gaps.
+func (g TimeGaps) Contains(t time.Time) bool {
+	if g.Skip != nil && g.Skip(t) {
+		return true
+	}
+	if g.Calendar != nil && !g.Calendar.IsBusinessDay(t) {
+		return true
+	}
+	for _, r := range g.Ranges {
+		if r.Contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// Compress drops the points that fall in the gaps and returns the rest as
+// values on an index scale together with their tick labels. Use the labels as
+// Data.Labels with a Category x axis so consecutive points sit next to each
+// other regardless of the time between them. ts and ys must be of the same
+// length.
+func (g TimeGaps) Compress(ts []time.Time, ys []float64) (Values, []string, error) {
+	if len(ts) != len(ys) {
+		return nil, nil, fmt.Errorf("chart: bad format of Values. Times and Y must be of the same length")
+	}
+	layout := g.Layout
+	if layout == "" {
+		layout = "2006-01-02 15:04"
+	}
+	var v xyValues
+	var labels []string
+	for i, t := range ts {
+		if g.Contains(t) {
+			continue
+		}
+		v.ys = append(v.ys, ys[i])
+		labels = append(labels, t.Format(layout))
+	}
+	return v, labels, nil
+}
+
+// Weekends is a TimeGaps.Skip func that skips Saturdays and Sundays.
+func Weekends(t time.Time) bool {
+	wd := t.Weekday()
+	return wd == time.Saturday || wd == time.Sunday
+}
+
+// OutsideHours returns a TimeGaps.Skip func that skips times outside
+// [open, close) hours of the day in t's location.
+func OutsideHours(open, close int) func(time.Time) bool {
+	return func(t time.Time) bool {
+		h := t.Hour()
+		return h < open || h >= close
+	}
+}
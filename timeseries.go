@@ -0,0 +1,131 @@
+package chartjs
+
+import (
+	"fmt"
+	"time"
+)
+
+// TimeValues are Values whose x values are times, for charts with a Time
+// x-axis. Xs must return the times in epoch milliseconds.
+type TimeValues interface {
+	Values
+	Times() []time.Time
+}
+
+// TimeSeries is a TimeValues of values at the given times.
+type TimeSeries struct {
+	Time  []time.Time
+	Value []float64
+}
+
+// Times implements TimeValues.
+func (s TimeSeries) Times() []time.Time { return s.Time }
+
+// Xs returns the times in epoch milliseconds.
+func (s TimeSeries) Xs() []float64 {
+	xs := make([]float64, len(s.Time))
+	for i, t := range s.Time {
+		xs[i] = float64(t.UnixNano() / int64(time.Millisecond))
+	}
+	return xs
+}
+
+// Ys returns the values.
+func (s TimeSeries) Ys() []float64 { return s.Value }
+
+// Rs returns nil.
+func (s TimeSeries) Rs() []float64 { return nil }
+
+// writeTimeValuesJSON writes {x, y} points with x in epoch milliseconds, or
+// formatted with the time layout in loc when it is set, e.g. time.RFC3339.
+func writeTimeValuesJSON(buf jsonWriter, v TimeValues, layout string, loc *time.Location, yformat string) error {
+	ts, ys := v.Times(), v.Ys()
+	if len(ts) != len(ys) {
+		return fmt.Errorf("chart: bad format of Values. Times and Y must be of the same length")
+	}
+	yf := parseFloatFormat(yformat)
+	buf.WriteByte('[')
+	for i, t := range ts {
+		if err := writeScratch(buf, func(b []byte) ([]byte, error) {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			b = appendTime(append(b, `{"x":`...), t, layout, loc)
+			b, err := yf.append(append(b, `,"y":`...), ys[i])
+			return append(b, '}'), err
+		}); err != nil {
+			return err
+		}
+	}
+	return buf.WriteByte(']')
+}
+
+type timeUnit int
+
+const (
+	UnitMillisecond timeUnit = iota + 1
+	UnitSecond
+	UnitMinute
+	UnitHour
+	UnitDay
+	UnitWeek
+	UnitMonth
+	UnitQuarter
+	UnitYear
+)
+
+var timeUnits = []string{
+	"",
+	"millisecond",
+	"second",
+	"minute",
+	"hour",
+	"day",
+	"week",
+	"month",
+	"quarter",
+	"year",
+}
+
+func (u timeUnit) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + timeUnits[u] + `"`), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface.
+func (u *timeUnit) UnmarshalJSON(b []byte) error {
+	i, err := unmarshalEnum(b, timeUnits, "time unit")
+	*u = timeUnit(i)
+	return err
+}
+
+// TimeOptions are the "time" options of a Time axis. Formats are those of the
+// date library of chart.js, moment.js for version 2.
+type TimeOptions struct {
+	// Unit fixes the unit of the ticks, which is picked from the data if unset.
+	Unit timeUnit `json:"unit,omitempty"`
+	// DisplayFormats are the tick formats by unit, e.g. {"hour": "HH:mm"}.
+	DisplayFormats map[string]string `json:"displayFormats,omitempty"`
+	// Parser is the format of string x values, see Dataset.TimeFormat.
+	Parser string `json:"parser,omitempty"`
+	// TooltipFormat is the format of times in tooltips.
+	TooltipFormat string `json:"tooltipFormat,omitempty"`
+}
+
+// Adapters configure the date library of a Time axis.
+type Adapters struct {
+	Date DateAdapter `json:"date"`
+}
+
+// DateAdapter holds the options of the date adapter.
+type DateAdapter struct {
+	// Zone is the IANA time zone ticks are shown in, e.g. "Europe/Berlin",
+	// instead of that of the browser. The chart then requires the luxon date
+	// adapter, Plugins["date-adapter-luxon"].
+	Zone string `json:"zone,omitempty"`
+}
+
+// ZoneAdapters returns the Adapters showing a Time axis in loc, to align it
+// with data bucketed by TimeBuckets in loc.
+func ZoneAdapters(loc *time.Location) *Adapters {
+	return &Adapters{Date: DateAdapter{Zone: loc.String()}}
+}
@@ -0,0 +1,61 @@
+package chartjs
+
+import "strconv"
+
+// TreeNode is a node of a Treemap. The Value of a node with Children is the
+// sum of theirs and Value is ignored.
+type TreeNode struct {
+	Label    string
+	Value    float64
+	Children []TreeNode
+}
+
+func (n TreeNode) depth() int {
+	d := 0
+	for _, c := range n.Children {
+		if cd := c.depth(); cd > d {
+			d = cd
+		}
+	}
+	return d + 1
+}
+
+// NewTreemapDataset flattens the trees into one object per leaf, holding
+// the labels of the leaf and its ancestors as "l0", "l1", ... and its value
+// as "value", and groups them by level.
+func NewTreemapDataset(label string, nodes []TreeNode) Dataset {
+	depth := 0
+	for _, n := range nodes {
+		if d := n.depth(); d > depth {
+			depth = d
+		}
+	}
+	d := Dataset{Type: Treemap, Label: label, Key: "value", Tree: []map[string]interface{}{}}
+	for i := 0; i < depth; i++ {
+		d.Groups = append(d.Groups, "l"+strconv.Itoa(i))
+	}
+	var walk func(n TreeNode, path []string)
+	walk = func(n TreeNode, path []string) {
+		path = append(path[:len(path):len(path)], n.Label)
+		if len(n.Children) > 0 {
+			for _, c := range n.Children {
+				walk(c, path)
+			}
+			return
+		}
+		leaf := map[string]interface{}{"value": n.Value}
+		for i := 0; i < depth; i++ {
+			// leaves above the deepest level repeat their label below.
+			l := path[len(path)-1]
+			if i < len(path) {
+				l = path[i]
+			}
+			leaf["l"+strconv.Itoa(i)] = l
+		}
+		d.Tree = append(d.Tree, leaf)
+	}
+	for _, n := range nodes {
+		walk(n, nil)
+	}
+	return d
+}
@@ -0,0 +1,83 @@
+package types
+
+import "math"
+
+// Colormap maps a value from 0 to 1 to a color, e.g. for heatmaps or for
+// coloring points by value. Values outside [0, 1] are clamped and NaN is
+// transparent.
+type Colormap func(t float64) RGBA
+
+func hex(v uint32) RGBA {
+	return RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 255}
+}
+
+var (
+	viridisStops = []RGBA{
+		hex(0x440154), hex(0x482475), hex(0x414487), hex(0x355f8d), hex(0x2a788e), hex(0x21918c),
+		hex(0x22a884), hex(0x44bf70), hex(0x7ad151), hex(0xbddf26), hex(0xfde725),
+	}
+	plasmaStops = []RGBA{
+		hex(0x0d0887), hex(0x41049d), hex(0x6a00a8), hex(0x8f0da4), hex(0xb12a90), hex(0xcc4778),
+		hex(0xe16462), hex(0xf2844b), hex(0xfca636), hex(0xfcce25), hex(0xf0f921),
+	}
+	rdBuStops = []RGBA{
+		hex(0x67001f), hex(0xb2182b), hex(0xd6604d), hex(0xf4a582), hex(0xfddbc7), hex(0xf7f7f7),
+		hex(0xd1e5f0), hex(0x92c5de), hex(0x4393c3), hex(0x2166ac), hex(0x053061),
+	}
+)
+
+// Viridis is matplotlib's perceptually uniform default colormap.
+func Viridis(t float64) RGBA {
+	return Interpolate(viridisStops, t)
+}
+
+// Plasma is matplotlib's perceptually uniform blue to yellow colormap.
+func Plasma(t float64) RGBA {
+	return Interpolate(plasmaStops, t)
+}
+
+// RdBu is ColorBrewer's diverging red to blue colormap, white at 0.5. It suits
+// values centered on zero, such as correlations.
+func RdBu(t float64) RGBA {
+	return Interpolate(rdBuStops, t)
+}
+
+// poly evaluates a polynomial with coefficients from the constant term up and
+// clamps it to a color channel.
+func poly(t float64, c ...float64) uint8 {
+	v := 0.0
+	for i := len(c) - 1; i >= 0; i-- {
+		v = v*t + c[i]
+	}
+	return uint8(math.Max(0, math.Min(255, math.Round(v))))
+}
+
+// Cividis is a colormap readable by people with color vision deficiency.
+// It uses the polynomial approximation from d3-scale-chromatic.
+func Cividis(t float64) RGBA {
+	if math.IsNaN(t) {
+		return RGBA{}
+	}
+	t = clamp01(t)
+	return RGBA{
+		R: poly(t, -4.54, -35.34, 2381.73, -6402.7, 7024.72, -2710.57),
+		G: poly(t, 32.49, 170.73, 52.82, -131.46, 176.58, -67.37),
+		B: poly(t, 81.24, 442.36, -2482.43, 6167.24, -6614.94, 2475.67),
+		A: 255,
+	}
+}
+
+// Turbo is Google's improved rainbow colormap, using the polynomial
+// approximation from d3-scale-chromatic.
+func Turbo(t float64) RGBA {
+	if math.IsNaN(t) {
+		return RGBA{}
+	}
+	t = clamp01(t)
+	return RGBA{
+		R: poly(t, 34.61, 1172.33, -10793.56, 33300.12, -38394.49, 14825.05),
+		G: poly(t, 23.31, 557.33, 1225.33, -3574.96, 1073.77, 707.56),
+		B: poly(t, 27.2, 3211.1, -15327.97, 27814, -22569.18, 6838.66),
+		A: 255,
+	}
+}
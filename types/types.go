@@ -1,8 +1,11 @@
 package types
 
 import (
+	"encoding/json"
 	"fmt"
 	"image/color"
+	"math"
+	"strings"
 )
 
 // RGBA amends image/color.RGBA to have a MarshalJSON that meets the expectations of chartjs.
@@ -26,3 +29,111 @@ var (
 	// False is a convenience for pointer to false
 	False = Bool(&f)
 )
+
+// UnmarshalJSON satisfies the json.Unmarshaler interface. It reads the
+// "rgba(r, g, b, a)" colors written by MarshalJSON, as well as "rgb(r, g, b)"
+// and hex colors.
+func (c *RGBA) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	s = strings.Replace(strings.ToLower(s), " ", "", -1)
+	var r, g, bl uint8
+	a := 1.0
+	var err error
+	switch {
+	case strings.HasPrefix(s, "rgba("):
+		_, err = fmt.Sscanf(s, "rgba(%d,%d,%d,%g)", &r, &g, &bl, &a)
+	case strings.HasPrefix(s, "rgb("):
+		_, err = fmt.Sscanf(s, "rgb(%d,%d,%d)", &r, &g, &bl)
+	case len(s) == 7 && s[0] == '#':
+		_, err = fmt.Sscanf(s, "#%02x%02x%02x", &r, &g, &bl)
+	case len(s) == 4 && s[0] == '#':
+		_, err = fmt.Sscanf(s, "#%1x%1x%1x", &r, &g, &bl)
+		r, g, bl = r*17, g*17, bl*17
+	default:
+		err = fmt.Errorf("unsupported format")
+	}
+	if err != nil {
+		return fmt.Errorf("types: bad color %q: %v", s, err)
+	}
+	*c = RGBA{R: r, G: g, B: bl, A: uint8(math.Round(clamp01(a) * 255))}
+	return nil
+}
+
+func clamp01(f float64) float64 {
+	return math.Max(0, math.Min(1, f))
+}
+
+// WithAlpha returns the color with its opacity set to a, from 0 to 1.
+func (c RGBA) WithAlpha(a float64) RGBA {
+	c.A = uint8(clamp01(a)*255 + 0.5)
+	return c
+}
+
+// Mix returns the color t of the way from c to o, from 0 to 1, alpha included.
+func (c RGBA) Mix(o RGBA, t float64) RGBA {
+	t = clamp01(t)
+	lerp := func(a, b uint8) uint8 {
+		return uint8(float64(a) + (float64(b)-float64(a))*t + 0.5)
+	}
+	return RGBA{R: lerp(c.R, o.R), G: lerp(c.G, o.G), B: lerp(c.B, o.B), A: lerp(c.A, o.A)}
+}
+
+// Lighten mixes the color with white by f, from 0 to 1, keeping its alpha.
+func (c RGBA) Lighten(f float64) RGBA {
+	return c.Mix(RGBA{R: 255, G: 255, B: 255, A: c.A}, f)
+}
+
+// Darken mixes the color with black by f, from 0 to 1, keeping its alpha.
+func (c RGBA) Darken(f float64) RGBA {
+	return c.Mix(RGBA{A: c.A}, f)
+}
+
+// Luminance returns the relative luminance of the color as defined by WCAG.
+func (c RGBA) Luminance() float64 {
+	lin := func(v uint8) float64 {
+		f := float64(v) / 255
+		if f <= 0.03928 {
+			return f / 12.92
+		}
+		return math.Pow((f+0.055)/1.055, 2.4)
+	}
+	return 0.2126*lin(c.R) + 0.7152*lin(c.G) + 0.0722*lin(c.B)
+}
+
+// ContrastRatio returns the WCAG contrast ratio between two colors, from 1 to 21.
+func (c RGBA) ContrastRatio(o RGBA) float64 {
+	l1, l2 := c.Luminance(), o.Luminance()
+	if l1 < l2 {
+		l1, l2 = l2, l1
+	}
+	return (l1 + 0.05) / (l2 + 0.05)
+}
+
+// Contrast returns black or white, whichever is more readable on the color.
+func (c RGBA) Contrast() RGBA {
+	black, white := RGBA{A: 255}, RGBA{R: 255, G: 255, B: 255, A: 255}
+	if c.ContrastRatio(black) >= c.ContrastRatio(white) {
+		return black
+	}
+	return white
+}
+
+// Interpolate returns the color at t, from 0 to 1, along evenly spaced stops.
+// NaN is transparent.
+func Interpolate(stops []RGBA, t float64) RGBA {
+	switch {
+	case len(stops) == 0 || math.IsNaN(t):
+		return RGBA{}
+	case len(stops) == 1:
+		return stops[0]
+	}
+	f := clamp01(t) * float64(len(stops)-1)
+	i := int(f)
+	if i == len(stops)-1 {
+		return stops[i]
+	}
+	return stops[i].Mix(stops[i+1], f-float64(i))
+}
@@ -0,0 +1,92 @@
+package types
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestColorHelpers(t *testing.T) {
+	red := RGBA{R: 255, A: 255}
+	if got := red.WithAlpha(0.5); got.A != 128 || got.R != 255 {
+		t.Errorf("WithAlpha: got %v", got)
+	}
+	if got := red.Lighten(0.5); got != (RGBA{R: 255, G: 128, B: 128, A: 255}) {
+		t.Errorf("Lighten: got %v", got)
+	}
+	if got := red.Darken(1); got != (RGBA{A: 255}) {
+		t.Errorf("Darken: got %v", got)
+	}
+	if got := red.Mix(RGBA{B: 255}, 0.5); got != (RGBA{R: 128, B: 128, A: 128}) {
+		t.Errorf("Mix: got %v", got)
+	}
+	white := RGBA{R: 255, G: 255, B: 255, A: 255}
+	if r := white.ContrastRatio(RGBA{A: 255}); math.Abs(r-21) > 1e-9 {
+		t.Errorf("ContrastRatio: got %v", r)
+	}
+	if got := white.Contrast(); got != (RGBA{A: 255}) {
+		t.Errorf("Contrast of white: got %v", got)
+	}
+	if got := (RGBA{B: 128, A: 255}).Contrast(); got != white {
+		t.Errorf("Contrast of navy: got %v", got)
+	}
+	stops := []RGBA{{A: 255}, {R: 100, A: 255}, {R: 200, A: 255}}
+	for _, tt := range []struct {
+		t float64
+		r uint8
+	}{{-1, 0}, {0.25, 50}, {0.5, 100}, {1, 200}, {2, 200}} {
+		if got := Interpolate(stops, tt.t); got.R != tt.r {
+			t.Errorf("Interpolate(%v): got %v", tt.t, got)
+		}
+	}
+	if got := Interpolate(stops, math.NaN()); got != (RGBA{}) {
+		t.Errorf("Interpolate(NaN): got %v", got)
+	}
+}
+
+func TestColormaps(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		m      Colormap
+		lo, hi RGBA
+	}{
+		{"viridis", Viridis, hex(0x440154), hex(0xfde725)},
+		{"plasma", Plasma, hex(0x0d0887), hex(0xf0f921)},
+		{"rdbu", RdBu, hex(0x67001f), hex(0x053061)},
+		{"cividis", Cividis, RGBA{R: 0, G: 32, B: 81, A: 255}, RGBA{R: 253, G: 234, B: 69, A: 255}},
+		{"turbo", Turbo, RGBA{R: 35, G: 23, B: 27, A: 255}, RGBA{R: 144, G: 12, B: 0, A: 255}},
+	} {
+		if got := tt.m(-0.5); got != tt.lo {
+			t.Errorf("%s(0): got %v, want %v", tt.name, got, tt.lo)
+		}
+		if got := tt.m(1); got != tt.hi {
+			t.Errorf("%s(1): got %v, want %v", tt.name, got, tt.hi)
+		}
+		if got := tt.m(math.NaN()); got != (RGBA{}) {
+			t.Errorf("%s(NaN): got %v", tt.name, got)
+		}
+	}
+}
+
+func TestRGBAUnmarshalJSON(t *testing.T) {
+	for in, want := range map[string]RGBA{
+		`"rgba(255, 0, 10, 0.502)"`: {R: 255, B: 10, A: 128},
+		`"rgb(1,2,3)"`:              {R: 1, G: 2, B: 3, A: 255},
+		`"#FF8000"`:                 {R: 255, G: 128, A: 255},
+		`"#f80"`:                    {R: 255, G: 136, A: 255},
+	} {
+		var c RGBA
+		if err := json.Unmarshal([]byte(in), &c); err != nil || c != want {
+			t.Errorf("%s: expected %v, got %v %v", in, want, c, err)
+		}
+	}
+	c := RGBA{R: 1, G: 2, B: 3, A: 77}
+	b, _ := json.Marshal(c)
+	var got RGBA
+	if err := json.Unmarshal(b, &got); err != nil || got != c {
+		t.Errorf("round trip of %s gave %v %v", b, got, err)
+	}
+	if err := json.Unmarshal([]byte(`"red"`), &got); err == nil {
+		t.Error("expected an error for a named color")
+	}
+}
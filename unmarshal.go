@@ -0,0 +1,281 @@
+package chartjs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"math"
+
+	"github.com/iszk1215/go-chartjs/types"
+)
+
+// this file implements loading chart configs written by MarshalJSON back into
+// a Chart, e.g. to change a stored dashboard. Configs for a TargetVersion
+// other than the default schema must be migrated first, see StoredConfig.
+
+// UnmarshalJSON implements json.Unmarshaler interface. The IDs of the axes
+// are set from the keys of Options.Scales.
+func (c *Chart) UnmarshalJSON(b []byte) error {
+	// avoid recursion by creating an alias.
+	type alias Chart
+	var a alias
+	if err := json.Unmarshal(b, &a); err != nil {
+		return err
+	}
+	for id, axis := range a.Options.Scales {
+		axis.ID = id
+		a.Options.Scales[id] = axis
+	}
+	*c = Chart(a)
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface. Labels spanning
+// several lines are read into LabelLines.
+func (d *Data) UnmarshalJSON(b []byte) error {
+	type alias Data
+	var raw struct {
+		alias
+		Labels []json.RawMessage `json:"labels"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	*d = Data(raw.alias)
+	d.Labels, d.LabelLines = nil, nil
+	if raw.Labels == nil {
+		return nil
+	}
+	multi := false
+	for _, l := range raw.Labels {
+		multi = multi || bytes.HasPrefix(l, []byte("["))
+	}
+	if !multi {
+		d.Labels = make([]string, len(raw.Labels))
+		for i, l := range raw.Labels {
+			if err := json.Unmarshal(l, &d.Labels[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	d.LabelLines = make([][]string, len(raw.Labels))
+	for i, l := range raw.Labels {
+		text, lines, err := unmarshalText(l)
+		if err != nil {
+			return err
+		}
+		if lines == nil {
+			lines = []string{text}
+		}
+		d.LabelLines[i] = lines
+	}
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface. Lists of colors are
+// read into BackgroundColors and BorderColors, and fill targets into
+// FillTarget. Numbers and {x, y[, r]} points are read into Values written
+// in the shortest form, other data is kept as json.RawMessage and written
+// as is, also for registered chart types.
+func (d *Dataset) UnmarshalJSON(b []byte) error {
+	type alias Dataset
+	var raw struct {
+		alias
+		Type            json.RawMessage `json:"type"`
+		BackgroundColor json.RawMessage `json:"backgroundColor"`
+		BorderColor     json.RawMessage `json:"borderColor"`
+		Fill            json.RawMessage `json:"fill"`
+		Data            json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	*d = Dataset(raw.alias)
+	if !isNull(raw.Type) {
+		if err := json.Unmarshal(raw.Type, &d.Type); err != nil {
+			return err
+		}
+	}
+	var err error
+	if d.BackgroundColor, d.BackgroundColors, err = unmarshalColors(raw.BackgroundColor); err != nil {
+		return err
+	}
+	if d.BorderColor, d.BorderColors, err = unmarshalColors(raw.BorderColor); err != nil {
+		return err
+	}
+	switch {
+	case isNull(raw.Fill):
+	case raw.Fill[0] == '"':
+		err = json.Unmarshal(raw.Fill, &d.FillTarget)
+	case raw.Fill[0] == 't' || raw.Fill[0] == 'f':
+		var fill bool
+		err = json.Unmarshal(raw.Fill, &fill)
+		d.Fill = &fill
+	default:
+		// a dataset index.
+		d.FillTarget = string(raw.Fill)
+	}
+	if err != nil {
+		return err
+	}
+	d.Data, err = unmarshalData(raw.Data)
+	if _, ok := d.Data.(Values); ok {
+		d.XFloatFormat, d.YFloatFormat = "%g", "%g"
+	}
+	return err
+}
+
+func isNull(b json.RawMessage) bool {
+	return len(b) == 0 || string(b) == "null"
+}
+
+// unmarshalColors reads a color or a list of colors.
+func unmarshalColors(b json.RawMessage) (*types.RGBA, []types.RGBA, error) {
+	if isNull(b) {
+		return nil, nil, nil
+	}
+	if b[0] == '[' {
+		var cs []types.RGBA
+		err := json.Unmarshal(b, &cs)
+		return nil, cs, err
+	}
+	var c types.RGBA
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, nil, err
+	}
+	return &c, nil, nil
+}
+
+// unmarshalData reads numbers, with null as NaN, into Floats and points into
+// XY or XYR. Anything else is returned as json.RawMessage.
+func unmarshalData(b json.RawMessage) (interface{}, error) {
+	if isNull(b) {
+		return nil, nil
+	}
+	var ns []*float64
+	if json.Unmarshal(b, &ns) == nil {
+		return Floats(nanFloats(ns)), nil
+	}
+	var points []map[string]*float64
+	if json.Unmarshal(b, &points) == nil && len(points) > 0 {
+		axes := make(map[string][]*float64)
+		for _, p := range points {
+			if len(p) != len(points[0]) {
+				axes = nil
+				break
+			}
+			for k, v := range p {
+				axes[k] = append(axes[k], v)
+			}
+		}
+		xs, ys, rs := axes["x"], axes["y"], axes["r"]
+		switch {
+		case len(xs) != len(points) || len(ys) != len(points):
+		case len(axes) == 2:
+			return XY(nanFloats(xs), nanFloats(ys)), nil
+		case len(axes) == 3 && len(rs) == len(points):
+			return XYR(nanFloats(xs), nanFloats(ys), nanFloats(rs)), nil
+		}
+	}
+	var raw json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return nil, fmt.Errorf("chart: bad dataset data: %v", err)
+	}
+	return raw, nil
+}
+
+// nanFloats returns the values of ns with nil as NaN.
+func nanFloats(ns []*float64) []float64 {
+	fs := make([]float64, len(ns))
+	for i, n := range ns {
+		if n == nil {
+			fs[i] = math.NaN()
+		} else {
+			fs[i] = *n
+		}
+	}
+	return fs
+}
+
+// unmarshalText reads a text, or the lines of a multi-line text.
+func unmarshalText(b json.RawMessage) (string, []string, error) {
+	if isNull(b) {
+		return "", nil, nil
+	}
+	if b[0] == '[' {
+		var lines []string
+		err := json.Unmarshal(b, &lines)
+		return "", lines, err
+	}
+	var text string
+	err := json.Unmarshal(b, &text)
+	return text, nil, err
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface.
+func (t *Title) UnmarshalJSON(b []byte) error {
+	type alias Title
+	var raw struct {
+		alias
+		Text json.RawMessage `json:"text"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	*t = Title(raw.alias)
+	var err error
+	t.Text, t.TextLines, err = unmarshalText(raw.Text)
+	return err
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface.
+func (t *AxisTitle) UnmarshalJSON(b []byte) error {
+	type alias AxisTitle
+	var raw struct {
+		alias
+		Text json.RawMessage `json:"text"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	*t = AxisTitle(raw.alias)
+	var err error
+	t.Text, t.TextLines, err = unmarshalText(raw.Text)
+	return err
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface.
+func (l *ScaleLabel) UnmarshalJSON(b []byte) error {
+	type alias ScaleLabel
+	var raw struct {
+		alias
+		LabelString json.RawMessage `json:"labelString"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	*l = ScaleLabel(raw.alias)
+	var err error
+	l.LabelString, l.LabelLines, err = unmarshalText(raw.LabelString)
+	return err
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface.
+func (t *TooltipCallbacks) UnmarshalJSON(b []byte) error {
+	var m map[string]JSFunc
+	if err := json.Unmarshal(b, &m); err != nil {
+		return err
+	}
+	*t = TooltipCallbacks{}
+	fields := t.callbacks()
+	for name, f := range m {
+		field, ok := fields[name]
+		if !ok {
+			return fmt.Errorf("chart: unknown tooltip callback %q", name)
+		}
+		*field = template.JSStr(f)
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package chartjs
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ValidationError lists every problem found by Validate.
+type ValidationError []error
+
+func (e ValidationError) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = "\t" + err.Error()
+	}
+	return fmt.Sprintf("chart: %d problems:\n%s", len(e), strings.Join(msgs, "\n"))
+}
+
+// Unwrap returns the problems.
+func (e ValidationError) Unwrap() []error { return e }
+
+// Is reports whether any of the problems matches target, so that errors.Is
+// looks into them before Go 1.20 too.
+func (e ValidationError) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first of the problems that matches target, so that errors.As
+// looks into them before Go 1.20 too.
+func (e ValidationError) As(target interface{}) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// labeledTypes are the chart types whose series are placed by label.
+var labeledTypes = map[chartType]bool{Line: true, Bar: true, Doughnut: true, PolarArea: true, Radar: true}
+
+// radialTypes are the chart types without cartesian axes.
+var radialTypes = map[chartType]bool{Doughnut: true, PolarArea: true, Radar: true}
+
+// Validate checks the chart for mistakes that chart.js would only show in
+// the browser, if at all: axis IDs referenced by datasets but missing from
+// Options.Scales, values of different lengths, Bubble datasets without
+// radii, series without enough labels, float formats that do not write JSON
+// numbers, and axes that do not fit the chart type or their direction. It
+// returns nil or a ValidationError listing every problem found.
+func (c Chart) Validate() error {
+	var errs ValidationError
+	add := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("chart: "+format, args...))
+	}
+
+	labels, err := c.Data.valueLabels()
+	if err != nil {
+		errs = append(errs, err)
+	}
+	nLabels := len(labels)
+	if c.Data.LabelLines != nil {
+		nLabels = len(c.Data.LabelLines)
+	}
+
+	ids := make([]string, 0, len(c.Options.Scales))
+	for id := range c.Options.Scales {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		a := c.Options.Scales[id]
+		switch {
+		case a.Type == Radial && a.Position != 0:
+			add("radial axis %q cannot have a position", id)
+		case a.Type == Radial && !radialTypes[c.Type]:
+			add("radial axis %q on a %s chart, use Linear", id, c.Type)
+		case a.Type != Radial && radialTypes[c.Type]:
+			add("%s charts have no cartesian axes, axis %q is ignored", c.Type, id)
+		}
+	}
+
+	for i, d := range c.Data.Datasets {
+		name := fmt.Sprintf("dataset %d (%q)", i, d.Label)
+		t := d.Type.or(c.Type).or(Line)
+
+		if !radialTypes[t] {
+			c.validateAxisRef(add, name, "x", d.XAxisID, defaultXAxisID, Top, Bottom)
+			c.validateAxisRef(add, name, "y", d.YAxisID, defaultYAxisID, Left, Right)
+			x, ok := c.Options.Scales[d.XAxisID]
+			if !ok && d.XAxisID == "" {
+				x, ok = c.Options.Scales[defaultXAxisID]
+			}
+			if ok && x.Type == Category && (t == Scatter || t == Bubble) {
+				add("%s is a %s on category x-axis, use Linear", name, t)
+			}
+		}
+
+		if err := d.checkFloatFormats(); err != nil {
+			errs = append(errs, err)
+		}
+
+		v, ok := d.Data.(Values)
+		if !ok {
+			continue
+		}
+		xs, ys, rs := v.Xs(), v.Ys(), v.Rs()
+		if tv, ok := v.(TimeValues); ok {
+			if n := len(tv.Times()); n != len(ys) {
+				add("%s has %d times for %d values", name, n, len(ys))
+			}
+		} else if len(ys) > 0 && len(xs) != len(ys) {
+			add("%s has %d x values for %d y values", name, len(xs), len(ys))
+		}
+		if len(rs) > 0 && len(rs) != len(ys) {
+			add("%s has %d radii for %d points", name, len(rs), len(ys))
+		}
+		if mv, ok := v.(MetaValues); ok {
+			if n, m := len(plotted(v)), len(mv.Meta()); n != m {
+				add("%s has %d meta entries for %d values", name, m, n)
+			}
+		}
+		if t == Bubble && len(rs) == 0 {
+			add("%s is a bubble dataset without radii, use XYR", name)
+		}
+		if len(ys) == 0 && labeledTypes[t] && len(xs) > nLabels {
+			add("%s has %d values but the chart has %d labels", name, len(xs), nLabels)
+		}
+	}
+	if errs == nil {
+		return nil
+	}
+	return errs
+}
+
+// validateAxisRef checks that the axis id of a dataset exists and lies in
+// one of the positions of its direction.
+func (c Chart) validateAxisRef(add func(string, ...interface{}), name, dir, id, def string, positions ...axisPosition) {
+	if !c.hasAxis(id, def) {
+		add("%s references unknown %s-axis %q", name, dir, id)
+		return
+	}
+	if id == "" {
+		id = def
+	}
+	a, ok := c.Options.Scales[id]
+	if !ok || a.Position == 0 {
+		return
+	}
+	for _, p := range positions {
+		if a.Position == p {
+			return
+		}
+	}
+	add("%s uses %s-axis %q positioned %s", name, dir, id, axisPositions[a.Position])
+}
@@ -0,0 +1,107 @@
+package chartjs
+
+import (
+	"fmt"
+	"sort"
+)
+
+// xyValues is a plain Values implementation used by the helpers in this package.
+type xyValues struct {
+	xs, ys, rs []float64
+}
+
+func (v xyValues) Xs() []float64 { return v.xs }
+func (v xyValues) Ys() []float64 { return v.ys }
+func (v xyValues) Rs() []float64 { return v.rs }
+
+func (v xyValues) Len() int                { return len(v.xs) }
+func (v xyValues) At(i int) (x, y float64) { return v.xs[i], v.ys[i] }
+
+// Floats returns Values of a single series, e.g. the heights of bars.
+func Floats(ys []float64) Values {
+	return xyValues{xs: ys}
+}
+
+// XY returns Values of the points (xs[i], ys[i]).
+func XY(xs, ys []float64) Values {
+	return xyValues{xs: xs, ys: ys}
+}
+
+// XYR returns Values of the points (xs[i], ys[i]) with radius rs[i], for
+// Bubble charts.
+func XYR(xs, ys, rs []float64) Values {
+	return xyValues{xs: xs, ys: ys, rs: rs}
+}
+
+// LabeledValues are Values with a category label for each value. The labels
+// of a chart are taken from them when it has none.
+type LabeledValues interface {
+	Values
+	Labels() []string
+}
+
+// mapValues are Values of a single series with a label for each value.
+type mapValues struct {
+	xyValues
+	labels []string
+}
+
+func (v mapValues) Labels() []string { return v.labels }
+
+// valueLabels returns the labels of the LabeledValues datasets, checking
+// that they have a label per value and agree with each other and with
+// Labels when set, or FullLabels when the labels were shortened.
+func (d Data) valueLabels() ([]string, error) {
+	var labels []string
+	var from string
+	if len(d.FullLabels) > 0 {
+		labels, from = d.FullLabels, "the chart"
+	} else if len(d.Labels) > 0 {
+		labels, from = d.Labels, "the chart"
+	}
+	for _, ds := range d.Datasets {
+		v, ok := ds.Data.(LabeledValues)
+		if !ok {
+			continue
+		}
+		ls := v.Labels()
+		if n := len(plotted(v)); len(ls) != n {
+			return nil, fmt.Errorf("chart: dataset %q has %d labels for %d values", ds.Label, len(ls), n)
+		}
+		if labels == nil {
+			labels, from = ls, fmt.Sprintf("dataset %q", ds.Label)
+			continue
+		}
+		if !equalStrings(labels, ls) {
+			return nil, fmt.Errorf("chart: labels of dataset %q differ from those of %s", ds.Label, from)
+		}
+	}
+	return labels, nil
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// FromMap returns LabeledValues of the values of m sorted by key, labeled
+// with the keys.
+func FromMap(m map[string]float64) Values {
+	v := mapValues{labels: make([]string, 0, len(m))}
+	for k := range m {
+		v.labels = append(v.labels, k)
+	}
+	sort.Strings(v.labels)
+	v.xs = make([]float64, len(v.labels))
+	for i, k := range v.labels {
+		v.xs[i] = m[k]
+	}
+	return v
+}
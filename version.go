@@ -0,0 +1,236 @@
+package chartjs
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+type chartJSVersion int
+
+// Chart.js major versions for Chart.TargetVersion. The zero value keeps the
+// schema emitted by earlier releases of this package.
+const (
+	V2 chartJSVersion = 2
+	V3 chartJSVersion = 3
+	V4 chartJSVersion = 4
+)
+
+type object = map[string]interface{}
+
+// rename moves key from to key to in m, if present and to is not set.
+func rename(m object, from, to string) {
+	v, ok := m[from]
+	if !ok {
+		return
+	}
+	delete(m, from)
+	if _, ok := m[to]; !ok {
+		m[to] = v
+	}
+}
+
+// child returns m[key] as an object, creating it if needed.
+func child(m object, key string) object {
+	if c, ok := m[key].(object); ok {
+		return c
+	}
+	c := object{}
+	m[key] = c
+	return c
+}
+
+// migrateJSON rewrites the JSON of a chart for the target version. JavaScript
+// in callbacks is passed through unchanged, so callbacks written for one
+// version may need changes for another.
+func migrateJSON(b []byte, v chartJSVersion) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var c object
+	if err := dec.Decode(&c); err != nil {
+		return nil, err
+	}
+	if v == V2 {
+		toV2(c)
+	} else {
+		toV3(c)
+	}
+	return json.Marshal(c)
+}
+
+func sortedKeys(m object) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func datasets(c object) []interface{} {
+	data, _ := c["data"].(object)
+	ds, _ := data["datasets"].([]interface{})
+	return ds
+}
+
+// toV2 turns the scale map into xAxes and yAxes lists, axis titles into
+// scale labels and tick fonts into font options.
+func toV2(c object) {
+	opts, _ := c["options"].(object)
+	if opts == nil {
+		return
+	}
+	if idx, _ := opts["indexAxis"].(string); idx == "y" && c["type"] == "bar" {
+		c["type"] = "horizontalBar"
+	}
+	delete(opts, "indexAxis")
+	scales, _ := opts["scales"].(object)
+	if scales == nil {
+		return
+	}
+	var xs, ys []interface{}
+	for _, id := range sortedKeys(scales) {
+		a, ok := scales[id].(object)
+		if !ok {
+			continue
+		}
+		a["id"] = id
+		if t, ok := a["ticks"].(object); ok {
+			fontToV2(t)
+		}
+		if g, ok := a["grid"].(object); ok {
+			delete(a, "grid")
+			rename(g, "tickLength", "tickMarkLength")
+			a["gridLines"] = g
+		}
+		if t, ok := a["title"].(object); ok {
+			delete(a, "title")
+			if text, ok := t["text"]; ok {
+				l := child(a, "scaleLabel")
+				if _, ok := l["labelString"]; !ok {
+					l["labelString"] = text
+					l["display"] = t["display"]
+				}
+			}
+		}
+		for _, k := range []string{"suggestedMin", "suggestedMax"} {
+			if v, ok := a[k]; ok {
+				delete(a, k)
+				child(a, "ticks")[k] = v
+			}
+		}
+		switch {
+		case a["type"] == "radialLinear":
+			delete(a, "id")
+			opts["scale"] = a
+		case a["position"] == "top" || a["position"] == "bottom" || a["position"] == nil && strings.HasPrefix(id, "x"):
+			xs = append(xs, a)
+		default:
+			ys = append(ys, a)
+		}
+	}
+	delete(opts, "scales")
+	if xs != nil || ys != nil {
+		s := object{}
+		if xs != nil {
+			s["xAxes"] = xs
+		}
+		if ys != nil {
+			s["yAxes"] = ys
+		}
+		opts["scales"] = s
+	}
+}
+
+// toV3 moves tooltips, legend, title and annotations under plugins, renames
+// font and tick options, and moves tick bounds to the axes.
+func toV3(c object) {
+	for _, d := range datasets(c) {
+		if d, ok := d.(object); ok {
+			rename(d, "steppedLine", "stepped")
+			rename(d, "lineTension", "tension")
+		}
+	}
+	opts, _ := c["options"].(object)
+	if opts == nil {
+		return
+	}
+	for from, to := range map[string]string{"tooltips": "tooltip", "legend": "legend", "title": "title", "annotation": "annotation"} {
+		if v, ok := opts[from]; ok {
+			delete(opts, from)
+			child(opts, "plugins")[to] = v
+		}
+	}
+	if plugins, ok := opts["plugins"].(object); ok {
+		if t, ok := plugins["title"].(object); ok {
+			fontToV3(t)
+		}
+	}
+	scales, _ := opts["scales"].(object)
+	for _, a := range scales {
+		a, ok := a.(object)
+		if !ok {
+			continue
+		}
+		if l, ok := a["scaleLabel"].(object); ok {
+			delete(a, "scaleLabel")
+			fontToV3(l)
+			rename(l, "labelString", "text")
+			t := child(a, "title")
+			for k, v := range l {
+				if _, ok := t[k]; !ok {
+					t[k] = v
+				}
+			}
+		}
+		if t, ok := a["ticks"].(object); ok {
+			fontToV3(t)
+			for _, k := range []string{"min", "max", "beginAtZero", "reverse", "suggestedMin", "suggestedMax"} {
+				if v, ok := t[k]; ok {
+					delete(t, k)
+					a[k] = v
+				}
+			}
+			if len(t) == 0 {
+				delete(a, "ticks")
+			}
+		}
+		if p, ok := a["pointLabels"].(object); ok {
+			fontToV3(p)
+		}
+	}
+}
+
+// fontToV2 reverts fontToV3. Font weights become the font style, unless
+// it is set.
+func fontToV2(m object) {
+	rename(m, "color", "fontColor")
+	f, ok := m["font"].(object)
+	if !ok {
+		return
+	}
+	delete(m, "font")
+	if w, ok := f["weight"]; ok {
+		if _, ok := f["style"]; !ok {
+			f["style"] = w
+		}
+	}
+	for from, to := range map[string]string{"family": "fontFamily", "size": "fontSize", "style": "fontStyle", "lineHeight": "lineHeight"} {
+		if v, ok := f[from]; ok {
+			m[to] = v
+		}
+	}
+}
+
+// fontToV3 replaces fontColor with color and fontFamily, fontSize and
+// fontStyle with a font object.
+func fontToV3(m object) {
+	rename(m, "fontColor", "color")
+	for from, to := range map[string]string{"fontFamily": "family", "fontSize": "size", "fontStyle": "style"} {
+		if v, ok := m[from]; ok {
+			delete(m, from)
+			child(m, "font")[to] = v
+		}
+	}
+}
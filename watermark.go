@@ -0,0 +1,116 @@
+package chartjs
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/iszk1215/go-chartjs/types"
+)
+
+type watermarkPosition int
+
+const (
+	// WatermarkCenter centers the watermark in the chart area (the default).
+	WatermarkCenter watermarkPosition = iota
+	WatermarkTopLeft
+	WatermarkTopRight
+	WatermarkBottomLeft
+	WatermarkBottomRight
+)
+
+var watermarkPositions = []string{
+	"center",
+	"top-left",
+	"top-right",
+	"bottom-left",
+	"bottom-right",
+}
+
+func (p watermarkPosition) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + watermarkPositions[p] + `"`), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface.
+func (p *watermarkPosition) UnmarshalJSON(b []byte) error {
+	i, err := unmarshalEnum(b, watermarkPositions, "watermark position")
+	*p = watermarkPosition(i)
+	return err
+}
+
+// Watermark stamps a text or logo on the chart area, e.g. for charts
+// published externally. It is drawn by an inline plugin.
+type Watermark struct {
+	Text string `json:"text,omitempty"`
+	// FontSize in pixels. Defaults to 24.
+	FontSize   int         `json:"-"`
+	FontFamily string      `json:"-"`
+	Color      *types.RGBA `json:"color,omitempty"`
+	// ImageURL is drawn instead of Text if set, sized Width x Height or at
+	// its natural size.
+	ImageURL string            `json:"image,omitempty"`
+	Width    int               `json:"width,omitempty"`
+	Height   int               `json:"height,omitempty"`
+	Position watermarkPosition `json:"position"`
+	// Opacity from 0 to 1. Defaults to 0.2.
+	Opacity float64 `json:"opacity"`
+}
+
+const watermarkJS = `{afterDraw: function(chart) {
+	var o = %s, ctx = chart.ctx, a = chart.chartArea, pad = 8, w, h, img;
+	if (o.image) {
+		img = chart.$watermark;
+		if (!img) {
+			img = chart.$watermark = new Image();
+			img.onload = function() { chart.draw(); };
+			img.src = o.image;
+			return;
+		}
+		if (!img.complete) { return; }
+		w = o.width || img.naturalWidth;
+		h = o.height || img.naturalHeight;
+	} else {
+		ctx.font = o.font;
+		w = ctx.measureText(o.text).width;
+		h = o.fontSize;
+	}
+	var x = o.position.indexOf("left") >= 0 ? a.left + pad : o.position.indexOf("right") >= 0 ? a.right - w - pad : (a.left + a.right - w) / 2;
+	var y = o.position.indexOf("top") >= 0 ? a.top + pad : o.position.indexOf("bottom") >= 0 ? a.bottom - h - pad : (a.top + a.bottom - h) / 2;
+	ctx.save();
+	ctx.globalAlpha = o.opacity;
+	if (img) {
+		ctx.drawImage(img, x, y, w, h);
+	} else {
+		ctx.fillStyle = o.color || "#000";
+		ctx.textBaseline = "top";
+		ctx.fillText(o.text, x, y);
+	}
+	ctx.restore();
+}}`
+
+// plugin returns the inline plugin drawing the watermark.
+func (w Watermark) plugin() (JSFunc, error) {
+	if w.Text == "" && w.ImageURL == "" {
+		return "", fmt.Errorf("chart: watermark needs Text or ImageURL")
+	}
+	if w.Opacity == 0 {
+		w.Opacity = 0.2
+	}
+	size, family := w.FontSize, w.FontFamily
+	if size == 0 {
+		size = 24
+	}
+	if family == "" {
+		family = "sans-serif"
+	}
+	type alias Watermark
+	o := struct {
+		alias
+		Font     string `json:"font"`
+		FontSize int    `json:"fontSize"`
+	}{alias(w), fmt.Sprintf("bold %dpx %s", size, family), size}
+	b, err := json.Marshal(o)
+	if err != nil {
+		return "", err
+	}
+	return JSFunc(fmt.Sprintf(watermarkJS, b)), nil
+}
@@ -0,0 +1,74 @@
+package chartjs
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Helpers that take weights count each value weight times, so pre-aggregated
+// data such as (value, frequency) pairs can be charted without expanding it.
+// Nil weights count every value once.
+
+func checkWeights(n int, ws []float64) error {
+	if ws == nil {
+		return nil
+	}
+	if len(ws) != n {
+		return fmt.Errorf("chart: got %d weights for %d values", len(ws), n)
+	}
+	for _, w := range ws {
+		if w < 0 || math.IsNaN(w) || math.IsInf(w, 0) {
+			return fmt.Errorf("chart: bad weight %v", w)
+		}
+	}
+	return nil
+}
+
+func weightAt(ws []float64, i int) float64 {
+	if ws == nil {
+		return 1
+	}
+	return ws[i]
+}
+
+type sample struct {
+	v, w float64
+}
+
+// sortedSample returns the values with their weights sorted by value, without
+// NaNs and zero weights, and the total weight.
+func sortedSample(xs, ws []float64) ([]sample, float64) {
+	s := make([]sample, 0, len(xs))
+	var total float64
+	for i, x := range xs {
+		if w := weightAt(ws, i); !math.IsNaN(x) && w > 0 {
+			s = append(s, sample{x, w})
+			total += w
+		}
+	}
+	sort.SliceStable(s, func(a, b int) bool { return s[a].v < s[b].v })
+	return s, total
+}
+
+// weightedQuantile interpolates the q-quantile as if each value were repeated
+// weight times, matching quantile for unit weights.
+func weightedQuantile(s []sample, total, q float64) float64 {
+	pos := q * (total - 1)
+	if pos < 0 {
+		pos = 0
+	}
+	// at returns the value at index k of the expanded sample.
+	at := func(k float64) float64 {
+		var cum float64
+		for _, p := range s {
+			cum += p.w
+			if k < cum {
+				return p.v
+			}
+		}
+		return s[len(s)-1].v
+	}
+	lo := math.Floor(pos)
+	return at(lo) + (at(lo+1)-at(lo))*(pos-lo)
+}
@@ -0,0 +1,134 @@
+package chartjs
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/iszk1215/go-chartjs/types"
+)
+
+var compass16 = []string{"N", "NNE", "NE", "ENE", "E", "ESE", "SE", "SSE", "S", "SSW", "SW", "WSW", "W", "WNW", "NW", "NNW"}
+
+// WindRose bins directional data, such as wind directions and speeds, into
+// sectors and speed classes.
+type WindRose struct {
+	// Sectors is the number of direction sectors, 16 by default. Sector 0 is
+	// centered on north.
+	Sectors int
+	// SpeedBins are the upper bounds of the speed classes, ascending. The last
+	// class holds the speeds above the last bound.
+	SpeedBins []float64
+	// Colormap colors the speed classes, types.Viridis by default.
+	Colormap types.Colormap
+}
+
+func (w WindRose) sectors() int {
+	if w.Sectors <= 0 {
+		return 16
+	}
+	return w.Sectors
+}
+
+// sectorLabels uses compass points when the sectors fall on them.
+func (w WindRose) sectorLabels() []string {
+	n := w.sectors()
+	labels := make([]string, n)
+	for i := range labels {
+		if 16%n == 0 {
+			labels[i] = compass16[i*16/n]
+		} else {
+			labels[i] = fmt.Sprintf("%g°", float64(i)*360/float64(n))
+		}
+	}
+	return labels
+}
+
+// Frequencies returns the percentage of observations in each speed class and
+// sector, freq[class][sector]. Directions are in degrees clockwise from north.
+// Observations with a NaN or infinite direction or speed are dropped.
+func (w WindRose) Frequencies(directions, speeds []float64) ([][]float64, error) {
+	if len(directions) != len(speeds) {
+		return nil, fmt.Errorf("chart: got %d directions for %d speeds", len(directions), len(speeds))
+	}
+	n := w.sectors()
+	width := 360 / float64(n)
+	freq := make([][]float64, len(w.SpeedBins)+1)
+	for i := range freq {
+		freq[i] = make([]float64, n)
+	}
+	var total float64
+	for i, d := range directions {
+		s := speeds[i]
+		if !finite(d) || !finite(s) {
+			continue
+		}
+		sector := int(math.Mod(math.Mod(d+width/2, 360)+360, 360)/width) % n
+		class := len(w.SpeedBins)
+		for k, b := range w.SpeedBins {
+			if s <= b {
+				class = k
+				break
+			}
+		}
+		freq[class][sector]++
+		total++
+	}
+	if total > 0 {
+		for _, f := range freq {
+			for i := range f {
+				f[i] *= 100 / total
+			}
+		}
+	}
+	return freq, nil
+}
+
+// Chart returns a Radar chart with a filled ring per speed class, stacked
+// outwards from the slowest class, in percent of the observations.
+func (w WindRose) Chart(directions, speeds []float64) (*Chart, error) {
+	freq, err := w.Frequencies(directions, speeds)
+	if err != nil {
+		return nil, err
+	}
+	cmap := w.Colormap
+	if cmap == nil {
+		cmap = types.Viridis
+	}
+	c := &Chart{Type: Radar, Data: Data{Labels: w.sectorLabels()}}
+	zero := 0.0
+	c.AddRAxis(Axis{SuggestedMin: &zero})
+	cum := make([]float64, w.sectors())
+	for k, f := range freq {
+		ring := make([]float64, len(cum))
+		for i := range cum {
+			cum[i] += f[i]
+			ring[i] = cum[i]
+		}
+		var label string
+		switch {
+		case k < len(w.SpeedBins):
+			label = fmt.Sprintf("≤ %g", w.SpeedBins[k])
+		case k > 0:
+			label = fmt.Sprintf("> %g", w.SpeedBins[k-1])
+		default:
+			label = "all"
+		}
+		t := 0.0
+		if len(freq) > 1 {
+			t = float64(k) / float64(len(freq)-1)
+		}
+		color := cmap(t)
+		fill := color.WithAlpha(0.8)
+		// slower classes are drawn on top of the faster ones around them.
+		c.AddDataset(Dataset{
+			Label:           label,
+			Data:            xyValues{xs: ring},
+			BackgroundColor: &fill,
+			BorderColor:     &color,
+			BorderWidth:     1,
+			Fill:            True,
+			Order:           k,
+		})
+	}
+	return c, nil
+}
@@ -0,0 +1,37 @@
+package chartjs
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// DataHandler serves the Data of a chart as JSON, for use as Chart.DataURL.
+// The data is that of the chart as it is marshaled, with its middlewares and
+// redactor applied.
+func DataHandler(c *Chart) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, err := c.prepare()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		b, err := json.Marshal(p.Data)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(StripJS(b))
+	})
+}
+
+// workerDataJS fetches and parses chart data in a web worker so that large
+// payloads don't block the page.
+const workerDataJS = `function chartjsWorkerData(url, done) {
+	var src = "onmessage = function(e) { fetch(e.data).then(function(r) { return r.text(); })" +
+		".then(function(t) { postMessage(JSON.parse(t)); }); };";
+	var w = new Worker(URL.createObjectURL(new Blob([src], {type: "application/javascript"})));
+	w.onmessage = function(e) { w.terminate(); done(e.data); };
+	w.postMessage(new URL(url, location.href).href);
+}
+`
@@ -0,0 +1,127 @@
+package chartjs
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// jsonWriter is written to by the point by point encoders. It is
+// implemented by bytes.Buffer and bufio.Writer.
+type jsonWriter interface {
+	io.Writer
+	io.ByteWriter
+	io.StringWriter
+}
+
+// WriteJSON writes the same JSON as MarshalJSON to w, streaming the datasets
+// point by point through a buffered writer instead of building the whole
+// config in memory first. Only the data of Values and TimeValues is
+// streamed, other data is marshaled one dataset at a time. Charts with a
+// TargetVersion are migrated as a whole. On error, w may hold a partial config.
+func (c Chart) WriteJSON(w io.Writer) error {
+	c, err := c.prepare()
+	if err != nil {
+		return err
+	}
+	// the tags of JSFunc values are removed as by MarshalJSON.
+	s := &jsStripper{w: w}
+	bw := bufio.NewWriter(s)
+	flush := func() error {
+		if err := bw.Flush(); err != nil {
+			return err
+		}
+		return s.Flush()
+	}
+	if c.TargetVersion != 0 {
+		b, err := c.marshal()
+		if err != nil {
+			return err
+		}
+		bw.Write(b)
+		return flush()
+	}
+	// marshal the chart around a placeholder for its data.
+	data := c.Data
+	c.Data = Data{Labels: []string{jsTag}}
+	placeholder := []byte(`{"datasets":null,"labels":["` + jsTag + `"]}`)
+	b, err := c.marshal()
+	if err != nil {
+		return err
+	}
+	i := bytes.Index(b, placeholder)
+	if i < 0 {
+		return fmt.Errorf("chart: data not found in the chart config")
+	}
+	bw.Write(b[:i])
+	if err := data.writeJSON(bw); err != nil {
+		return err
+	}
+	bw.Write(b[i+len(placeholder):])
+	return flush()
+}
+
+// writeJSON writes the data one dataset at a time, and the labels directly
+// instead of through json.Marshal.
+func (d Data) writeJSON(w jsonWriter) error {
+	labels, err := d.valueLabels()
+	if err != nil {
+		return err
+	}
+	if len(d.Labels) == 0 && d.LabelLines == nil {
+		d.Labels = labels
+	}
+	w.WriteString(`{"datasets":`)
+	if d.Datasets == nil {
+		w.WriteString("null")
+	} else {
+		w.WriteByte('[')
+		for i, ds := range d.Datasets {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			if err := ds.writeJSON(w); err != nil {
+				return err
+			}
+		}
+		w.WriteByte(']')
+	}
+	w.WriteString(`,"labels":`)
+	if d.LabelLines == nil {
+		err = writeStringsJSON(w, d.Labels)
+	} else {
+		w.WriteByte('[')
+		for i, lines := range d.LabelLines {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			if err = writeStringsJSON(w, lines); err != nil {
+				break
+			}
+		}
+		w.WriteByte(']')
+	}
+	if err != nil {
+		return err
+	}
+	if len(d.FullLabels) > 0 {
+		w.WriteString(`,"fullLabels":`)
+		if err := writeStringsJSON(w, d.FullLabels); err != nil {
+			return err
+		}
+	}
+	return w.WriteByte('}')
+}
+
+// marshal returns the JSON of a prepared chart.
+func (c Chart) marshal() ([]byte, error) {
+	// avoid recursion by creating an alias.
+	type alias Chart
+	b, err := json.Marshal(alias(c))
+	if err != nil || c.TargetVersion == 0 {
+		return b, err
+	}
+	return migrateJSON(b, c.TargetVersion)
+}
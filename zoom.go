@@ -0,0 +1,100 @@
+package chartjs
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ZoomCache holds decimated copies of a large series at several resolutions,
+// like map tiles, so that the points for any zoomed range are found without
+// reading the whole series.
+//
+// The coarsest level has about MaxPoints points and each finer level twice
+// as many. Ranges narrower than the finest level are read from the source.
+type ZoomCache struct {
+	// MaxPoints is the number of points returned for a range.
+	MaxPoints int
+
+	src    pointSource
+	levels []xyValues // coarsest first
+}
+
+// NewZoomCache builds the given number of levels from v. It reads v once, so
+// MappedValues are supported without loading them into memory.
+func NewZoomCache(v Values, maxPoints, levels int) (*ZoomCache, error) {
+	if maxPoints <= 0 || levels <= 0 {
+		return nil, fmt.Errorf("chart: bad zoom cache size %d x %d", maxPoints, levels)
+	}
+	var src pointSource
+	if m, ok := v.(*MappedValues); ok {
+		src = m
+	} else {
+		xs, ys := v.Xs(), v.Ys()
+		if len(ys) == 0 && len(xs) > 0 {
+			return nil, fmt.Errorf("chart: zoom cache needs x and y values")
+		}
+		if len(xs) != len(ys) {
+			return nil, fmt.Errorf("chart: bad format of Values. X and Y must be of the same length")
+		}
+		src = xyValues{xs: xs, ys: ys}
+	}
+	z := &ZoomCache{MaxPoints: maxPoints, src: src}
+	var level pointSource = src
+	for l := levels - 1; l >= 0; l-- {
+		n := maxPoints << uint(l)
+		if n >= src.Len() {
+			continue
+		}
+		xs, ys := minMax(level, 0, level.Len(), n/2)
+		next := xyValues{xs: xs, ys: ys}
+		z.levels = append([]xyValues{next}, z.levels...)
+		level = next
+	}
+	return z, nil
+}
+
+// Levels returns the number of cached levels.
+func (z *ZoomCache) Levels() int { return len(z.levels) }
+
+func search(src pointSource, x float64) int {
+	return sort.Search(src.Len(), func(i int) bool {
+		xi, _ := src.At(i)
+		return xi >= x
+	})
+}
+
+// Range returns at most MaxPoints points with x in [from, to]. It uses the
+// finest level with no more than MaxPoints points in the range, and reads
+// the source when even the finest level has less than half of MaxPoints.
+func (z *ZoomCache) Range(from, to float64) Values {
+	var best xyValues
+	found := false
+	for _, l := range z.levels {
+		lo, hi := search(l, from), searchAfter(l, to)
+		if hi-lo > z.MaxPoints {
+			break
+		}
+		best, found = xyValues{xs: l.xs[lo:hi], ys: l.ys[lo:hi]}, true
+	}
+	if found && len(best.xs) >= z.MaxPoints/2 {
+		return best
+	}
+	if !found && len(z.levels) > 0 {
+		// the range spans more than the coarsest level can show.
+		l := z.levels[0]
+		lo, hi := search(l, from), searchAfter(l, to)
+		xs, ys := minMax(l, lo, hi, z.MaxPoints/2)
+		return xyValues{xs: xs, ys: ys}
+	}
+	lo, hi := search(z.src, from), searchAfter(z.src, to)
+	xs, ys := minMax(z.src, lo, hi, z.MaxPoints/2)
+	return xyValues{xs: xs, ys: ys}
+}
+
+// searchAfter returns the index of the first point with an x above x.
+func searchAfter(src pointSource, x float64) int {
+	return sort.Search(src.Len(), func(i int) bool {
+		xi, _ := src.At(i)
+		return xi > x
+	})
+}